@@ -0,0 +1,175 @@
+package suretax
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// How long a verified address is trusted by default.
+const DefaultAddressCacheTTL = 30 * 24 * time.Hour
+
+// Remembers addresses SureAddress verified successfully, so later requests for the same
+// address are sent with VerifyAddress turned off and don't incur verification fees again.
+// Addresses are compared after normalizing case, whitespace and punctuation. Safe for concurrent use.
+type AddressCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	verified map[string]time.Time
+	now      func() time.Time
+}
+
+// Returns a cache trusting verified addresses for ttl, DefaultAddressCacheTTL if ttl is 0.
+func NewAddressCache(ttl time.Duration) *AddressCache {
+	if ttl == 0 {
+		ttl = DefaultAddressCacheTTL
+	}
+	return &AddressCache{ttl: ttl, verified: make(map[string]time.Time), now: time.Now}
+}
+
+type addressCacheBypassKey struct{}
+
+// Returns a context under which Send verifies every address as requested, ignoring the
+// AddressCache. Successful verifications still refresh the cache.
+func BypassAddressCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, addressCacheBypassKey{}, true)
+}
+
+func addressCacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(addressCacheBypassKey{}).(bool)
+	return bypass
+}
+
+// Reports whether a was verified within the TTL.
+func (c *AddressCache) Verified(a *Address) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	exp, ok := c.verified[addressKey(a)]
+	if ok && !c.now().Before(exp) {
+		delete(c.verified, addressKey(a))
+		return false
+	}
+	return ok
+}
+
+// Records a as verified.
+func (c *AddressCache) Add(a *Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verified[addressKey(a)] = c.now().Add(c.ttl)
+}
+
+// Removes the addresses whose TTL has passed.
+func (c *AddressCache) Prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, exp := range c.verified {
+		if !now.Before(exp) {
+			delete(c.verified, k)
+		}
+	}
+}
+
+func addressKey(a *Address) string {
+	fields := []string{a.PrimaryAddressLine, a.SecondaryAddressLine, a.City, a.State, a.PostalCode, a.Plus4, a.Country}
+
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString(strings.Join(strings.FieldsFunc(strings.ToUpper(f), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}), " "))
+	}
+	return b.String()
+}
+
+func verificationRequested(a *Address) bool {
+	switch strings.ToLower(a.VerifyAddress) {
+	case "1", "true", "y", "yes":
+		return true
+	}
+	return false
+}
+
+// The value turning verification off, in the same convention as the requesting value.
+func verificationOff(a *Address) string {
+	if strings.EqualFold(a.VerifyAddress, "true") {
+		return "false"
+	}
+	return "0"
+}
+
+// Calls fn with every address of item that may request verification.
+// Changes made by fn to the ServiceAddress are made on a copy.
+func eachVerifiable(item *RequestItem, fn func(a *Address) bool) {
+	fn(&item.Address)
+	fn((*Address)(&item.P2PAddress))
+	if item.ServiceAddress != nil {
+		a := *item.ServiceAddress
+		if fn(&a) {
+			item.ServiceAddress = &a
+		}
+	}
+}
+
+// Returns req with verification turned off for cached addresses, a copy if anything changed.
+func (c *AddressCache) skipVerified(req *Request) *Request {
+	var items []RequestItem
+
+	for i := range req.ItemList {
+		item := req.ItemList[i]
+		changed := false
+		eachVerifiable(&item, func(a *Address) bool {
+			if verificationRequested(a) && c.Verified(a) {
+				a.VerifyAddress = verificationOff(a)
+				changed = true
+				return true
+			}
+			return false
+		})
+
+		if changed {
+			if items == nil {
+				items = make([]RequestItem, len(req.ItemList))
+				copy(items, req.ItemList)
+			}
+			items[i] = item
+		}
+	}
+
+	if items == nil {
+		return req
+	}
+	cp := *req
+	cp.ItemList = items
+	return &cp
+}
+
+// Records the addresses verified for the items of req that SureTax processed without error.
+func (c *AddressCache) recordVerified(req *Request, res *Response) {
+	failed := map[string]bool{}
+	for _, m := range res.ItemMessages {
+		failed[lineKey(m.LineNumber)] = true
+	}
+
+	for i := range req.ItemList {
+		item := req.ItemList[i]
+		if failed[lineKey(item.LineNumber)] {
+			continue
+		}
+		eachVerifiable(&item, func(a *Address) bool {
+			if verificationRequested(a) {
+				c.Add(a)
+			}
+			return false
+		})
+	}
+}
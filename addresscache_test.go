@@ -0,0 +1,76 @@
+package suretax
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_AddressCache_Send(t *testing.T) {
+
+	ok := envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)
+	fake := &fakeHttpClient{bodies: []string{ok, ok, ok, ok}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	cache := NewAddressCache(time.Hour)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	cli := &SuretaxClient{AddressCache: cache}
+	newRequest := func(line string) *Request {
+		req := getTestRequest()
+		req.ItemList[0].Address = Address{PrimaryAddressLine: line, City: "Fernandina Beach", State: "FL", PostalCode: "32034", VerifyAddress: "1"}
+		return req
+	}
+	sentVerify := func(i int) string {
+		return decodeTestRequest(t, fake.requests[i]).ItemList[0].Address.VerifyAddress
+	}
+
+	if _, err := cli.Send(newRequest("1 Main St.")); err != nil {
+		t.Fatal(err)
+	}
+	req := newRequest("1  MAIN ST")
+	if _, err := cli.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	if sentVerify(0) != "1" || sentVerify(1) != "0" {
+		t.Fatalf("Expected only the first request to verify the address but got %v and %v", sentVerify(0), sentVerify(1))
+	}
+	if req.ItemList[0].Address.VerifyAddress != "1" {
+		t.Fatal("Expected the caller's request to be unchanged")
+	}
+
+	if _, err := cli.SendContext(BypassAddressCache(context.Background()), newRequest("1 Main St")); err != nil {
+		t.Fatal(err)
+	}
+	if sentVerify(2) != "1" {
+		t.Fatal("Expected the bypassed request to verify the address")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := cli.Send(newRequest("1 Main St")); err != nil {
+		t.Fatal(err)
+	}
+	if sentVerify(3) != "1" {
+		t.Fatal("Expected the address to be verified again after the TTL")
+	}
+}
+
+func Test_AddressCache_ItemErrors(t *testing.T) {
+
+	cache := NewAddressCache(0)
+	req := getTestRequest()
+	req.ItemList[0].P2PAddress = P2PAddress{PostalCode: "30301", VerifyAddress: "true"}
+
+	cache.recordVerified(req, &Response{ItemMessages: []ItemMessage{{LineNumber: "1", ResponseCode: "9131"}}})
+	if cache.Verified((*Address)(&req.ItemList[0].P2PAddress)) {
+		t.Fatal("Expected the address of a failed item not to be cached")
+	}
+
+	cache.recordVerified(req, &Response{})
+	skipped := cache.skipVerified(req)
+	if skipped.ItemList[0].P2PAddress.VerifyAddress != "false" || req.ItemList[0].P2PAddress.VerifyAddress != "true" {
+		t.Fatalf("Expected verification to be turned off in a copy but got %v", skipped.ItemList[0].P2PAddress.VerifyAddress)
+	}
+}
@@ -0,0 +1,135 @@
+package suretax
+
+import (
+	"math/big"
+	"sort"
+)
+
+// Taxes of one request item, as stamped on an invoice line.
+type LineTax struct {
+	LineNumber    string
+	InvoiceNumber string
+
+	// The originating request item. Nil for groups whose line number doesn't match any item.
+	Item *RequestItem
+
+	TaxList []Tax
+
+	// Exact sum of TaxAmount over TaxList.
+	ExactTax string
+
+	// ExactTax rounded as configured in TaxAllocator, adjusted so the lines of an invoice add up to
+	// the rounded invoice total.
+	TotalTax string
+}
+
+// Maps the taxes of a response back onto the request items and rounds them per line.
+// Rounding every line on its own can make the lines disagree with the invoice total by a few cents,
+// so the difference is spread over the lines with the largest rounding remainders.
+type TaxAllocator struct {
+	// Decimal places of TotalTax. Defaults to 2.
+	Places int
+
+	// Rounding of the line and invoice totals. Defaults to RoundHalfUp.
+	Mode RoundingMode
+}
+
+// Returns one LineTax per item of req, in request order, followed by the groups of res
+// that match no item. Items are matched by line number and grouped into invoices by InvoiceNumber.
+func (a TaxAllocator) Allocate(req *Request, res *Response) ([]LineTax, error) {
+	groups, err := res.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]LineTax, len(req.ItemList))
+	index := make(map[string]int, len(req.ItemList))
+	for i := range req.ItemList {
+		item := &req.ItemList[i]
+		lines[i] = LineTax{LineNumber: item.LineNumber, InvoiceNumber: item.InvoiceNumber, Item: item}
+		index[lineKey(item.LineNumber)] = i
+	}
+	for _, g := range groups {
+		key := lineKey(g.LineNumber)
+		i, ok := index[key]
+		if !ok {
+			i = len(lines)
+			index[key] = i
+			lines = append(lines, LineTax{LineNumber: g.LineNumber, InvoiceNumber: g.InvoiceNumber})
+		}
+		lines[i].TaxList = append(lines[i].TaxList, g.TaxList...)
+	}
+
+	ro := Rounding{Mode: a.Mode, Places: a.Places}
+	if ro.Places <= 0 {
+		ro.Places = 2
+	}
+
+	exact := make([]*big.Rat, len(lines))
+	rounded := make([]*big.Rat, len(lines))
+	invoices := map[string][]int{}
+	var order []string
+	for i := range lines {
+		l := &lines[i]
+		amounts := make([]string, len(l.TaxList))
+		for j, t := range l.TaxList {
+			amounts[j] = t.TaxAmount
+		}
+		if l.ExactTax, err = sumAmounts(amounts); err != nil {
+			return nil, err
+		}
+		exact[i], _ = parseAmount(l.ExactTax)
+		rounded[i] = ro.Round(exact[i])
+
+		if _, ok := invoices[l.InvoiceNumber]; !ok {
+			order = append(order, l.InvoiceNumber)
+		}
+		invoices[l.InvoiceNumber] = append(invoices[l.InvoiceNumber], i)
+	}
+
+	for _, inv := range order {
+		spreadRemainder(invoices[inv], exact, rounded, ro)
+	}
+
+	for i := range lines {
+		lines[i].TotalTax = formatAmount(rounded[i], ro.Places)
+	}
+	return lines, nil
+}
+
+// Adjusts the rounded amounts of the lines by one unit of the last decimal place each until
+// they add up to the rounded sum of the exact amounts, starting with the largest remainders.
+func spreadRemainder(lines []int, exact, rounded []*big.Rat, ro Rounding) {
+	sumExact, sumRounded := new(big.Rat), new(big.Rat)
+	for _, i := range lines {
+		sumExact.Add(sumExact, exact[i])
+		sumRounded.Add(sumRounded, rounded[i])
+	}
+
+	unit := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(ro.Places)), nil))
+	diff := new(big.Rat).Sub(ro.Round(sumExact), sumRounded)
+	units := new(big.Rat).Quo(diff, unit).Num().Int64()
+	if units == 0 {
+		return
+	}
+
+	residual := func(i int) *big.Rat { return new(big.Rat).Sub(exact[i], rounded[i]) }
+	byRemainder := append([]int(nil), lines...)
+	sort.SliceStable(byRemainder, func(a, b int) bool {
+		c := residual(byRemainder[a]).Cmp(residual(byRemainder[b]))
+		if units > 0 {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	step := unit
+	if units < 0 {
+		step = new(big.Rat).Neg(unit)
+		units = -units
+	}
+	for n := int64(0); n < units; n++ {
+		i := byRemainder[n%int64(len(byRemainder))]
+		rounded[i].Add(rounded[i], step)
+	}
+}
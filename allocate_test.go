@@ -0,0 +1,65 @@
+package suretax
+
+import "testing"
+
+func Test_TaxAllocator(t *testing.T) {
+
+	req := getTestRequest()
+	item := req.ItemList[0]
+	req.ItemList = nil
+	for _, ln := range []string{"01", "02", "03", "04"} {
+		item.LineNumber = ln
+		req.ItemList = append(req.ItemList, item)
+	}
+	req.ItemList[3].InvoiceNumber = "INV-003"
+
+	res := &Response{GroupList: []Group{
+		{LineNumber: "1", TaxList: []Tax{{TaxAmount: "0.3333"}}},
+		{LineNumber: "2", TaxList: []Tax{{TaxAmount: "0.2000"}, {TaxAmount: "0.1334"}}},
+		{LineNumber: "3", TaxList: []Tax{{TaxAmount: "0.3332"}}},
+		{LineNumber: "9", InvoiceNumber: "INV-009", TaxList: []Tax{{TaxAmount: "0.005"}}},
+	}}
+
+	lines, err := TaxAllocator{}.Allocate(req, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"0.33", "0.34", "0.33", "0.00", "0.01"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines but got %+v", len(expected), lines)
+	}
+	for i, l := range lines {
+		if l.TotalTax != expected[i] {
+			t.Fatalf("Expected line %d to total %v but got %v", i, expected[i], l.TotalTax)
+		}
+	}
+
+	if lines[1].Item != &req.ItemList[1] || lines[1].ExactTax != "0.3334" || len(lines[1].TaxList) != 2 {
+		t.Fatalf("Expected line 02 to be matched with both taxes but got %+v", lines[1])
+	}
+	if lines[4].Item != nil || lines[4].InvoiceNumber != "INV-009" {
+		t.Fatalf("Expected the unmatched group last but got %+v", lines[4])
+	}
+
+	lines, _ = TaxAllocator{Places: 3}.Allocate(req, res)
+	if lines[0].TotalTax != "0.333" {
+		t.Fatalf("Expected 3 decimal places but got %v", lines[0].TotalTax)
+	}
+}
+
+func Test_TaxAllocator_Mode(t *testing.T) {
+
+	req := getTestRequest()
+	res := &Response{GroupList: []Group{{LineNumber: "1", TaxList: []Tax{{TaxAmount: "0.125"}}}}}
+
+	for mode, expected := range map[RoundingMode]string{RoundHalfUp: "0.13", RoundHalfEven: "0.12", RoundDown: "0.12"} {
+		lines, err := TaxAllocator{Mode: mode}.Allocate(req, res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines[0].TotalTax != expected {
+			t.Fatalf("Expected %v rounding to give %v but got %v", mode, expected, lines[0].TotalTax)
+		}
+	}
+}
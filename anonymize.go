@@ -0,0 +1,173 @@
+package suretax
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Rewrites customer numbers, invoice numbers, phone numbers and street addresses of captured payloads,
+// e.g. for fixtures attached to bug reports. Every value is replaced with the same stand-in
+// wherever it occurs, so requests and responses still correlate. Everything taxes depend on is kept:
+// amounts, codes, state, city, county, postal code and the NPA-NXX of phone numbers.
+// Safe for concurrent use.
+type Anonymizer struct {
+	mu     sync.Mutex
+	values map[string]string
+	counts map[string]int
+}
+
+func NewAnonymizer() *Anonymizer {
+	return &Anonymizer{values: make(map[string]string), counts: make(map[string]int)}
+}
+
+// Returns the stand-in for value of the given kind, made by format from its sequence number.
+func (a *Anonymizer) replace(kind, value string, format func(n int) string) string {
+	if value == "" {
+		return ""
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := kind + "\x00" + value
+	if v, ok := a.values[key]; ok {
+		return v
+	}
+	a.counts[kind]++
+	v := format(a.counts[kind])
+	a.values[key] = v
+	return v
+}
+
+func (a *Anonymizer) customer(v string) string {
+	return a.replace("customer", v, func(n int) string { return fmt.Sprintf("CUST%04d", n) })
+}
+
+func (a *Anonymizer) invoice(v string) string {
+	return a.replace("invoice", v, func(n int) string { return fmt.Sprintf("INV%04d", n) })
+}
+
+func (a *Anonymizer) street(v string) string {
+	return a.replace("street", v, func(n int) string { return fmt.Sprintf("%d Anonymous St", n) })
+}
+
+// Keeps the NPA-NXX, which locates the number, and replaces the remaining digits.
+func (a *Anonymizer) phone(v string) string {
+	if len(v) <= 6 {
+		return v
+	}
+	prefix, rest := v[:6], v[6:]
+	return a.replace("phone "+prefix, v, func(n int) string {
+		digits := fmt.Sprintf("%0*d", len(rest), n)
+		return prefix + digits[len(digits)-len(rest):]
+	})
+}
+
+func (a *Anonymizer) address(addr *Address) {
+	addr.PrimaryAddressLine = a.street(addr.PrimaryAddressLine)
+	addr.SecondaryAddressLine = a.street(addr.SecondaryAddressLine)
+}
+
+// Returns an anonymized copy of req.
+func (a *Anonymizer) Request(req *Request) *Request {
+	c := *req
+	c.ItemList = make([]RequestItem, len(req.ItemList))
+
+	for i, item := range req.ItemList {
+		item.CustomerNumber = a.customer(item.CustomerNumber)
+		item.InvoiceNumber = a.invoice(item.InvoiceNumber)
+		item.OrigNumber = a.phone(item.OrigNumber)
+		item.TermNumber = a.phone(item.TermNumber)
+		item.BillToNumber = a.phone(item.BillToNumber)
+
+		a.address(&item.Address)
+		a.address((*Address)(&item.P2PAddress))
+		if item.ServiceAddress != nil {
+			s := *item.ServiceAddress
+			a.address(&s)
+			item.ServiceAddress = &s
+		}
+
+		c.ItemList[i] = item
+	}
+
+	return &c
+}
+
+// Returns an anonymized copy of res.
+func (a *Anonymizer) Response(res *Response) (*Response, error) {
+	groups, err := res.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	c := *res
+	c.lazy, c.taxes = nil, nil
+	c.GroupList = make([]Group, len(groups))
+
+	for i, g := range groups {
+		g.CustomerNumber = a.customer(g.CustomerNumber)
+		g.InvoiceNumber = a.invoice(g.InvoiceNumber)
+		c.GroupList[i] = g
+	}
+
+	return &c, nil
+}
+
+// Anonymizes a captured JSON payload: a request or response, bare or in the wrapper
+// sent to and received from SureTax. Cancel requests carry no personal data and are returned as is.
+func (a *Anonymizer) Payload(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	has := func(key string) bool {
+		for k := range fields {
+			if strings.EqualFold(k, key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, key := range []string{"request", "d"} {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		var inner string
+		if err := json.Unmarshal(raw, &inner); err != nil {
+			return nil, err
+		}
+		anon, err := a.Payload([]byte(inner))
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{key: string(anon)})
+	}
+
+	switch {
+	case has("ItemList"):
+		req := &Request{}
+		if err := json.Unmarshal(data, req); err != nil {
+			return nil, err
+		}
+		return json.Marshal(a.Request(req))
+
+	case has("GroupList"), has("TotalTax"):
+		res := &Response{}
+		if err := json.Unmarshal(data, res); err != nil {
+			return nil, err
+		}
+		anon, err := a.Response(res)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(anon)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,59 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func Test_Anonymizer(t *testing.T) {
+
+	a := NewAnonymizer()
+
+	req := getTestRequest()
+	item := &req.ItemList[0]
+	item.CustomerNumber, item.InvoiceNumber = "ACME-42", "INV-2026-001"
+	item.OrigNumber, item.TermNumber, item.BillToNumber = "9045551234", "9045551234", "9045559876"
+	item.Address = Address{PrimaryAddressLine: "1 Main St", City: "Fernandina Beach", State: "FL", PostalCode: "32034"}
+
+	anon := a.Request(req)
+	got := anon.ItemList[0]
+	if got.CustomerNumber != "CUST0001" || got.InvoiceNumber != "INV0001" || got.Address.PrimaryAddressLine != "1 Anonymous St" {
+		t.Fatalf("Expected identifiers and street to be replaced but got %+v", got)
+	}
+	if got.OrigNumber != "9045550001" || got.TermNumber != got.OrigNumber || got.BillToNumber != "9045550002" {
+		t.Fatalf("Expected consistent phone numbers keeping the NPA-NXX but got %v %v %v", got.OrigNumber, got.TermNumber, got.BillToNumber)
+	}
+	if got.Address.PostalCode != "32034" || got.Revenue != item.Revenue || item.CustomerNumber != "ACME-42" {
+		t.Fatal("Expected tax relevant fields and the original request to be kept")
+	}
+
+	res, err := a.Response(&Response{TotalTax: "1.00", GroupList: []Group{{CustomerNumber: "ACME-42", InvoiceNumber: "INV-2026-001", TaxList: []Tax{{TaxAmount: "1.00"}}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := res.GroupList[0]; g.CustomerNumber != "CUST0001" || g.InvoiceNumber != "INV0001" || g.TaxList[0].TaxAmount != "1.00" {
+		t.Fatalf("Expected the response to correlate with the request but got %+v", g)
+	}
+}
+
+func Test_Anonymizer_Payload(t *testing.T) {
+
+	a := NewAnonymizer()
+
+	inner, _ := json.Marshal(&Response{GroupList: []Group{{CustomerNumber: "ACME-42"}}})
+	wrapped, _ := json.Marshal(ResponseWrapper{D: string(inner)})
+
+	out, err := a.Payload(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("ACME")) || !bytes.Contains(out, []byte("CUST0001")) {
+		t.Fatalf("Expected the wrapped response to be anonymized but got %s", out)
+	}
+
+	cancel := []byte(`{"requestCancel":"{}"}`)
+	if out, _ := a.Payload(cancel); !bytes.Equal(out, cancel) {
+		t.Fatalf("Expected cancel requests to be kept but got %s", out)
+	}
+}
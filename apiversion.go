@@ -0,0 +1,87 @@
+package suretax
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Version of the SureTax communications request format.
+// RequestItem fields introduced after the original format carry a since:"<version>" struct tag.
+type APIVersion int
+
+const (
+	// The original request shape, accepted by every endpoint (default).
+	APIVersion1 APIVersion = iota
+
+	// Adds LocationCode, AuxRevenue and AuxRevenueType to request items.
+	APIVersion2
+)
+
+func (v APIVersion) String() string {
+	return "V" + strconv.Itoa(int(v)+1)
+}
+
+type versionedField struct {
+	index int
+	name  string
+	since APIVersion
+}
+
+// RequestItem fields tagged with the version that introduced them.
+var versionedItemFields = func() []versionedField {
+	var fields []versionedField
+	t := reflect.TypeOf(RequestItem{})
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("since")
+		if tag == "" {
+			continue
+		}
+		n, err := strconv.Atoi(tag)
+		if err != nil {
+			panic("suretax: invalid since tag on RequestItem." + t.Field(i).Name)
+		}
+		fields = append(fields, versionedField{i, t.Field(i).Name, APIVersion(n - 1)})
+	}
+	return fields
+}()
+
+// Returns req as it should be sent to an endpoint of version v, together with the names of the
+// fields that had to be left out. req itself is returned when nothing needs to be removed,
+// otherwise a copy is made and req is not modified.
+func (v APIVersion) shape(req *Request) (*Request, []string) {
+	var dropped []string
+	var items []RequestItem
+
+	for i := range req.ItemList {
+		item := reflect.ValueOf(&req.ItemList[i]).Elem()
+		for _, f := range versionedItemFields {
+			if f.since <= v || item.Field(f.index).IsZero() {
+				continue
+			}
+
+			if items == nil {
+				items = make([]RequestItem, len(req.ItemList))
+				copy(items, req.ItemList)
+			}
+			reflect.ValueOf(&items[i]).Elem().Field(f.index).SetZero()
+			dropped = appendUnique(dropped, f.name)
+		}
+	}
+
+	if items == nil {
+		return req, nil
+	}
+
+	c := *req
+	c.ItemList = items
+	return &c, dropped
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
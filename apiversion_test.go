@@ -0,0 +1,57 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_APIVersion_shape(t *testing.T) {
+
+	req := getTestRequest()
+	if shaped, dropped := APIVersion1.shape(req); shaped != req || dropped != nil {
+		t.Fatal("Expected a request without newer fields to be sent as is")
+	}
+
+	req.ItemList[0].LocationCode = "LOC1"
+	req.ItemList[0].AuxRevenue = "10.00"
+
+	shaped, dropped := APIVersion1.shape(req)
+	if shaped == req || shaped.ItemList[0].LocationCode != "" || shaped.ItemList[0].AuxRevenue != "" {
+		t.Fatalf("Expected newer fields to be cleared in a copy but got %+v", shaped.ItemList[0])
+	}
+	if len(dropped) != 2 || dropped[0] != "LocationCode" || dropped[1] != "AuxRevenue" {
+		t.Fatalf("Expected LocationCode and AuxRevenue to be dropped but got %v", dropped)
+	}
+	if req.ItemList[0].LocationCode != "LOC1" {
+		t.Fatal("Expected the original request to be unchanged")
+	}
+
+	if shaped, dropped := APIVersion2.shape(req); shaped != req || dropped != nil {
+		t.Fatal("Expected APIVersion2 to keep the newer fields")
+	}
+}
+
+func Test_Send_APIVersion(t *testing.T) {
+
+	for _, v := range []APIVersion{APIVersion1, APIVersion2} {
+		fake := &fakeHttpClient{bodies: []string{envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)}}
+		SetHttpClient(fake)
+
+		req := getTestRequest()
+		req.ItemList[0].LocationCode = "LOC1"
+
+		cli := &SuretaxClient{APIVersion: v}
+		if _, err := cli.Send(req); err != nil {
+			t.Fatal(err)
+		}
+
+		sent := decodeTestRequest(t, fake.requests[0])
+		if sent.ItemList[0].LocationCode != map[APIVersion]string{APIVersion1: "", APIVersion2: "LOC1"}[v] {
+			t.Fatalf("Expected LocationCode to be sent only to %v but got %q for %v", APIVersion2, sent.ItemList[0].LocationCode, v)
+		}
+	}
+	SetHttpClient(nil)
+
+	if APIVersion2.String() != "V2" {
+		t.Fatalf("Expected V2 but got %v", APIVersion2)
+	}
+}
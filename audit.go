@@ -0,0 +1,222 @@
+package suretax
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Tamper-evident record of a single SureTax transaction.
+// Every record carries the hash of its predecessor, so altering or removing a record
+// breaks the chain from that point on. See VerifyAuditChain.
+type AuditRecord struct {
+	// Position of the record in the chain, starting at 1.
+	Sequence uint64
+
+	// Time the response was received.
+	Time time.Time
+
+	// "send" or "cancel"
+	Operation string
+
+	// SHA-256 of the request body as sent to SureTax.
+	RequestFingerprint string
+
+	ClientTracking string
+	STAN           string
+	TransId        int
+	ResponseCode   string
+	TotalRevenue   string
+	TotalTax       string
+
+	// Caller set with WithActor, e.g. the user or service on whose behalf the call was made.
+	Actor string `json:",omitempty"`
+
+	// "success", "item errors" or "declined" for a send, the CancelOutcome of a cancel.
+	Outcome string `json:",omitempty"`
+
+	// Hash of the previous record. Empty for the first record.
+	PrevHash string
+
+	// SHA-256 (or HMAC-SHA256 when the auditor has a key) over the record and PrevHash.
+	Hash string
+}
+
+// Destination for audit records, e.g. a database table or an append-only file.
+type AuditSink interface {
+	Write(rec *AuditRecord) error
+}
+
+// Produces chained AuditRecords and writes them to a sink. Safe for concurrent use.
+type Auditor struct {
+	sink AuditSink
+	key  []byte
+
+	mu   sync.Mutex
+	seq  uint64
+	prev string
+	now  func() time.Time
+}
+
+// Creates an auditor writing to sink. If key is not empty, records are signed with HMAC-SHA256,
+// otherwise a plain SHA-256 chain is used.
+func NewAuditor(sink AuditSink, key []byte) *Auditor {
+	return &Auditor{sink: sink, key: key, now: time.Now}
+}
+
+// Continues an existing chain, e.g. after a restart. last is the most recent record written to the sink.
+func (a *Auditor) Resume(last *AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.seq = last.Sequence
+	a.prev = last.Hash
+}
+
+func (a *Auditor) record(rec *AuditRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rec.Sequence = a.seq + 1
+	rec.Time = a.now().UTC()
+	rec.PrevHash = a.prev
+
+	h, err := auditHash(rec, a.key)
+	if err != nil {
+		return err
+	}
+	rec.Hash = h
+
+	// the chain only advances once the record is written, so a failed write leaves no gap
+	if err := a.sink.Write(rec); err != nil {
+		return err
+	}
+
+	a.seq = rec.Sequence
+	a.prev = rec.Hash
+	return nil
+}
+
+type actorKey struct{}
+
+// Returns a context attributing the SuretaxClient calls made with it to actor in audit records.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func callActor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+func sendOutcome(res *Response) string {
+	switch {
+	case res.declined():
+		return "declined"
+	case len(res.ItemMessages) > 0:
+		return "item errors"
+	}
+	return "success"
+}
+
+func (a *Auditor) recordSend(ctx context.Context, fingerprint string, req *Request, res *Response) {
+	rec := &AuditRecord{
+		Operation:          "send",
+		RequestFingerprint: fingerprint,
+		ClientTracking:     req.ClientTracking,
+		STAN:               req.STAN,
+		TransId:            res.TransId,
+		ResponseCode:       res.ResponseCode,
+		TotalRevenue:       req.TotalRevenue,
+		TotalTax:           res.TotalTax,
+		Actor:              callActor(ctx),
+		Outcome:            sendOutcome(res),
+	}
+	if err := a.record(rec); err != nil {
+		logger.Error("Audit record failed", "TransId", res.TransId, "Error", err)
+	}
+}
+
+func (a *Auditor) recordCancel(ctx context.Context, fingerprint string, req *CancelRequest, res *CancelResponse) {
+	rec := &AuditRecord{
+		Operation:          "cancel",
+		RequestFingerprint: fingerprint,
+		ClientTracking:     req.ClientTracking,
+		TransId:            res.TransId,
+		ResponseCode:       res.ResponseCode,
+		Actor:              callActor(ctx),
+		Outcome:            res.Outcome().String(),
+	}
+	if err := a.record(rec); err != nil {
+		logger.Error("Audit record failed", "TransId", res.TransId, "Error", err)
+	}
+}
+
+// Checks that records form an unbroken chain signed with key (or plain SHA-256 if key is empty).
+// Records must be in sequence order; the first one may be any point of the chain.
+func VerifyAuditChain(records []*AuditRecord, key []byte) error {
+	for i, rec := range records {
+		if i > 0 {
+			prev := records[i-1]
+			if rec.Sequence != prev.Sequence+1 {
+				return fmt.Errorf("Audit chain broken: record %d follows record %d", rec.Sequence, prev.Sequence)
+			}
+			if rec.PrevHash != prev.Hash {
+				return fmt.Errorf("Audit chain broken: record %d does not reference record %d", rec.Sequence, prev.Sequence)
+			}
+		}
+
+		h, err := auditHash(rec, key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(h), []byte(rec.Hash)) {
+			return fmt.Errorf("Audit record %d was altered", rec.Sequence)
+		}
+	}
+	return nil
+}
+
+func auditHash(rec *AuditRecord, key []byte) (string, error) {
+	c := *rec
+	c.Hash = ""
+
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(data)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Returns the SHA-256 of the request body.
+func fingerprintBody(r *http.Request) (string, error) {
+	body, err := r.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
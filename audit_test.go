@@ -0,0 +1,78 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type sliceAuditSink struct {
+	records []*AuditRecord
+}
+
+func (s *sliceAuditSink) Write(rec *AuditRecord) error {
+	s.records = append(s.records, rec)
+	return nil
+}
+
+// Fails the next fail writes.
+type flakyAuditSink struct {
+	sliceAuditSink
+	fail int
+}
+
+func (s *flakyAuditSink) Write(rec *AuditRecord) error {
+	if s.fail > 0 {
+		s.fail--
+		return errors.New("sink unavailable")
+	}
+	return s.sliceAuditSink.Write(rec)
+}
+
+func Test_VerifyAuditChain(t *testing.T) {
+
+	key := []byte("secret")
+	sink := &sliceAuditSink{}
+	a := NewAuditor(sink, key)
+
+	req := getTestRequest()
+	for i := 0; i < 3; i++ {
+		a.recordSend(context.Background(), "fingerprint", req, &Response{TransId: 616039832 + i, TotalTax: "28.65"})
+	}
+
+	if err := VerifyAuditChain(sink.records, key); err != nil {
+		t.Fatal(err)
+	}
+
+	sink.records[1].TotalTax = "0.00"
+
+	if err := VerifyAuditChain(sink.records, key); err == nil {
+		t.Fatal("Expected altered record to be detected")
+	}
+
+	sink.records[1].TotalTax = "28.65"
+	sink.records = append(sink.records[:1], sink.records[2:]...)
+
+	if err := VerifyAuditChain(sink.records, key); err == nil {
+		t.Fatal("Expected removed record to be detected")
+	}
+}
+
+func Test_Auditor_failedWrite(t *testing.T) {
+
+	sink := &flakyAuditSink{}
+	a := NewAuditor(sink, nil)
+
+	req := getTestRequest()
+	a.recordSend(context.Background(), "fingerprint", req, &Response{TransId: 1})
+	sink.fail = 1
+	a.recordSend(context.Background(), "fingerprint", req, &Response{TransId: 2})
+	a.recordSend(context.Background(), "fingerprint", req, &Response{TransId: 3})
+
+	if len(sink.records) != 2 || sink.records[1].Sequence != 2 {
+		t.Fatalf("Expected the failed record to leave no gap but got %+v", sink.records)
+	}
+	if err := VerifyAuditChain(sink.records, nil); err != nil {
+		t.Fatalf("Expected the chain to be intact after a failed write but got %v", err)
+	}
+}
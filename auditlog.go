@@ -0,0 +1,209 @@
+package suretax
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// When an AuditLog starts a new file and how long rotated files are kept. Zero fields disable the limit.
+type AuditRotation struct {
+	// Size of the current file after which the next record starts a new one.
+	MaxBytes int64
+
+	// Age of the current file after which the next record starts a new one, e.g. 24 * time.Hour.
+	MaxAge time.Duration
+
+	// Number of rotated files kept, oldest removed first.
+	MaxBackups int
+
+	// Age of rotated files after which they are removed, e.g. 7 years for SOX.
+	Retention time.Duration
+}
+
+// AuditSink appending one JSON line per record to a file, separate from the debug logs.
+// Rotated files are renamed with the UTC time of rotation, e.g. audit-20261014T120000Z.jsonl for audit.jsonl.
+// The chain of the Auditor continues across files. Safe for concurrent use.
+//
+//	log, err := suretax.NewAuditLog("/var/log/suretax/audit.jsonl", suretax.AuditRotation{MaxAge: 24 * time.Hour, Retention: 7 * 365 * 24 * time.Hour})
+//	client.Auditor = suretax.NewAuditor(log, key)
+type AuditLog struct {
+	path     string
+	rotation AuditRotation
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+	now    func() time.Time
+}
+
+// Opens the audit log at path for appending, creating it with mode 0600 if needed.
+func NewAuditLog(path string, rotation AuditRotation) (*AuditLog, error) {
+	l := &AuditLog{path: path, rotation: rotation, now: time.Now}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *AuditLog) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	l.file, l.size = f, info.Size()
+	l.opened = info.ModTime()
+	if l.size == 0 {
+		l.opened = l.now()
+	}
+	return nil
+}
+
+func (l *AuditLog) Write(rec *AuditRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("Audit log %s is closed", l.path)
+	}
+	if l.due(int64(len(line))) {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// Reports whether writing n more bytes should start a new file.
+func (l *AuditLog) due(n int64) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.rotation.MaxBytes > 0 && l.size+n > l.rotation.MaxBytes {
+		return true
+	}
+	return l.rotation.MaxAge > 0 && l.now().Sub(l.opened) >= l.rotation.MaxAge
+}
+
+func (l *AuditLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+
+	ext := filepath.Ext(l.path)
+	base := strings.TrimSuffix(l.path, ext)
+	stamp := l.now().UTC().Format("20060102T150405Z")
+	rotated := base + "-" + stamp + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			break
+		}
+		rotated = fmt.Sprintf("%s-%s.%d%s", base, stamp, i, ext)
+	}
+
+	if err := os.Rename(l.path, rotated); err != nil {
+		return err
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+	return l.prune()
+}
+
+// Removes the rotated files beyond MaxBackups or older than Retention.
+func (l *AuditLog) prune() error {
+	backups, err := l.Backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, path := range backups {
+		remove := l.rotation.MaxBackups > 0 && i < len(backups)-l.rotation.MaxBackups
+		if !remove && l.rotation.Retention > 0 {
+			if info, err := os.Stat(path); err == nil && l.now().Sub(info.ModTime()) > l.rotation.Retention {
+				remove = true
+			}
+		}
+		if remove {
+			if err := os.Remove(path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Pruning audit log %s failed: %v", l.path, errs)
+	}
+	return nil
+}
+
+// Returns the paths of the rotated files, oldest first.
+func (l *AuditLog) Backups() ([]string, error) {
+	ext := filepath.Ext(l.path)
+	matches, err := filepath.Glob(strings.TrimSuffix(l.path, ext) + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	// the timestamps sort chronologically
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Reads the records of an AuditLog file, e.g. to check them with VerifyAuditChain
+// or to Resume the chain from the last one.
+func ReadAuditLog(r io.Reader) ([]*AuditRecord, error) {
+	var records []*AuditRecord
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		rec := &AuditRecord{}
+		if err := json.Unmarshal(sc.Bytes(), rec); err != nil {
+			return records, fmt.Errorf("Audit log line %d: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, sc.Err()
+}
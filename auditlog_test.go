@@ -0,0 +1,87 @@
+package suretax
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_AuditLog_Rotation(t *testing.T) {
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	l, err := NewAuditLog(path, AuditRotation{MaxAge: time.Hour, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.now = func() time.Time { return now }
+	l.opened = now
+
+	key := []byte("secret")
+	a := NewAuditor(l, key)
+	ctx := WithActor(context.Background(), "analyst@example.com")
+
+	for i := 0; i < 4; i++ {
+		a.recordSend(ctx, "fingerprint", getTestRequest(), &Response{ResponseCode: "9999", Successful: "Y", TransId: 100 + i, TotalTax: "28.65"})
+		now = now.Add(time.Hour)
+	}
+
+	backups, err := l.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || filepath.Base(backups[0]) != "audit-20261014T140000Z.jsonl" {
+		t.Fatalf("Expected the two newest rotated files but got %v", backups)
+	}
+
+	var all []*AuditRecord
+	for _, p := range append(backups, path) {
+		f, err := os.Open(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := ReadAuditLog(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, records...)
+	}
+
+	if len(all) != 3 || all[0].Sequence != 2 {
+		t.Fatalf("Expected records 2 to 4 but got %d records", len(all))
+	}
+	if all[0].Actor != "analyst@example.com" || all[0].Outcome != "success" || all[0].RequestFingerprint != "fingerprint" {
+		t.Fatalf("Expected the actor and outcome to be recorded but got %+v", all[0])
+	}
+	if err := VerifyAuditChain(all, key); err != nil {
+		t.Fatalf("Expected the chain to continue across files but got %v", err)
+	}
+}
+
+func Test_AuditLog_MaxBytes(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := NewAuditLog(path, AuditRotation{MaxBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := l.Write(&AuditRecord{Sequence: uint64(i + 1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backups, _ := l.Backups(); len(backups) != 2 {
+		t.Fatalf("Expected a file per record but got %v", backups)
+	}
+
+	l.Close()
+	if err := l.Write(&AuditRecord{}); err == nil {
+		t.Fatal("Expected an error writing to a closed log")
+	}
+}
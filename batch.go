@@ -0,0 +1,131 @@
+package suretax
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Items per request sent by SendBatch unless SuretaxClient.BatchSize is set.
+const DefaultBatchSize = 1000
+
+// Outcome of SendBatch.
+type BatchResult struct {
+	// Merged response of all requests: GroupList and ItemMessages concatenated, TotalTax summed.
+	// TransId is the one of the first request. Nil if a request failed.
+	Response *Response
+
+	// Requests sent, in item order, and their responses. A response is nil if its request failed or wasn't sent.
+	Requests  []*Request
+	Responses []*Response
+}
+
+// Returned by SendBatch when one of its requests failed.
+type BatchError struct {
+	// Index of the failed request in BatchResult.Requests.
+	Request int
+
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("Batch request %d failed: %v", e.Request+1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Sends items with the fields of header, split into requests of at most BatchSize items,
+// BatchConcurrency at a time, and merges the responses.
+// Line numbers are assigned across the whole batch first, so they stay unique in the merged response.
+// When the items are split, each request gets its own TotalRevenue, an empty STAN unless STAN generation
+// is enabled, and ClientTracking suffixed with -1, -2... so every request is a transaction of its own.
+// The first failure cancels the requests not yet sent; the result then holds the responses received so far.
+func (c *SuretaxClient) SendBatch(ctx context.Context, items []*RequestItem, header Request) (*BatchResult, error) {
+	list := make([]RequestItem, len(items))
+	for i, item := range items {
+		list[i] = *item
+	}
+	header.ItemList = list
+	if err := AssignLineNumbers(&header); err != nil {
+		return nil, err
+	}
+
+	size := c.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	result := &BatchResult{}
+	if len(list) <= size {
+		result.Requests = []*Request{&header}
+	} else {
+		for start, n := 0, 1; start < len(list); start, n = start+size, n+1 {
+			sub, err := subRequest(&header, list[start:min(start+size, len(list))])
+			if err != nil {
+				return nil, err
+			}
+			if header.ClientTracking != "" {
+				sub.ClientTracking = header.ClientTracking + "-" + strconv.Itoa(n)
+			}
+			result.Requests = append(result.Requests, sub)
+		}
+	}
+	result.Responses = make([]*Response, len(result.Requests))
+
+	workers := max(1, min(c.BatchConcurrency, len(result.Requests)))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var failure *BatchError
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if ctx.Err() != nil {
+					continue
+				}
+				res, err := c.SendContext(ctx, result.Requests[i])
+				mu.Lock()
+				if err != nil {
+					if failure == nil || i < failure.Request {
+						failure = &BatchError{Request: i, Err: err}
+					}
+					cancel()
+				} else {
+					result.Responses[i] = res
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range result.Requests {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if failure != nil {
+		return result, failure
+	}
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+
+	merged, err := mergeResponses(result.Responses)
+	if err != nil {
+		return result, err
+	}
+	result.Response = merged
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package suretax
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func getBatchTestItems(n int) []*RequestItem {
+	items := make([]*RequestItem, n)
+	for i := range items {
+		items[i] = &RequestItem{Revenue: "10"}
+	}
+	return items
+}
+
+// Answers every request with one group per item and a 1.00 tax, failing the requests with a TransId in fail.
+func batchTestClient(t *testing.T, fail string) (HttpClient, *[]*Request) {
+	var mu sync.Mutex
+	var sent []*Request
+	return httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		req := decodeTestRequest(t, r)
+		mu.Lock()
+		sent = append(sent, req)
+		transId := len(sent)
+		mu.Unlock()
+
+		if fail != "" && req.ClientTracking == fail {
+			return okResponse(envelope(`{"ResponseCode":"1101","HeaderMessage":"Failure","Successful":"N"}`)), nil
+		}
+		groups := make([]string, len(req.ItemList))
+		for i, item := range req.ItemList {
+			groups[i] = fmt.Sprintf(`{"LineNumber":"%s","TaxList":[{"TaxTypeCode":"035","TaxAmount":"1.00"}]}`, item.LineNumber)
+		}
+		return okResponse(envelope(fmt.Sprintf(`{"ResponseCode":"9999","Successful":"Y","TransId":%d,"TotalTax":"%d.00","GroupList":[%s]}`,
+			transId, len(req.ItemList), strings.Join(groups, ",")))), nil
+	}), &sent
+}
+
+func Test_SendBatch(t *testing.T) {
+
+	transport, sent := batchTestClient(t, "")
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport))
+	cli.BatchSize = 4
+	cli.BatchConcurrency = 2
+
+	res, err := cli.SendBatch(context.Background(), getBatchTestItems(10), Request{ClientNumber: "000000001", ReturnFileCode: "0", ClientTracking: "run"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*sent) != 3 || len(res.Requests) != 3 || len(res.Requests[2].ItemList) != 2 {
+		t.Fatalf("Expected 10 items in requests of 4, 4 and 2 but got %d requests", len(*sent))
+	}
+	if res.Requests[1].ClientTracking != "run-2" || res.Requests[1].TotalRevenue != "40" || res.Requests[1].ItemList[0].LineNumber != "5" {
+		t.Fatalf("Expected the second request to continue the line numbers but got %+v", res.Requests[1])
+	}
+	if len(res.Response.GroupList) != 10 || res.Response.TotalTax != "10.00" || res.Response.GroupList[9].LineNumber != "10" {
+		t.Fatalf("Expected the merged response of all items but got %+v", res.Response)
+	}
+
+	single, err := cli.SendBatch(context.Background(), getBatchTestItems(2), Request{ClientTracking: "one", STAN: "S1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := single.Requests[0]; r.ClientTracking != "one" || r.STAN != "S1" {
+		t.Fatalf("Expected a single request to keep the header as is but got %+v", r)
+	}
+}
+
+func Test_SendBatch_Failure(t *testing.T) {
+
+	transport, sent := batchTestClient(t, "run-2")
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport))
+	cli.BatchSize = 2
+
+	res, err := cli.SendBatch(context.Background(), getBatchTestItems(8), Request{ClientTracking: "run"})
+	berr, ok := err.(*BatchError)
+	if !ok || berr.Request != 1 {
+		t.Fatalf("Expected a *BatchError for the second request but got %v", err)
+	}
+	if _, ok := berr.Err.(*ResponseError); !ok {
+		t.Fatalf("Expected the cause to be unwrapped but got %T", berr.Err)
+	}
+	if len(*sent) != 2 || res.Response != nil || res.Responses[0] == nil || res.Responses[2] != nil {
+		t.Fatalf("Expected the remaining requests not to be sent but got %d sent, %+v", len(*sent), res)
+	}
+}
@@ -0,0 +1,189 @@
+package suretax
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Returned without contacting SureTax while a CircuitBreaker is open. IsTransient reports it as transient,
+// so fallbacks and estimates engage as they would for the outage itself.
+var ErrCircuitOpen = errors.New("SureTax circuit breaker is open")
+
+// State of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// Calls are sent to SureTax.
+	CircuitClosed CircuitState = iota
+
+	// Calls fail with ErrCircuitOpen until OpenDuration has passed.
+	CircuitOpen
+
+	// A limited number of probe calls is let through to find out whether SureTax has recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// Fails calls fast while SureTax is down instead of letting each one wait for its timeout.
+// The circuit opens after FailureThreshold consecutive transient failures (network errors, timeouts,
+// 5xx and 429 responses); once OpenDuration has passed, HalfOpenProbes calls are let through and
+// the circuit closes if they all succeed, or opens again on the first failure.
+// Set as SuretaxClient.Breaker, possibly shared by several clients of the same SureTax host.
+// Must not be copied after first use. Safe for concurrent use.
+type CircuitBreaker struct {
+	// Consecutive failures opening the circuit. Defaults to 5.
+	FailureThreshold int
+
+	// Time the circuit stays open before probing. Defaults to 30 seconds.
+	OpenDuration time.Duration
+
+	// Probe calls let through while half-open. Defaults to 1.
+	HalfOpenProbes int
+
+	// Optional callback invoked on every state change, outside the breaker's lock.
+	OnStateChange func(from, to CircuitState)
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probes    int // probes in flight
+	successes int // successful probes
+	now       func() time.Time
+}
+
+// Returns the current state of the breaker.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && b.clock().Sub(b.openedAt) >= b.openDuration() {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+func (b *CircuitBreaker) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+func (b *CircuitBreaker) openDuration() time.Duration {
+	if b.OpenDuration > 0 {
+		return b.OpenDuration
+	}
+	return 30 * time.Second
+}
+
+func (b *CircuitBreaker) threshold() int {
+	if b.FailureThreshold > 0 {
+		return b.FailureThreshold
+	}
+	return 5
+}
+
+func (b *CircuitBreaker) halfOpenProbes() int {
+	if b.HalfOpenProbes > 0 {
+		return b.HalfOpenProbes
+	}
+	return 1
+}
+
+// Admits a call or fails with ErrCircuitOpen. The returned function reports whether the admitted call failed.
+func (b *CircuitBreaker) allow() (func(failed bool), error) {
+	b.mu.Lock()
+	from := b.state
+	if b.state == CircuitOpen {
+		if b.clock().Sub(b.openedAt) < b.openDuration() {
+			b.mu.Unlock()
+			return nil, ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.successes = 0
+	}
+
+	probe := b.state == CircuitHalfOpen
+	if probe {
+		if b.probes+b.successes >= b.halfOpenProbes() {
+			b.mu.Unlock()
+			return nil, ErrCircuitOpen
+		}
+		b.probes++
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+	return func(failed bool) { b.report(probe, failed) }, nil
+}
+
+func (b *CircuitBreaker) report(probe, failed bool) {
+	b.mu.Lock()
+	from := b.state
+
+	if probe {
+		b.probes--
+	}
+	switch {
+	case b.state == CircuitOpen, b.state == CircuitHalfOpen && !probe:
+		// admitted before the circuit last opened, says nothing about the recovery
+	case failed && (b.state == CircuitHalfOpen || b.failures+1 >= b.threshold()):
+		b.state = CircuitOpen
+		b.openedAt = b.clock()
+		b.failures = 0
+	case failed:
+		b.failures++
+	case b.state == CircuitHalfOpen:
+		b.successes++
+		if b.successes >= b.halfOpenProbes() {
+			b.state = CircuitClosed
+		}
+	default:
+		b.failures = 0
+	}
+
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+}
+
+func (b *CircuitBreaker) changed(from, to CircuitState) {
+	if from != to && b.OnStateChange != nil {
+		b.OnStateChange(from, to)
+	}
+}
+
+// HttpClient passing the requests through a CircuitBreaker.
+type breakerClient struct {
+	breaker *CircuitBreaker
+	client  HttpClient
+}
+
+func (c breakerClient) Do(r *http.Request) (*http.Response, error) {
+	done, err := c.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(r)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		done(IsTransient(&HttpError{resp.StatusCode, resp.Status}))
+	} else {
+		done(IsTransient(err))
+	}
+	return resp, err
+}
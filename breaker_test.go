@@ -0,0 +1,88 @@
+package suretax
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_CircuitBreaker(t *testing.T) {
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	var changes []string
+	b := &CircuitBreaker{FailureThreshold: 2, OpenDuration: time.Minute, HalfOpenProbes: 1, now: func() time.Time { return now }}
+	b.OnStateChange = func(from, to CircuitState) { changes = append(changes, from.String()+">"+to.String()) }
+
+	status := 503
+	var posted int
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		posted++
+		if status != 200 {
+			return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: http.NoBody}, nil
+		}
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)), nil
+	})
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport))
+	cli.Breaker = b
+
+	cli.Send(getTestRequest())
+	cli.Send(getTestRequest())
+	if _, err := cli.Send(getTestRequest()); !errors.Is(err, ErrCircuitOpen) || !IsTransient(err) || posted != 2 {
+		t.Fatalf("Expected the circuit to open after 2 failures but got %v after %v requests", err, posted)
+	}
+
+	now = now.Add(time.Minute)
+	if b.State() != CircuitHalfOpen {
+		t.Fatalf("Expected the circuit to be half-open but got %v", b.State())
+	}
+	if _, err := cli.Send(getTestRequest()); err == nil || errors.Is(err, ErrCircuitOpen) || b.State() != CircuitOpen {
+		t.Fatalf("Expected the failed probe to open the circuit again but got %v, %v", err, b.State())
+	}
+
+	now = now.Add(time.Minute)
+	status = 200
+	if _, err := cli.Send(getTestRequest()); err != nil || b.State() != CircuitClosed {
+		t.Fatalf("Expected the successful probe to close the circuit but got %v, %v", err, b.State())
+	}
+
+	status = 400
+	for i := 0; i < 3; i++ {
+		cli.Send(getTestRequest())
+	}
+	if b.State() != CircuitClosed {
+		t.Fatalf("Expected client errors to leave the circuit closed but got %v", b.State())
+	}
+
+	expected := "[closed>open open>half-open half-open>open open>half-open half-open>closed]"
+	if s := fmt.Sprint(changes); s != expected {
+		t.Fatalf("Expected state changes %v but got %v", expected, s)
+	}
+}
+
+func Test_CircuitBreaker_Probes(t *testing.T) {
+
+	now := time.Now()
+	b := &CircuitBreaker{FailureThreshold: 1, HalfOpenProbes: 2, now: func() time.Time { return now }}
+
+	done, _ := b.allow()
+	done(true)
+	now = now.Add(30 * time.Second)
+
+	probe1, err1 := b.allow()
+	probe2, err2 := b.allow()
+	_, err3 := b.allow()
+	if err1 != nil || err2 != nil || err3 != ErrCircuitOpen {
+		t.Fatalf("Expected 2 probes to be let through but got %v, %v, %v", err1, err2, err3)
+	}
+
+	probe1(false)
+	if b.State() != CircuitHalfOpen {
+		t.Fatalf("Expected the circuit to stay half-open until both probes succeed but got %v", b.State())
+	}
+	probe2(false)
+	if b.State() != CircuitClosed {
+		t.Fatalf("Expected the circuit to close but got %v", b.State())
+	}
+}
@@ -0,0 +1,81 @@
+package suretax
+
+import (
+	"sort"
+	"time"
+)
+
+// Thresholds applied when a UsageBudget has none: 80% and 100% of the expected volume.
+var DefaultBudgetThresholds = []float64{0.8, 1}
+
+// Expected monthly volume of billable calls, counted by a UsageMeter per calendar month in UTC.
+type UsageBudget struct {
+	// Billable calls expected per month.
+	Expected int64
+
+	// Fractions of Expected at which OnThreshold is called, e.g. 0.8. Defaults to DefaultBudgetThresholds.
+	Thresholds []float64
+
+	// Called synchronously, once per threshold and month.
+	OnThreshold func(BudgetEvent)
+}
+
+// Passed to UsageBudget.OnThreshold when the calls of a month reach a threshold.
+type BudgetEvent struct {
+	// Month in the form 2006-01.
+	Month string
+
+	Expected  int64
+	Used      int64
+	Threshold float64
+
+	Time time.Time
+}
+
+// Monthly count checked against a UsageBudget.
+type budgetState struct {
+	budget UsageBudget
+	month  string
+	used   int64
+
+	// thresholds already reported this month
+	reported int
+}
+
+// Sets the budget the billable calls of the meter are checked against. Calls made earlier this month
+// are not counted. A zero Expected removes the budget.
+func (m *UsageMeter) SetBudget(b UsageBudget) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if b.Expected <= 0 {
+		m.budget = nil
+		return
+	}
+	if len(b.Thresholds) == 0 {
+		b.Thresholds = DefaultBudgetThresholds
+	}
+	b.Thresholds = append([]float64(nil), b.Thresholds...)
+	sort.Float64s(b.Thresholds)
+
+	m.budget = &budgetState{budget: b, month: m.now().UTC().Format("2006-01")}
+}
+
+// Counts a billable call against the budget, returning the thresholds it reached. Called with mu held.
+func (s *budgetState) count(now time.Time) []BudgetEvent {
+	if month := now.UTC().Format("2006-01"); month != s.month {
+		s.month, s.used, s.reported = month, 0, 0
+	}
+	s.used++
+
+	var events []BudgetEvent
+	for s.reported < len(s.budget.Thresholds) {
+		th := s.budget.Thresholds[s.reported]
+		if float64(s.used) < th*float64(s.budget.Expected) {
+			break
+		}
+		events = append(events, BudgetEvent{s.month, s.budget.Expected, s.used, th, now})
+		s.reported++
+	}
+	return events
+}
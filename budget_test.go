@@ -0,0 +1,39 @@
+package suretax
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_UsageMeter_Budget(t *testing.T) {
+
+	m := NewUsageMeter()
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	var events []BudgetEvent
+	m.SetBudget(UsageBudget{Expected: 10, OnThreshold: func(e BudgetEvent) { events = append(events, e) }})
+
+	key := UsageKey{Endpoint: UsageEndpointSend}
+	for i := 0; i < 12; i++ {
+		m.record(key, 1)
+	}
+	if len(events) != 2 || events[0].Used != 8 || events[0].Threshold != 0.8 || events[1].Used != 10 {
+		t.Fatalf("Expected events at 8 and 10 calls but got %+v", events)
+	}
+
+	now = now.Add(24 * time.Hour)
+	for i := 0; i < 8; i++ {
+		m.record(key, 1)
+	}
+	if len(events) != 3 || events[2].Month != "2026-02" {
+		t.Fatalf("Expected the count to restart in February but got %+v", events)
+	}
+
+	m.SetBudget(UsageBudget{Expected: 1, Thresholds: []float64{2, 0.5}, OnThreshold: func(e BudgetEvent) { events = append(events, e) }})
+	m.record(key, 1)
+	m.record(key, 1)
+	if len(events) != 5 || events[3].Threshold != 0.5 || events[4].Threshold != 2 {
+		t.Fatalf("Expected the thresholds in ascending order but got %+v", events[3:])
+	}
+}
@@ -0,0 +1,203 @@
+package suretax
+
+import (
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// Builds a Request with the defaults most integrations use: ReturnFileCode 0, ResponseGroup 00,
+// ResponseType D2, sequential line numbers and TotalRevenue summed from the items.
+// Credentials are left to SuretaxClient.Credentials.
+//
+//	req, err := suretax.NewRequestBuilder().
+//		DataPeriod(time.Now()).
+//		ClientTracking("INV-2026-001").
+//		AddItem(suretax.NewItem("010101", "10.00").Invoice("INV2026001").Address(addr)).
+//		Build()
+type RequestBuilder struct {
+	req   Request
+	items []*ItemBuilder
+}
+
+func NewRequestBuilder() *RequestBuilder {
+	return &RequestBuilder{req: Request{
+		ReturnFileCode: "0",
+		ResponseGroup:  "00",
+		ResponseType:   "D2",
+	}}
+}
+
+// Sets the tax calculation and remittance periods to the month of t, see Request.SetDataPeriod.
+func (b *RequestBuilder) DataPeriod(t time.Time) *RequestBuilder {
+	b.req.SetDataPeriod(t)
+	return b
+}
+
+func (b *RequestBuilder) BusinessUnit(unit string) *RequestBuilder {
+	b.req.BusinessUnit = unit
+	return b
+}
+
+func (b *RequestBuilder) ClientTracking(tracking string) *RequestBuilder {
+	b.req.ClientTracking = tracking
+	return b
+}
+
+func (b *RequestBuilder) STAN(stan string) *RequestBuilder {
+	b.req.STAN = stan
+	return b
+}
+
+// Sets ReturnFileCode to Q, so taxes are calculated without being recorded.
+func (b *RequestBuilder) Quote() *RequestBuilder {
+	b.req.ReturnFileCode = "Q"
+	return b
+}
+
+func (b *RequestBuilder) ResponseGroup(group string) *RequestBuilder {
+	b.req.ResponseGroup = group
+	return b
+}
+
+func (b *RequestBuilder) ResponseType(responseType string) *RequestBuilder {
+	b.req.ResponseType = responseType
+	return b
+}
+
+// Appends the items to the request, in order.
+func (b *RequestBuilder) AddItem(items ...*ItemBuilder) *RequestBuilder {
+	b.items = append(b.items, items...)
+	return b
+}
+
+// Returns the request. Items without a LineNumber are numbered sequentially and TotalRevenue is
+// the sum of the item revenues. Returns a *ValidationError for duplicate line numbers or an invalid Revenue.
+// The builder can be reused, every call returns a new Request.
+func (b *RequestBuilder) Build() (*Request, error) {
+	req := b.req
+	req.ItemList = make([]RequestItem, len(b.items))
+	for i, item := range b.items {
+		req.ItemList[i] = item.Build()
+		if _, err := parseAmount(req.ItemList[i].Revenue); err != nil {
+			return nil, &ValidationError{LineNumber: req.ItemList[i].LineNumber, Field: "Revenue", Message: err.Error()}
+		}
+	}
+
+	if err := AssignLineNumbers(&req); err != nil {
+		return nil, err
+	}
+
+	total, err := sumRevenue(req.ItemList)
+	if err != nil {
+		return nil, err
+	}
+	req.TotalRevenue = total
+
+	return &req, nil
+}
+
+// Builds a RequestItem, see NewItem.
+type ItemBuilder struct {
+	item RequestItem
+}
+
+// Returns a builder for an item of the given trans type and revenue with Units 1, Seconds 1,
+// TaxIncludedCode 0, UnitType 00 and SalesTypeCode R, the SureTax defaults.
+func NewItem(transTypeCode, revenue string) *ItemBuilder {
+	return &ItemBuilder{item: RequestItem{
+		TransTypeCode:        transTypeCode,
+		Revenue:              revenue,
+		Units:                "1",
+		UnitType:             UnitTypeLines,
+		Seconds:              "1",
+		TaxIncludedCode:      "0",
+		SalesTypeCode:        SalesResidential,
+		TaxExemptionCodeList: []string{},
+	}}
+}
+
+func (b *ItemBuilder) LineNumber(lineNumber string) *ItemBuilder {
+	b.item.LineNumber = lineNumber
+	return b
+}
+
+func (b *ItemBuilder) Invoice(invoiceNumber string) *ItemBuilder {
+	b.item.InvoiceNumber = invoiceNumber
+	return b
+}
+
+func (b *ItemBuilder) Customer(customerNumber string) *ItemBuilder {
+	b.item.CustomerNumber = customerNumber
+	return b
+}
+
+// Sets Revenue to r, formatted with FormatAmount.
+func (b *ItemBuilder) Revenue(r *big.Rat) *ItemBuilder {
+	b.item.SetRevenue(r)
+	return b
+}
+
+// Sets TransDate to t in MM/DD/YYYY format.
+func (b *ItemBuilder) TransDate(t time.Time) *ItemBuilder {
+	b.item.SetTransDate(t, DateFormatUS)
+	return b
+}
+
+func (b *ItemBuilder) Situs(rule TaxSitusRule) *ItemBuilder {
+	b.item.TaxSitusRule = rule
+	return b
+}
+
+// Sets the billing address. Use Situs to choose how it locates the item.
+func (b *ItemBuilder) Address(a Address) *ItemBuilder {
+	b.item.Address = a
+	return b
+}
+
+// Sets the Z endpoint of point to point items.
+func (b *ItemBuilder) P2PAddress(a P2PAddress) *ItemBuilder {
+	b.item.P2PAddress = a
+	return b
+}
+
+// Sets OrigNumber, TermNumber and BillToNumber.
+func (b *ItemBuilder) Numbers(orig, term, billTo string) *ItemBuilder {
+	b.item.OrigNumber, b.item.TermNumber, b.item.BillToNumber = orig, term, billTo
+	return b
+}
+
+func (b *ItemBuilder) SalesType(code SalesTypeCode) *ItemBuilder {
+	b.item.SalesTypeCode = code
+	return b
+}
+
+func (b *ItemBuilder) Regulatory(code RegulatoryCode) *ItemBuilder {
+	b.item.RegulatoryCode = code
+	return b
+}
+
+func (b *ItemBuilder) Units(units int, unitType UnitType) *ItemBuilder {
+	b.item.Units = strconv.Itoa(units)
+	b.item.UnitType = unitType
+	return b
+}
+
+// Sets TaxIncludedCode to 1, Revenue then includes the taxes.
+func (b *ItemBuilder) TaxIncluded() *ItemBuilder {
+	b.item.TaxIncludedCode = "1"
+	return b
+}
+
+func (b *ItemBuilder) Exemptions(reasonCode string, codes ...string) *ItemBuilder {
+	b.item.ExemptReasonCode = reasonCode
+	b.item.TaxExemptionCodeList = append([]string{}, codes...)
+	return b
+}
+
+// Returns a copy of the item.
+func (b *ItemBuilder) Build() RequestItem {
+	item := b.item
+	item.TaxExemptionCodeList = append([]string{}, b.item.TaxExemptionCodeList...)
+	return item
+}
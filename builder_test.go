@@ -0,0 +1,44 @@
+package suretax
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func Test_RequestBuilder(t *testing.T) {
+
+	b := NewRequestBuilder().
+		DataPeriod(time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)).
+		ClientTracking("INV-1").
+		AddItem(
+			NewItem("010101", "10.00").Situs(SitusZipCode).Address(Address{PostalCode: "60601"}),
+			NewItem("010101", "").Revenue(big.NewRat(1, 8)).LineNumber("1"),
+		)
+
+	req, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.ReturnFileCode != "0" || req.DataYear != "2026" || req.CmplDataMonth != "10" || req.ClientTracking != "INV-1" {
+		t.Fatalf("Expected the header defaults but got %+v", req)
+	}
+	if req.TotalRevenue != "10.1250" {
+		t.Fatalf("Expected TotalRevenue 10.1250 but got %v", req.TotalRevenue)
+	}
+
+	first := req.ItemList[0]
+	if first.LineNumber != "2" || req.ItemList[1].LineNumber != "1" {
+		t.Fatalf("Expected line numbers 2 and 1 but got %v and %v", first.LineNumber, req.ItemList[1].LineNumber)
+	}
+	if first.Units != "1" || first.Seconds != "1" || first.TaxIncludedCode != "0" || first.UnitType != UnitTypeLines || first.TaxExemptionCodeList == nil {
+		t.Fatalf("Expected the item defaults but got %+v", first)
+	}
+	if errs := first.ValidateSitus(); errs != nil {
+		t.Fatalf("Expected a valid situs but got %v", errs)
+	}
+
+	if _, err := b.AddItem(NewItem("010101", "ten")).Build(); !IsValidationError(err) {
+		t.Fatalf("Expected an invalid revenue to fail but got %v", err)
+	}
+}
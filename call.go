@@ -0,0 +1,172 @@
+package suretax
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Form of the responses of an endpoint.
+type EnvelopeStyle int
+
+const (
+	// The response JSON is quoted in the "d" field of an object, as by the SureTax ASMX endpoints (default).
+	EnvelopeD EnvelopeStyle = iota
+
+	// The body is the response JSON itself.
+	EnvelopeNone
+)
+
+// SureTax endpoint called with Call: requests are posted wrapped in an object under WrapperKey
+// and answered according to Envelope.
+type Endpoint struct {
+	// Name counted by the client UsageMeter, e.g. "StatusQuery".
+	Name string
+
+	Url string
+
+	// Key of the request wrapper, e.g. "request". Empty posts the request JSON as is.
+	WrapperKey string
+
+	Envelope EnvelopeStyle
+
+	// Number of retries of transient failures (see IsTransient).
+	MaxRetries int
+
+	// Delay before the first retry, doubled for each following one. Defaults to one second.
+	Backoff time.Duration
+}
+
+// Posts req to endpoint through the client's transport, limiter and parse limits, and decodes the enveloped
+// response into a TResp. Meant for SureTax endpoints the package doesn't model, such as status queries.
+func Call[TReq, TResp any](ctx context.Context, c *SuretaxClient, endpoint Endpoint, req *TReq) (*TResp, error) {
+	res := new(TResp)
+	if err := invoke(ctx, c, endpoint, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Posts req to endpoint, retrying transient failures, and decodes the response into res.
+func invoke(ctx context.Context, c *SuretaxClient, endpoint Endpoint, req, res interface{}) error {
+	backoff := endpoint.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := invokeOnce(ctx, c, endpoint, req, res)
+		if err == nil || !IsTransient(err) || attempt >= endpoint.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		logger.Warn("Call failed, retrying", "Endpoint", endpoint.Name, "Url", endpoint.Url, "Error", err)
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// Makes a single attempt of invoke.
+func invokeOnce(ctx context.Context, c *SuretaxClient, endpoint Endpoint, req, res interface{}) error {
+	var r *http.Request
+	var err error
+	if endpoint.WrapperKey != "" {
+		r, err = newPost(endpoint.Url, endpoint.WrapperKey, req)
+	} else {
+		r, err = newBarePost(endpoint.Url, req)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.sign(r); err != nil {
+		return err
+	}
+
+	cl := newCallLog()
+	failed := true
+	defer func() { cl.done(failed) }()
+
+	if cl.enabled(LevelTrace) {
+		body, _ := requestBody(r)
+		cl.log(LevelTrace, "Request Data", "Payload", string(ScrubPayload([]byte(body))))
+	}
+
+	release, err := c.acquire(ctx, callPriority(ctx, nil), callTenant(ctx, nil))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	resp, err := c.getClient().Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	cl.log(LevelDebug, "SureTax HTTP response", "StatusCode", resp.StatusCode, "Status", resp.Status)
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &HttpError{resp.StatusCode, resp.Status}
+	}
+
+	bodyBytes, err := c.Limits.readBody(resp.Body)
+	release()
+	if err != nil {
+		return err
+	}
+
+	cl.log(LevelTrace, "Response Data", "Payload", string(ScrubPayload(bodyBytes)))
+
+	inner := bodyBytes
+	if endpoint.Envelope == EnvelopeD {
+		respw := ResponseWrapper{}
+		if err := json.Unmarshal(bodyBytes, &respw); err != nil {
+			return fmt.Errorf("Response Wrapper Unmarshal Failed. Error: %v", err)
+		}
+		inner = []byte(respw.D)
+	}
+
+	if err := c.Limits.check(string(inner)); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(inner, res); err != nil {
+		return fmt.Errorf("Response Unmarshal Failed. Error: %v", err)
+	}
+
+	if c.Usage != nil {
+		c.Usage.record(UsageKey{r.URL.Host, endpoint.Name, "", callTenant(ctx, nil)}, 0)
+	}
+
+	failed = false
+	return nil
+}
+
+// Returns a POST of the JSON of v.
+func newBarePost(url string, v interface{}) (*http.Request, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Add("Content-Type", "application/json")
+
+	return r, nil
+}
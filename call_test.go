@@ -0,0 +1,60 @@
+package suretax
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type statusQuery struct {
+	ClientNumber string
+	TransId      string
+}
+
+type statusResult struct {
+	ResponseCode string
+	Status       string
+}
+
+func Test_Call(t *testing.T) {
+
+	var attempts int
+	var sent requestWrapper
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return &http.Response{StatusCode: 503, Status: "503 Service Unavailable", Body: http.NoBody}, nil
+		}
+		raw := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&raw)
+		sent.Request = raw["statusRequest"]
+		return okResponse(envelope(`{"ResponseCode":"9999","Status":"Posted"}`)), nil
+	}))
+	defer SetHttpClient(nil)
+
+	meter := NewUsageMeter()
+	cli := &SuretaxClient{Usage: meter}
+	endpoint := Endpoint{Name: "Status", Url: "https://testapi.taxrating.net/status", WrapperKey: "statusRequest", MaxRetries: 1, Backoff: time.Millisecond}
+
+	res, err := Call[statusQuery, statusResult](context.Background(), cli, endpoint, &statusQuery{ClientNumber: "000000001", TransId: "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != "Posted" || attempts != 2 {
+		t.Fatalf("Expected the status after a retry but got %+v after %v attempts", res, attempts)
+	}
+	if sent.Request != `{"ClientNumber":"000000001","TransId":"42"}` {
+		t.Fatalf("Expected the wrapped query but got %v", sent.Request)
+	}
+	if s := meter.Snapshot(); len(s.Entries) != 1 || s.Entries[0].Endpoint != "Status" {
+		t.Fatalf("Expected the call to be counted but got %+v", s.Entries)
+	}
+
+	attempts = 0
+	endpoint.MaxRetries = 0
+	if _, err := Call[statusQuery, statusResult](context.Background(), cli, endpoint, &statusQuery{}); !IsTransient(err) || attempts != 1 {
+		t.Fatalf("Expected the transient error without retries but got %v", err)
+	}
+}
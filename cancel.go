@@ -0,0 +1,134 @@
+package suretax
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result of a cancel request.
+type CancelOutcome int
+
+const (
+	CancelOutcomeUnknown CancelOutcome = iota
+
+	// 9999 – Cancel Request was successful.
+	CancelOutcomeCancelled
+
+	// 9410 – Transaction is already cancelled.
+	CancelOutcomeAlreadyCancelled
+
+	// 1510 – Transaction is more than 60 days old.
+	CancelOutcomeTooOld
+
+	// 1150/1151 – Validation key missing or invalid.
+	CancelOutcomeInvalidKey
+
+	// The transaction ID is unknown to SureTax.
+	CancelOutcomeNotFound
+)
+
+func (o CancelOutcome) String() string {
+	switch o {
+	case CancelOutcomeCancelled:
+		return "cancelled"
+	case CancelOutcomeAlreadyCancelled:
+		return "already cancelled"
+	case CancelOutcomeTooOld:
+		return "too old"
+	case CancelOutcomeInvalidKey:
+		return "invalid key"
+	case CancelOutcomeNotFound:
+		return "not found"
+	}
+	return "unknown"
+}
+
+// Returns the typed outcome of the cancel request.
+func (r *CancelResponse) Outcome() CancelOutcome {
+	switch r.ResponseCode {
+	case ResponseCodeSuccess:
+		return CancelOutcomeCancelled
+	case ResponseCodeTransactionCancelled:
+		return CancelOutcomeAlreadyCancelled
+	case ResponseCodeTransactionTooOld:
+		return CancelOutcomeTooOld
+	case ResponseCodeValidationKeyRequired, ResponseCodeInvalidValidationKey:
+		return CancelOutcomeInvalidKey
+	}
+
+	m := strings.ToLower(r.HeaderMessage)
+	if strings.Contains(m, "not found") || strings.Contains(m, "does not exist") {
+		return CancelOutcomeNotFound
+	}
+
+	return CancelOutcomeUnknown
+}
+
+// Returned by Cancel, together with the response, when the transaction was not cancelled.
+// Response is nil when the cancellation was refused locally, see SuretaxClient.CancelWindow.
+type CancelError struct {
+	Outcome       CancelOutcome
+	ResponseCode  string
+	HeaderMessage string
+
+	Response *CancelResponse
+}
+
+func (e *CancelError) Error() string {
+	return fmt.Sprintf("SureTax cancel %s (%s): %s", e.Outcome, e.ResponseCode, e.HeaderMessage)
+}
+
+// Returns a *CancelError unless the transaction was cancelled.
+func (r *CancelResponse) Err() error {
+	o := r.Outcome()
+	if o == CancelOutcomeCancelled {
+		return nil
+	}
+	return &CancelError{Outcome: o, ResponseCode: r.ResponseCode, HeaderMessage: r.HeaderMessage, Response: r}
+}
+
+// SureTax only accepts cancellations of transactions up to 60 days old.
+const DefaultCancelWindow = 60 * 24 * time.Hour
+
+// Checks the age of the transaction recorded in the tracking index against the cancellation window.
+// Returns a *CancelError with CancelOutcomeTooOld for stale transactions unless allowStale is set,
+// in which case a warning is logged and the request proceeds.
+func checkCancelWindow(index TrackingIndex, window time.Duration, allowStale bool, req *CancelRequest) error {
+
+	ids, ok := index.(TransIdIndex)
+	if !ok {
+		return nil
+	}
+
+	transId, err := strconv.Atoi(req.TransId)
+	if err != nil {
+		return nil
+	}
+
+	entry, found, err := ids.LookupTransId(transId)
+	if err != nil || !found {
+		return err
+	}
+
+	if window <= 0 {
+		window = DefaultCancelWindow
+	}
+
+	age := time.Since(entry.Time)
+	if age <= window {
+		return nil
+	}
+
+	if allowStale {
+		logger.Warn("Cancelling a transaction SureTax will likely reject as too old", "TransId", transId, "Age", age.Round(time.Hour))
+		return nil
+	}
+
+	return &CancelError{
+		Outcome:       CancelOutcomeTooOld,
+		ResponseCode:  "1510",
+		HeaderMessage: fmt.Sprintf("Transaction was recorded %v ago, outside the %v cancellation window", age.Round(time.Hour), window),
+	}
+}
@@ -0,0 +1,82 @@
+package suretax
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_CancelResponse_Outcome(t *testing.T) {
+
+	cases := map[string]CancelOutcome{
+		"9999": CancelOutcomeCancelled,
+		"9410": CancelOutcomeAlreadyCancelled,
+		"1510": CancelOutcomeTooOld,
+		"1151": CancelOutcomeInvalidKey,
+		"1100": CancelOutcomeUnknown,
+	}
+
+	for code, outcome := range cases {
+		r := &CancelResponse{ResponseCode: code}
+		if r.Outcome() != outcome {
+			t.Fatalf("Expected outcome %v for %v but got %v", outcome, code, r.Outcome())
+		}
+	}
+
+	r := &CancelResponse{ResponseCode: "1520", HeaderMessage: "Failure - Transaction ID not found"}
+	if r.Outcome() != CancelOutcomeNotFound {
+		t.Fatalf("Expected outcome %v but got %v", CancelOutcomeNotFound, r.Outcome())
+	}
+}
+
+func Test_Cancel_error(t *testing.T) {
+
+	SetHttpClient(&fakeHttpClient{bodies: []string{envelope(`{"ResponseCode":"1510","HeaderMessage":"Failure - Transaction is more than 60 days old.","Successful":"N","TransId":616039832}`)}})
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{}
+
+	res, err := cli.Cancel(&CancelRequest{TransId: "616039832"})
+
+	var cerr *CancelError
+	if !errors.As(err, &cerr) || cerr.Outcome != CancelOutcomeTooOld {
+		t.Fatalf("Expected too old CancelError but got %v", err)
+	}
+
+	if res == nil || res.TransId != 616039832 {
+		t.Fatal("Expected the response to be returned with the error")
+	}
+}
+
+func Test_Cancel_window(t *testing.T) {
+
+	fake := &fakeHttpClient{bodies: []string{envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	index := NewMemoryTrackingIndex()
+	index.Put(TrackingEntry{ClientTracking: "old", TransId: 1, Time: time.Now().Add(-61 * 24 * time.Hour)})
+
+	cli := &SuretaxClient{TrackingIndex: index}
+
+	_, err := cli.Cancel(&CancelRequest{TransId: "1"})
+
+	var cerr *CancelError
+	if !errors.As(err, &cerr) || cerr.Outcome != CancelOutcomeTooOld {
+		t.Fatalf("Expected too old CancelError but got %v", err)
+	}
+
+	if len(fake.requests) != 0 {
+		t.Fatal("Expected stale cancellation to be refused without calling SureTax")
+	}
+
+	cli.AllowStaleCancel = true
+
+	if _, err := cli.Cancel(&CancelRequest{TransId: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.requests) != 1 {
+		t.Fatal("Expected stale cancellation to be sent when allowed")
+	}
+}
@@ -0,0 +1,248 @@
+package suretax
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Final state of a single cancellation attempted by a CancelRunner.
+type CancelResult struct {
+	TransId string
+
+	Outcome      CancelOutcome
+	ResponseCode string
+
+	// SureTax header message, or the error returned by the last attempt.
+	Message string
+
+	Attempts int
+
+	// Set when the last attempt failed with a transient error, so a resumed run tries again.
+	Retryable bool
+}
+
+// Reports whether the transaction is cancelled, including transactions cancelled earlier.
+func (r CancelResult) Cancelled() bool {
+	return r.Outcome == CancelOutcomeCancelled || r.Outcome == CancelOutcomeAlreadyCancelled
+}
+
+// Records cancellation results so an interrupted run can be resumed.
+type CancelCheckpoint interface {
+	// Returns the results recorded so far, keyed by TransId.
+	Load() (map[string]CancelResult, error)
+
+	Save(result CancelResult) error
+}
+
+// CancelCheckpoint appending one JSON line per result to a file.
+type FileCancelCheckpoint struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (f *FileCancelCheckpoint) Load() (map[string]CancelResult, error) {
+	results := make(map[string]CancelResult)
+
+	file, err := os.Open(f.Path)
+	if os.IsNotExist(err) {
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	s := bufio.NewScanner(file)
+	for s.Scan() {
+		var r CancelResult
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			// a partially written last line from an interrupted run
+			continue
+		}
+		results[r.TransId] = r
+	}
+
+	return results, s.Err()
+}
+
+func (f *FileCancelCheckpoint) Save(result CancelResult) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(result)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Outcome of a CancelRunner run, including results restored from the checkpoint.
+type CancelReport struct {
+	Cancelled []CancelResult
+	Failed    []CancelResult
+}
+
+// Writes the report as CSV with one row per transaction.
+func (r *CancelReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"TransId", "Status", "Outcome", "ResponseCode", "Message", "Attempts"})
+
+	write := func(status string, results []CancelResult) {
+		for _, res := range results {
+			cw.Write([]string{res.TransId, status, res.Outcome.String(), res.ResponseCode, res.Message, strconv.Itoa(res.Attempts)})
+		}
+	}
+	write("cancelled", r.Cancelled)
+	write("failed", r.Failed)
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Cancels a list of transactions, e.g. those of a voided billing run, with rate limiting,
+// retries of transient failures and resumable progress.
+type CancelRunner struct {
+	Client *SuretaxClient
+
+	// Template for every cancel request: ClientNumber, ValidationKey and optional ClientTracking.
+	Request CancelRequest
+
+	// Optional limiter applied before every attempt.
+	Limiter RateLimiter
+
+	// Number of retries of transient failures per transaction.
+	MaxRetries int
+
+	// Delay before the first retry, doubled for each following one. Defaults to one second.
+	Backoff time.Duration
+
+	// Optional checkpoint. Transactions with a recorded non-retryable result are skipped.
+	Checkpoint CancelCheckpoint
+}
+
+// Cancels transIds in order. Returns early with the partial report if ctx is cancelled.
+func (r *CancelRunner) Run(ctx context.Context, transIds []string) (*CancelReport, error) {
+
+	done := map[string]CancelResult{}
+	if r.Checkpoint != nil {
+		var err error
+		if done, err = r.Checkpoint.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &CancelReport{}
+	add := func(res CancelResult) {
+		if res.Cancelled() {
+			report.Cancelled = append(report.Cancelled, res)
+		} else {
+			report.Failed = append(report.Failed, res)
+		}
+	}
+
+	for _, id := range transIds {
+		if prev, ok := done[id]; ok && !prev.Retryable {
+			add(prev)
+			continue
+		}
+
+		res, err := r.cancel(ctx, id)
+		if err != nil {
+			return report, err
+		}
+
+		if r.Checkpoint != nil {
+			if err := r.Checkpoint.Save(res); err != nil {
+				return report, err
+			}
+		}
+
+		add(res)
+	}
+
+	return report, nil
+}
+
+// Cancels a single transaction. Only returns an error if ctx is done.
+func (r *CancelRunner) cancel(ctx context.Context, transId string) (CancelResult, error) {
+
+	result := CancelResult{TransId: transId}
+
+	backoff := r.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for {
+		if r.Limiter != nil {
+			if err := r.Limiter.Wait(ctx); err != nil {
+				return result, err
+			}
+		}
+
+		req := r.Request
+		req.TransId = transId
+
+		result.Attempts++
+		res, err := r.Client.CancelContext(ctx, &req)
+
+		if res != nil {
+			result.Outcome = res.Outcome()
+			result.ResponseCode = res.ResponseCode
+			result.Message = res.HeaderMessage
+			result.Retryable = false
+			return result, nil
+		}
+
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		// refused without calling SureTax, e.g. outside the cancellation window
+		var cerr *CancelError
+		if errors.As(err, &cerr) {
+			result.Outcome = cerr.Outcome
+			result.ResponseCode = cerr.ResponseCode
+			result.Message = cerr.HeaderMessage
+			result.Retryable = false
+			return result, nil
+		}
+
+		result.Message = err.Error()
+		result.Retryable = IsTransient(err)
+
+		if !result.Retryable || result.Attempts > r.MaxRetries {
+			return result, nil
+		}
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return result, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,92 @@
+package suretax
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_CancelRunner(t *testing.T) {
+
+	attempts := map[string]int{}
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		var inner string
+		req := &CancelRequest{}
+		decodeWrapped(t, r, &inner, req)
+		attempts[req.TransId]++
+
+		switch req.TransId {
+		case "1":
+			if attempts["1"] == 1 {
+				return &http.Response{StatusCode: 503, Status: "503 Service Unavailable", Body: http.NoBody}, nil
+			}
+			return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)), nil
+		case "2":
+			return okResponse(envelope(`{"ResponseCode":"9410","Successful":"N","TransId":2}`)), nil
+		}
+		return okResponse(envelope(`{"ResponseCode":"1510","Successful":"N","TransId":3}`)), nil
+	}))
+	defer SetHttpClient(nil)
+
+	checkpoint := &FileCancelCheckpoint{Path: filepath.Join(t.TempDir(), "cancel.ndjson")}
+
+	runner := &CancelRunner{
+		Client:     &SuretaxClient{},
+		Limiter:    NewIntervalLimiter(time.Millisecond),
+		MaxRetries: 2,
+		Backoff:    time.Millisecond,
+		Checkpoint: checkpoint,
+	}
+
+	report, err := runner.Run(context.Background(), []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Cancelled) != 2 || len(report.Failed) != 1 {
+		t.Fatalf("Expected 2 cancelled and 1 failed but got %+v", report)
+	}
+
+	if report.Failed[0].Outcome != CancelOutcomeTooOld {
+		t.Fatalf("Expected outcome %v but got %v", CancelOutcomeTooOld, report.Failed[0].Outcome)
+	}
+
+	if attempts["1"] != 2 {
+		t.Fatalf("Expected transient failure to be retried but got %v attempts", attempts["1"])
+	}
+
+	// resumed run uses the checkpoint
+	report, err = runner.Run(context.Background(), []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts["1"] != 2 || attempts["2"] != 1 || len(report.Cancelled) != 2 {
+		t.Fatal("Expected checkpointed transactions to be skipped")
+	}
+}
+
+func Test_CancelRunner_refused(t *testing.T) {
+
+	fake := &fakeHttpClient{}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	index := NewMemoryTrackingIndex()
+	index.Put(TrackingEntry{ClientTracking: "old", TransId: 1, Time: time.Now().Add(-61 * 24 * time.Hour)})
+
+	runner := &CancelRunner{Client: &SuretaxClient{TrackingIndex: index}}
+	report, err := runner.Run(context.Background(), []string{"1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Failed) != 1 || report.Failed[0].Outcome != CancelOutcomeTooOld || report.Failed[0].ResponseCode != "1510" || report.Failed[0].Retryable {
+		t.Fatalf("Expected the refused cancellation to be too old but got %+v", report.Failed)
+	}
+	if len(fake.requests) != 0 {
+		t.Fatal("Expected no call to SureTax")
+	}
+}
@@ -0,0 +1,162 @@
+package suretax
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var updateCassettes = flag.Bool("update-cassettes", false, "rewrite the expectations of testdata/cassettes")
+
+// Recorded SureTax interaction replayed by Test_Cassettes.
+type cassette struct {
+	Description string `json:"description"`
+
+	// "send" or "cancel"
+	Operation string          `json:"operation"`
+	Request   json.RawMessage `json:"request"`
+
+	Response struct {
+		Status int    `json:"status"`
+		Body   string `json:"body"`
+	} `json:"response"`
+
+	Expect *cassetteExpectation `json:"expect,omitempty"`
+}
+
+// Observable behavior of the public API for a cassette. The digests cover the exact request body sent
+// and the JSON of the returned response, so any change of serialization or parsing is caught.
+type cassetteExpectation struct {
+	Error        string `json:"error,omitempty"`
+	ResponseCode string `json:"responseCode,omitempty"`
+	TransId      int    `json:"transId,omitempty"`
+	TotalTax     string `json:"totalTax,omitempty"`
+	Groups       int    `json:"groups"`
+	Taxes        int    `json:"taxes"`
+	ItemMessages int    `json:"itemMessages"`
+
+	RequestDigest string `json:"requestDigest"`
+	ResultDigest  string `json:"resultDigest"`
+}
+
+func digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func replayCassette(t *testing.T, c *cassette) *cassetteExpectation {
+	fake := &fakeHttpClient{status: c.Response.Status, bodies: []string{c.Response.Body}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{}
+	exp := &cassetteExpectation{}
+
+	var result interface{}
+	var err error
+	switch c.Operation {
+	case "send":
+		req := &Request{}
+		if err := json.Unmarshal(c.Request, req); err != nil {
+			t.Fatal(err)
+		}
+		var res *Response
+		if res, err = cli.Send(req); res != nil {
+			groups, _ := res.Groups()
+			exp.ResponseCode, exp.TransId, exp.TotalTax = res.ResponseCode, res.TransId, res.TotalTax
+			exp.Groups, exp.ItemMessages = len(groups), len(res.ItemMessages)
+			for _, g := range groups {
+				exp.Taxes += len(g.TaxList)
+			}
+			result = res
+		}
+	case "cancel":
+		req := &CancelRequest{}
+		if err := json.Unmarshal(c.Request, req); err != nil {
+			t.Fatal(err)
+		}
+		var res *CancelResponse
+		if res, err = cli.Cancel(req); res != nil {
+			exp.ResponseCode, exp.TransId = res.ResponseCode, res.TransId
+			result = res
+		}
+	default:
+		t.Fatalf("Unknown operation %v", c.Operation)
+	}
+
+	if err != nil {
+		exp.Error = err.Error()
+	}
+	if len(fake.requests) != 1 {
+		t.Fatalf("Expected one request but got %v", len(fake.requests))
+	}
+	body, err := requestBody(fake.requests[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp.RequestDigest = digest([]byte(body))
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp.ResultDigest = digest(out)
+
+	return exp
+}
+
+// Replays the cassette library against Send and Cancel. Run with -update-cassettes to record
+// the current behavior as expected after an intended change, and review the diff.
+func Test_Cassettes(t *testing.T) {
+
+	files, err := filepath.Glob(filepath.Join("testdata", "cassettes", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("Expected cassettes in testdata/cassettes")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := &cassette{}
+			if err := json.Unmarshal(data, c); err != nil {
+				t.Fatal(err)
+			}
+
+			got := replayCassette(t, c)
+
+			if *updateCassettes {
+				c.Expect = got
+				buf := new(bytes.Buffer)
+				enc := json.NewEncoder(buf)
+				enc.SetEscapeHTML(false)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(c); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if c.Expect == nil {
+				t.Fatal("Expected a recorded expectation, run with -update-cassettes")
+			}
+			if !reflect.DeepEqual(got, c.Expect) {
+				t.Fatalf("Expected %+v but got %+v", *c.Expect, *got)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Fault rates of a ChaosClient. Rates are probabilities between 0 and 1, rolled independently per request.
+type ChaosConfig struct {
+	// Delay added before the request is forwarded.
+	LatencyRate float64
+	Latency     time.Duration
+
+	// The request is forwarded, but its response is dropped and a timeout error returned,
+	// like a client timeout after SureTax already processed the request.
+	TimeoutRate float64
+
+	// A 500, 502 or 503 response is returned without forwarding the request.
+	ServerErrorRate float64
+
+	// The response envelope is truncated.
+	MalformedRate float64
+
+	// The first group of a successful response is replaced with a 9001 item error.
+	ItemErrorRate float64
+
+	// Seed of the fault generator. Zero seeds from the current time.
+	Seed int64
+}
+
+// HttpClient wrapping another client and injecting SureTax-like failures,
+// for testing retry and idempotency handling. Safe for concurrent use.
+type ChaosClient struct {
+	next   HttpClient
+	config ChaosConfig
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func NewChaosClient(next HttpClient, config ChaosConfig) *ChaosClient {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ChaosClient{next: next, config: config, rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (c *ChaosClient) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rnd.Float64() < rate
+}
+
+func (c *ChaosClient) Do(req *http.Request) (*http.Response, error) {
+
+	if c.roll(c.config.ServerErrorRate) {
+		c.mu.Lock()
+		status := []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}[c.rnd.Intn(3)]
+		c.mu.Unlock()
+		return chaosResponse(req, status, nil), nil
+	}
+
+	if c.roll(c.config.LatencyRate) {
+		t := time.NewTimer(c.config.Latency)
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	resp, err := c.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.roll(c.config.TimeoutRate) {
+		resp.Body.Close()
+		return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: chaosTimeout{}}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	malformed := c.roll(c.config.MalformedRate)
+	itemError := c.roll(c.config.ItemErrorRate)
+	if !malformed && !itemError {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if itemError {
+		body = injectItemError(body)
+	}
+	if malformed {
+		body = body[:len(body)/2]
+	}
+
+	return chaosResponse(req, resp.StatusCode, body), nil
+}
+
+func chaosResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// Replaces the first group of a response envelope with a 9001 item error, its tax subtracted from TotalTax
+// so the response still passes Verify. Other payloads are returned unchanged.
+func injectItemError(body []byte) []byte {
+	var env ResponseWrapper
+	if err := json.Unmarshal(body, &env); err != nil {
+		return body
+	}
+
+	var inner map[string]interface{}
+	if err := json.Unmarshal([]byte(env.D), &inner); err != nil {
+		return body
+	}
+
+	groups, _ := inner["GroupList"].([]interface{})
+	if len(groups) == 0 {
+		return body
+	}
+
+	lineNumber := ""
+	if g, ok := groups[0].(map[string]interface{}); ok {
+		lineNumber, _ = g["LineNumber"].(string)
+		subtractGroupTax(inner, g)
+	}
+
+	messages, _ := inner["ItemMessages"].([]interface{})
+	inner["ItemMessages"] = append(messages, map[string]interface{}{
+		"LineNumber":   lineNumber,
+		"Message":      "Injected item error",
+		"ResponseCode": "9400",
+	})
+	inner["GroupList"] = groups[1:]
+	inner["ResponseCode"] = "9001"
+	inner["HeaderMessage"] = "Success with Item errors"
+
+	innerBytes, err := json.Marshal(inner)
+	if err != nil {
+		return body
+	}
+
+	out, err := json.Marshal(ResponseWrapper{D: string(innerBytes)})
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// Subtracts the TaxAmounts of the decoded group g from the TotalTax of the decoded response inner,
+// keeping the decimal places of TotalTax. Leaves TotalTax unchanged if an amount can't be parsed.
+func subtractGroupTax(inner, g map[string]interface{}) {
+	totalTax, _ := inner["TotalTax"].(string)
+	total, err := parseAmount(totalTax)
+	if err != nil {
+		return
+	}
+
+	taxes, _ := g["TaxList"].([]interface{})
+	for _, t := range taxes {
+		tax, _ := t.(map[string]interface{})
+		amount, _ := tax["TaxAmount"].(string)
+		a, err := parseAmount(amount)
+		if err != nil {
+			return
+		}
+		total.Sub(total, a)
+	}
+
+	inner["TotalTax"] = formatAmount(total, amountDecimals(totalTax))
+}
+
+type chaosTimeout struct{}
+
+func (chaosTimeout) Error() string   { return "chaos: injected timeout" }
+func (chaosTimeout) Timeout() bool   { return true }
+func (chaosTimeout) Temporary() bool { return true }
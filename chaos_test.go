@@ -0,0 +1,46 @@
+package suretax
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_ChaosClient(t *testing.T) {
+
+	ok := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		resp := getTestResponse()
+		resp.StatusCode = http.StatusOK
+		return resp, nil
+	})
+
+	cli := &SuretaxClient{}
+
+	SetHttpClient(NewChaosClient(ok, ChaosConfig{ServerErrorRate: 1}))
+	if _, err := cli.Send(getTestRequest()); !IsTransient(err) {
+		t.Fatalf("Expected transient server error but got %v", err)
+	}
+
+	SetHttpClient(NewChaosClient(ok, ChaosConfig{TimeoutRate: 1}))
+	if _, err := cli.Send(getTestRequest()); !IsTransient(err) {
+		t.Fatalf("Expected transient timeout but got %v", err)
+	}
+
+	SetHttpClient(NewChaosClient(ok, ChaosConfig{MalformedRate: 1}))
+	if _, err := cli.Send(getTestRequest()); err == nil {
+		t.Fatal("Expected malformed envelope to fail")
+	}
+
+	SetHttpClient(NewChaosClient(ok, ChaosConfig{ItemErrorRate: 1}))
+	res, err := cli.Send(getTestRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ResponseCode != "9001" || len(res.GroupList) != 0 || len(res.ItemMessages) != 2 {
+		t.Fatalf("Expected injected item error but got %+v", res)
+	}
+	if err := res.Verify(); err != nil {
+		t.Fatalf("Expected the dropped group's tax subtracted from TotalTax but got %v", err)
+	}
+
+	SetHttpClient(nil)
+}
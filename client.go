@@ -1,11 +1,14 @@
 package suretax
 
 import (
+	"context"
 	"net/http"
 	"bytes"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 	)
@@ -16,7 +19,10 @@ type HttpClient interface {
 
 var httpClientOverride HttpClient = nil
 
-// Sets the package's http client.
+// Sets the http client of every SuretaxClient that has none of its own, see WithHttpClient.
+//
+// Deprecated: a package-wide client can't give two clients different transports or proxies.
+// Use WithHttpClient or SuretaxClient.SetHttpClient instead.
 func SetHttpClient(client HttpClient) {
 	httpClientOverride = client
 }
@@ -28,41 +34,538 @@ type SuretaxClient struct {
 	// SureTax cancel post request url.
 	CancelUrl string
 
-	mu         sync.Mutex
+	// Account set on the requests that have no ClientNumber, ValidationKey or BusinessUnit of their own.
+	Credentials Credentials
+
+	// Optional source of the credentials consulted on every call instead of Credentials, see CachedCredentials.
+	CredentialProvider CredentialProvider
+
+	// Handling of InvoiceNumber/CustomerNumber values that are not alphanumeric.
+	// Defaults to IdentifierPassThrough. Sanitized values are reported in Response.IdentifierChanges.
+	IdentifierMode IdentifierMode
+
+	// When set, requests with an empty ClientTracking are assigned a generated ULID
+	// so every transaction can be traced. The value is returned in Response.ClientTracking.
+	GenerateClientTracking bool
+
+	// When set, requests with an empty STAN are assigned a value generated by NewStan.
+	// The value is returned in Response.STAN.
+	GenerateStan bool
+
+	// Optional store used to reject requests reusing a STAN within StanWindow. A STAN is recorded once
+	// SureTax has accepted its request, so a request that failed or was declined may be sent again with it.
+	StanStore DedupeStore
+
+	// Period during which a STAN may not be reused. Defaults to DefaultStanWindow.
+	StanWindow time.Duration
+
+	// Optional store of the responses of requests sent with a STAN. A request resent with the same
+	// STAN and body within IdempotencyWindow gets the stored response, marked Replayed, without a call
+	// to SureTax, or ErrOutcomeUnknown if the first call failed without a response, e.g. on a timeout.
+	// Declined requests aren't stored. See WithIdempotency.
+	Idempotency IdempotencyStore
+
+	// Period during which responses are kept in Idempotency. Defaults to DefaultStanWindow.
+	IdempotencyWindow time.Duration
+
+	// Optional auditor receiving a tamper-evident record of every Send and Cancel response.
+	Auditor *Auditor
+
+	// Treatment of phone numbers, street addresses and customer numbers in logged payloads.
+	// Defaults to PrivacyOff.
+	Privacy PrivacyMode
+
+	// Secret key of the hashes logged with PrivacyHash. Keep it the same across processes for their
+	// logs to correlate. When empty, a random key is generated per client, so hashes correlate only
+	// within the lifetime of the client.
+	PrivacyKey []byte
+
+	// Optional index mapping ClientTracking values to TransIds, populated on every successful Send.
+	// Required by CancelByTracking.
+	TrackingIndex TrackingIndex
+
+	// Maximum age of a transaction that may be cancelled. Defaults to DefaultCancelWindow.
+	// Checked only when TrackingIndex implements TransIdIndex and knows the transaction.
+	CancelWindow time.Duration
+
+	// When set, cancellations outside CancelWindow are sent anyway with a warning instead of being refused.
+	AllowStaleCancel bool
+
+	// Optional ledger recording the lifecycle of every transaction sent through the client.
+	Ledger *Ledger
+
+	// Limits enforced when reading and parsing responses. The zero value enforces none,
+	// see DefaultParseLimits.
+	Limits ParseLimits
+
+	// Number of goroutines decoding the GroupList of large responses (thousands of groups).
+	// 0 or 1 decodes on the calling goroutine.
+	DecodeWorkers int
+
+	// When set, responses are returned with only the header fields decoded (ResponseCode, TransId,
+	// TotalTax, ItemMessages...). The GroupList is decoded on the first call to Response.Groups,
+	// or one group at a time by Response.EachGroup.
+	LazyGroups bool
+
+	// Optional hook receiving payload sizes, timings and allocation estimates of every Send,
+	// including the sub-requests of SendIsolating. See StatsCollector for aggregating them.
+	StatsHook func(CallStats)
+
+	// Optional writer receiving one JSON line (an ItemResult) per request item as soon as each
+	// response arrives, so consumers can process results before a batch completes.
+	// Writes are serialized; a failing writer is logged and doesn't fail the call.
+	ResultWriter io.Writer
+
+	// Request format version accepted by the endpoint. Fields introduced in later versions are
+	// left out of requests, so the zero value (APIVersion1) sends the original request shape.
+	APIVersion APIVersion
+
+	// When set, requests with items failing ValidateSitus are refused with the joined
+	// *ValidationErrors instead of being sent.
+	CheckSitus bool
+
+	// When set, an empty TotalRevenue is computed from the item revenues and requests whose
+	// TotalRevenue disagrees with them are refused, see ReconcileTotalRevenue.
+	ComputeTotalRevenue bool
+
+	// UnitType codes of the utility units of the account, see SetUsage and Lint.
+	UtilityUnits UtilityUnits
+
+	// Optional cache of verified addresses. Items asking for verification of an address verified
+	// before are sent with VerifyAddress off. See BypassAddressCache.
+	AddressCache *AddressCache
+
+	// Optional cache reusing the taxes of identical quoted items (ReturnFileCode Q).
+	// Only the items missing from the cache are sent to SureTax.
+	QuoteCache *QuoteCache
+
+	// Optional limit on the calls in flight and the rate they are sent at, shared by all calls of the client.
+	// Waiting calls are served by priority, see WithPriority.
+	Limiter *PriorityLimiter
+
+	// Optional throttle every HTTP request to SureTax waits for, e.g. a *rate.Limiter. See WithRateLimiter.
+	RateLimiter RateLimiter
+
+	// Optional circuit breaker failing calls fast while SureTax is unavailable.
+	Breaker *CircuitBreaker
+
+	// Optional primary and secondary SureTax sites, replacing Url and CancelUrl.
+	Failover *Failover
+
+	// Optional call quotas per ClientNumber. Quotes are refused over quota, finals are always sent.
+	Quotas *QuotaEnforcer
+
+	// Optional meter counting the billable calls of the client.
+	Usage *UsageMeter
+
+	// Optional monitor recording the latency and outcome of every call to SureTax.
+	Monitor *SLOMonitor
+
+	// Optional metrics observing every Send and Cancel, e.g. the Prometheus collector of package suretaxprom.
+	Metrics Metrics
+
+	// Optional secondary engine calculating the request when SureTax fails as selected by FallbackPolicy.
+	Fallback FallbackEngine
+
+	// Selects the errors Fallback is engaged for. Defaults to FallbackOnUnavailable.
+	FallbackPolicy FallbackPolicy
+
+	// Optional estimate returned by Send when SureTax can't be reached (see IsTransient),
+	// so checkout can proceed. The response is marked Estimated.
+	Estimator EstimateProvider
+
+	// Receives the requests answered with an estimate or by Fallback, for the authoritative calculation
+	// to be sent once SureTax is back. Quotes are not queued, and neither are final requests SureTax may
+	// have recorded, e.g. on a timeout: Send returns their error instead of an estimate.
+	Outbox Outbox
+
+	// Optional discovery document overriding Url and CancelUrl once loaded.
+	Discovery *Discovery
+
+	// Optional signer of every outbound payload, see HMACSigner.
+	Signer RequestSigner
+
+	// Maximum number of items per request sent by SendBatch. Defaults to DefaultBatchSize.
+	BatchSize int
+
+	// Number of SendBatch requests sent at the same time. 0 or 1 sends them one after the other.
+	BatchConcurrency int
+
+	resultMu sync.Mutex
+	mu       sync.Mutex
+
+	// set with WithHttpClient or SetHttpClient
+	transport HttpClient
+
+	// built on first use when there is no transport
 	httpClient HttpClient
+
+	// generated on first use when PrivacyKey is empty
+	privacyKey []byte
 }
 
 func (c *SuretaxClient) Send(req *Request) (*Response, error) {
+	return c.SendContext(context.Background(), req)
+}
+
+// Same as Send, with the HTTP request bound to ctx.
+func (c *SuretaxClient) SendContext(ctx context.Context, req *Request) (*Response, error) {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	creds.Apply(req)
+
+	if c.Quotas != nil {
+		if err := c.checkQuota(req); err != nil {
+			return nil, err
+		}
+	}
+
+	var res *Response
+	start := time.Now()
+	if c.QuoteCache != nil && req.ReturnFileCode == "Q" {
+		res, err = c.sendQuoteCached(ctx, req)
+	} else {
+		res, err = c.send(ctx, req, &Response{})
+	}
+	c.credentialsRejected(err)
+	if c.Monitor != nil {
+		c.Monitor.Record(time.Since(start), err)
+	}
+	if c.Metrics != nil {
+		c.Metrics.ObserveCall(sendMetrics(req, res, err, time.Since(start)))
+	}
+
+	if err != nil && c.Fallback != nil && c.FallbackPolicy.engage(err) {
+		if fres, ok := c.sendFallback(ctx, req, err); ok {
+			return fres, nil
+		}
+	}
+
+	if c.Estimator != nil {
+		return c.estimateOnFailure(req, res, err)
+	}
+	return res, err
+}
+
+// Same as Send, decoding the response into res instead of allocating a new one.
+// res is reset first, keeping the capacity of its slices, so a long-running process reusing
+// one Response per worker stops allocating GroupList, TaxList and ItemMessages once they have grown.
+// Groups and taxes obtained from res before the call are overwritten.
+func (c *SuretaxClient) SendInto(req *Request, res *Response) error {
+	return c.SendIntoContext(context.Background(), req, res)
+}
+
+// Same as SendInto, with the HTTP request bound to ctx.
+func (c *SuretaxClient) SendIntoContext(ctx context.Context, req *Request, res *Response) error {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	creds.Apply(req)
+
+	res.Reset()
+	_, err = c.send(ctx, req, res)
+	c.credentialsRejected(err)
+	return err
+}
+
+func (c *SuretaxClient) send(ctx context.Context, req *Request, res *Response) (*Response, error) {
+
+	if err := AssignLineNumbers(req); err != nil {
+		return nil, err
+	}
+
+	if c.CheckSitus {
+		if err := situsError(req); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.ComputeTotalRevenue {
+		if err := ReconcileTotalRevenue(req); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := NormalizeIdentifiers(req, c.IdentifierMode)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range changes {
+		original, sanitized := ch.Original, ch.Sanitized
+		if ch.Field == "CustomerNumber" {
+			key := c.privacySecret()
+			original, sanitized = minimize(original, c.Privacy, key), minimize(sanitized, c.Privacy, key)
+		}
+		logger.Info("Sanitized identifier", "Field", ch.Field, "LineNumber", ch.LineNumber, "Original", original, "Sanitized", sanitized)
+	}
+
+	if c.GenerateClientTracking && req.ClientTracking == "" {
+		req.ClientTracking = newUlid()
+	}
+
+	if c.GenerateStan && req.STAN == "" && !isQuoteCall(ctx) {
+		req.STAN = NewStan()
+	}
+
+	if c.Ledger != nil {
+		if err := c.Ledger.CheckSend(req); err != nil {
+			return nil, err
+		}
+	}
 
 	cli := c.getClient()
 
-	r, err := c.buildRequest(req)
+	shaped, dropped := c.APIVersion.shape(req)
+	if len(dropped) > 0 {
+		logger.Warn("Fields not supported by SureTax API version left out of the request", "APIVersion", c.APIVersion, "Fields", dropped)
+	}
+
+	if c.AddressCache != nil && !addressCacheBypassed(ctx) {
+		shaped = c.AddressCache.skipVerified(shaped)
+	}
+
+	r, err := c.buildRequest(shaped)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := cli.Do(r)
+	if err := c.sign(r); err != nil {
+		return nil, err
+	}
+
+	idempotent := c.Idempotency != nil && req.STAN != "" && !isQuoteCall(ctx)
+
+	var fingerprint, idemFingerprint string
+	if c.Auditor != nil {
+		if fingerprint, err = fingerprintBody(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if idempotent {
+		if idemFingerprint, err = idempotencyFingerprint(req); err != nil {
+			return nil, err
+		}
+		replayed, err := c.replay(req, idemFingerprint)
+		if err != nil {
+			return nil, err
+		}
+		if replayed != nil {
+			*res = *replayed
+			res.Replayed = true
+			res.IdentifierChanges = changes
+			logger.Info("Replayed SureTax response", "TransId", res.TransId, "STAN", req.STAN)
+			return res, nil
+		}
+	}
+
+	stans := c.StanStore
+	if isQuoteCall(ctx) {
+		stans = nil
+	}
+	if err := checkStan(stans, c.StanWindow, req); err != nil {
+		return nil, err
+	}
+	// the STAN is reserved to refuse concurrent reuse, and kept only once SureTax has accepted the request
+	accepted := false
+	defer func() {
+		if !accepted {
+			releaseStan(stans, req)
+		}
+	}()
+
+	cl := newCallLog()
+	failed := true
+	defer func() { cl.done(failed) }()
+
+	if cl.enabled(LevelTrace) {
+		if c.Privacy == PrivacyOff {
+			body, _ := requestBody(r)
+			cl.log(LevelTrace, "Request Data", "Payload", string(ScrubPayload([]byte(body))))
+		} else if minBytes, err := json.Marshal(MinimizeRequest(req, c.Privacy, c.privacySecret())); err == nil {
+			cl.log(LevelTrace, "Request Data (minimized)", "Payload", string(ScrubPayload(minBytes)))
+		}
+	}
+
+	release, err := c.acquire(ctx, callPriority(ctx, req), callTenant(ctx, req))
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
-	logger.Debug("Response Code:", resp.StatusCode, "Status:", resp.Status)
+	stats := CallStats{Items: len(req.ItemList), RequestBytes: r.ContentLength}
+	start := time.Now()
+
+	if idempotent {
+		if err := c.markPending(req, idemFingerprint); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := cli.Do(r.WithContext(ctx))
+	if err != nil {
+		// e.g. the breaker is open or the connection was refused, nothing reached SureTax
+		if idempotent && notSent(err) {
+			c.forget(req)
+		}
+		return nil, err
+	}
+
+	cl.log(LevelDebug, "SureTax HTTP response", "StatusCode", resp.StatusCode, "Status", resp.Status)
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("SureTax returned " + resp.Status)
+		// A 4xx is refused before processing, a 5xx may come from a gateway giving up on a recorded call.
+		if idempotent && resp.StatusCode < 500 {
+			c.forget(req)
+		}
+		return nil, &HttpError{resp.StatusCode, resp.Status}
 	}
 
-	res, err := c.parseResponse(resp)
+	bodyBytes, err := c.Limits.readBody(resp.Body)
+	release()
 	if err != nil {
 		return nil, err
 	}
+	latency := time.Since(start)
+
+	if c.Privacy == PrivacyOff {
+		cl.log(LevelTrace, "Response Data", "Payload", string(ScrubPayload(bodyBytes)))
+	}
+
+	if c.StatsHook != nil {
+		stats.ResponseBytes = int64(len(bodyBytes))
+		stats.RoundTrip = latency
+		start = time.Now()
+	}
+
+	if err := c.decodeResponseInto(bodyBytes, res); err != nil {
+		return nil, err
+	}
+
+	if c.Usage != nil {
+		key := UsageKey{r.URL.Host, UsageEndpointSend, req.ReturnFileCode, callTenant(ctx, req)}
+		c.Usage.record(key, len(req.ItemList))
+	}
+
+	if c.StatsHook != nil {
+		stats.Decode = time.Since(start)
+		stats.DecodedBytes = decodedSize(res, stats.ResponseBytes)
+		stats.Groups = len(res.GroupList)
+		c.StatsHook(stats)
+	}
+
+	if c.Privacy != PrivacyOff && cl.enabled(LevelTrace) {
+		if minBytes, err := json.Marshal(MinimizeResponse(res, c.Privacy, c.privacySecret())); err == nil {
+			cl.log(LevelTrace, "Response Data (minimized)", "Payload", string(minBytes))
+		}
+	}
+
+	if res.ClientTracking == "" {
+		res.ClientTracking = req.ClientTracking
+	}
+	if res.STAN == "" {
+		res.STAN = req.STAN
+	}
+	res.IdentifierChanges = changes
+
+	cl.log(LevelInfo, "SureTax response", "TransId", res.TransId, "ResponseCode", res.ResponseCode, "ClientTracking", res.ClientTracking, "Latency", latency)
+
+	if cl.enabled(LevelDebug) && !c.LazyGroups {
+		table := new(bytes.Buffer)
+		if err := WriteTable(table, res, TableText); err == nil {
+			cl.log(LevelDebug, "Response Taxes", "Taxes", table.String())
+		}
+	}
+
+	if c.Auditor != nil {
+		c.Auditor.recordSend(ctx, fingerprint, req, res)
+	}
+
+	if c.AddressCache != nil && !res.declined() {
+		c.AddressCache.recordVerified(shaped, res)
+	}
+
+	if c.Ledger != nil && !res.declined() {
+		if err := c.Ledger.RecordSend(req, res); err != nil {
+			logger.Error("Ledger update failed", "TransId", res.TransId, "Error", err)
+		}
+	}
+
+	if c.TrackingIndex != nil && !res.declined() && req.ReturnFileCode != "Q" && req.ClientTracking != "" {
+		entry := TrackingEntry{ClientTracking: req.ClientTracking, TransId: res.TransId, ClientNumber: req.ClientNumber, Time: time.Now()}
+		if err := c.TrackingIndex.Put(entry); err != nil {
+			logger.Error("Indexing failed", "TransId", res.TransId, "Error", err)
+		}
+	}
+
+	if c.ResultWriter != nil {
+		c.resultMu.Lock()
+		err := writeItemResults(c.ResultWriter, req, res, c.Privacy, c.privacySecret())
+		c.resultMu.Unlock()
+		if err != nil {
+			logger.Error("Writing results failed", "TransId", res.TransId, "Error", err)
+		}
+	}
+
+	if idempotent {
+		if res.declined() {
+			c.forget(req)
+		} else {
+			c.remember(req, idemFingerprint, res)
+		}
+	}
+
+	failed = res.ResponseCode != "9999"
+	accepted = !res.declined()
+
+	if res.declined() {
+		return res, res.Err()
+	}
 
 	return res, nil
 }
 
 func (c *SuretaxClient) Cancel(req *CancelRequest) (*CancelResponse, error) {
+	return c.CancelContext(context.Background(), req)
+}
+
+// Same as Cancel, with the HTTP request bound to ctx.
+func (c *SuretaxClient) CancelContext(ctx context.Context, req *CancelRequest) (*CancelResponse, error) {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	creds.ApplyCancel(req)
+
+	start := time.Now()
+	res, err := c.cancel(ctx, req)
+	c.credentialsRejected(err)
+	if c.Metrics != nil {
+		c.Metrics.ObserveCall(cancelMetrics(res, err, time.Since(start)))
+	}
+	return res, err
+}
+
+func (c *SuretaxClient) cancel(ctx context.Context, req *CancelRequest) (*CancelResponse, error) {
+
+	if c.Ledger != nil {
+		if transId, err := strconv.Atoi(req.TransId); err == nil {
+			if err := c.Ledger.CheckCancel(transId); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if c.TrackingIndex != nil {
+		if err := checkCancelWindow(c.TrackingIndex, c.CancelWindow, c.AllowStaleCancel, req); err != nil {
+			return nil, err
+		}
+	}
 
 	cli := c.getClient()
 
@@ -71,28 +574,101 @@ func (c *SuretaxClient) Cancel(req *CancelRequest) (*CancelResponse, error) {
 		return nil, err
 	}
 
-	resp, err := cli.Do(r)
+	if err := c.sign(r); err != nil {
+		return nil, err
+	}
+
+	var fingerprint string
+	if c.Auditor != nil {
+		if fingerprint, err = fingerprintBody(r); err != nil {
+			return nil, err
+		}
+	}
+
+	cl := newCallLog()
+	failed := true
+	defer func() { cl.done(failed) }()
+
+	if cl.enabled(LevelTrace) {
+		body, _ := requestBody(r)
+		cl.log(LevelTrace, "Request Data", "Payload", string(ScrubPayload([]byte(body))))
+	}
+
+	release, err := c.acquire(ctx, cancelPriority(ctx), callTenant(ctx, nil))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	start := time.Now()
+	resp, err := cli.Do(r.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Debug("Response Code:", resp.StatusCode, "Status:", resp.Status)
+	cl.log(LevelDebug, "SureTax HTTP response", "StatusCode", resp.StatusCode, "Status", resp.Status)
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("SureTax returned " + resp.Status)
+		return nil, &HttpError{resp.StatusCode, resp.Status}
+	}
+
+	bodyBytes, err := c.Limits.readBody(resp.Body)
+	release()
+	if err != nil {
+		return nil, err
 	}
+	latency := time.Since(start)
+
+	cl.log(LevelTrace, "Response Data", "Payload", string(ScrubPayload(bodyBytes)))
 
-	res, err := c.parseCancelResponse(resp)
+	res, err := c.decodeCancelResponse(bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 
+	if c.Usage != nil {
+		c.Usage.record(UsageKey{r.URL.Host, UsageEndpointCancel, "", callTenant(ctx, nil)}, 0)
+	}
+
+	cl.log(LevelInfo, "SureTax cancel response", "TransId", res.TransId, "ResponseCode", res.ResponseCode, "ClientTracking", req.ClientTracking, "Latency", latency)
+
+	if c.Auditor != nil {
+		c.Auditor.recordCancel(ctx, fingerprint, req, res)
+	}
+
+	if c.Ledger != nil {
+		if err := c.Ledger.RecordCancel(res); err != nil {
+			logger.Error("Ledger update failed", "TransId", res.TransId, "Error", err)
+		}
+	}
+
+	failed = res.Successful != "Y"
+
+	if err := res.Err(); err != nil {
+		return res, err
+	}
+
 	return res, nil
 }
 
 func (c *SuretaxClient) getClient() HttpClient {
+	cli := c.baseClient()
+	if c.Failover != nil {
+		cli = failoverClient{c.Failover, cli}
+	}
+	if c.Breaker != nil {
+		cli = breakerClient{c.Breaker, cli}
+	}
+	return cli
+}
+
+func (c *SuretaxClient) baseClient() HttpClient {
+
+	if c.transport != nil {
+		return c.transport
+	}
 
 	if httpClientOverride != nil {
 		return httpClientOverride
@@ -116,22 +692,23 @@ func (c *SuretaxClient) getClient() HttpClient {
 }
 
 func (c *SuretaxClient) buildRequest(req *Request) (*http.Request, error) {
-	reqBytes, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
+	return newPost(c.postUrl(), "request", req)
+}
 
-	rw := requestWrapper{string(reqBytes)}
-	reqWrapperBytes, err := json.Marshal(rw)
-	if err != nil {
+func (c *SuretaxClient) buildCancelRequest(req *CancelRequest) (*http.Request, error) {
+	return newPost(c.cancelPostUrl(), "requestCancel", req)
+}
+
+// Returns a POST of v wrapped in an object under key, as SureTax endpoints expect.
+func newPost(url, key string, v interface{}) (*http.Request, error) {
+	buf := new(bytes.Buffer)
+	if err := writeWrapped(buf, key, v); err != nil {
 		return nil, err
 	}
 
-	logger.Debug("Request Data: ", string(reqWrapperBytes))
-
-	reader := bytes.NewReader(reqWrapperBytes)
+	reader := bytes.NewReader(buf.Bytes())
 
-	r, err := http.NewRequest("POST", c.Url, reader)
+	r, err := http.NewRequest("POST", url, reader)
 	if err != nil {
 		return nil, err
 	}
@@ -141,72 +718,83 @@ func (c *SuretaxClient) buildRequest(req *Request) (*http.Request, error) {
 	return r, nil
 }
 
-func (c *SuretaxClient) buildCancelRequest(req *CancelRequest) (*http.Request, error) {
-	reqBytes, err := json.Marshal(req)
+// Returns the request body as a string without consuming it.
+func requestBody(r *http.Request) (string, error) {
+	body, err := r.GetBody()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	defer body.Close()
 
-	rw := cancelRequestWrapper{string(reqBytes)}
-	reqWrapperBytes, err := json.Marshal(rw)
-	if err != nil {
-		return nil, err
-	}
-
-	logger.Debug("Request Data: ", string(reqWrapperBytes))
+	b, err := ioutil.ReadAll(body)
+	return string(b), err
+}
 
-	reader := bytes.NewReader(reqWrapperBytes)
+func (c *SuretaxClient) parseResponse(resp *http.Response) (*Response, error) {
 
-	r, err := http.NewRequest("POST", c.CancelUrl, reader)
+	bodyBytes, err := c.Limits.readBody(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	r.Header.Add("Content-Type", "application/json")
-
-	return r, nil
+	return c.decodeResponse(bodyBytes)
 }
 
-func (c *SuretaxClient) parseResponse(resp *http.Response) (*Response, error) {
+func (c *SuretaxClient) decodeResponse(bodyBytes []byte) (*Response, error) {
 
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
+	res := &Response{}
+	if err := c.decodeResponseInto(bodyBytes, res); err != nil {
 		return nil, err
 	}
 
-	logger.Debug("Response Data: ", string(bodyBytes))
+	return res, nil
+}
+
+func (c *SuretaxClient) decodeResponseInto(bodyBytes []byte, res *Response) error {
 
 	respw := ResponseWrapper{}
 	if err := json.Unmarshal(bodyBytes, &respw); err != nil {
-		return nil, fmt.Errorf("Response Wrapper Unmarshal Failed. Error: %v", err)
+		return fmt.Errorf("Response Wrapper Unmarshal Failed. Error: %v", err)
 	}
 
-	res := &Response{}
-	if err := json.Unmarshal([]byte(respw.D), res); err != nil {
-		return nil, fmt.Errorf("Response Unmarshal Failed. Error: %v", err)
+	if err := c.Limits.check(respw.D); err != nil {
+		return err
 	}
 
-	return res, nil
+	if err := decodeResponseJSON(respw.D, res, decodeOptions{c.DecodeWorkers, c.LazyGroups}); err != nil {
+		return fmt.Errorf("Response Unmarshal Failed. Error: %v", err)
+	}
+	normalizeResponse(res)
+
+	return nil
 }
 
 func (c *SuretaxClient) parseCancelResponse(resp *http.Response) (*CancelResponse, error) {
 
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	bodyBytes, err := c.Limits.readBody(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Debug("Response Data: ", string(bodyBytes))
+	return c.decodeCancelResponse(bodyBytes)
+}
+
+func (c *SuretaxClient) decodeCancelResponse(bodyBytes []byte) (*CancelResponse, error) {
 
 	respw := ResponseWrapper{}
 	if err := json.Unmarshal(bodyBytes, &respw); err != nil {
 		return nil, fmt.Errorf("Response Wrapper Unmarshal Failed. Error: %v", err)
 	}
 
+	if err := c.Limits.check(respw.D); err != nil {
+		return nil, err
+	}
+
 	res := &CancelResponse{}
 	if err := json.Unmarshal([]byte(respw.D), res); err != nil {
 		return nil, fmt.Errorf("Response Unmarshal Failed. Error: %v", err)
 	}
+	res.Normalize()
 
 	return res, nil
 }
@@ -319,7 +907,7 @@ type RequestItem struct {
 
 	// Required.
 	// 00 – Default / Number of unique access lines. *See Appendix F for additional values.
-	UnitType string
+	UnitType UnitType
 
 	// Required. Values:
 	// 01 – Two-out-of-Three test using NPA-NXX
@@ -332,7 +920,8 @@ type RequestItem struct {
 	// 14 – Use Zip code field for international country code (VAT calculations)
 	// 17 - Point to Point Zip codes (private line transactions) with both A and Z endpoints calculated*
 	// 27 – Use only Billing Address / Zip+4
-	TaxSitusRule string
+	// See the Situs constants.
+	TaxSitusRule TaxSitusRule
 
 	// Required. Transaction Type Indicator.
 	TransTypeCode string
@@ -341,10 +930,11 @@ type RequestItem struct {
 	// R – Residential customer (default) B – Business customer
 	// I – Industrial customer
 	// L – Lifeline customer
-	SalesTypeCode string
+	// See the Sales constants.
+	SalesTypeCode SalesTypeCode
 
-	// Required. Provider Type.
-	RegulatoryCode string
+	// Required. Provider Type. See the Regulatory constants.
+	RegulatoryCode RegulatoryCode
 
 	// Required. Tax Exemption to be applied to this item only.
 	TaxExemptionCodeList []string
@@ -420,6 +1010,24 @@ type RequestItem struct {
 
 	// P2P address for transaction
 	P2PAddress P2PAddress
+
+	// Optional. Service location of a metered energy or utility service, where the commodity is delivered,
+	// when it differs from the billing Address.
+	ServiceAddress *Address `json:",omitempty"`
+
+	// Optional. Meter or service point identifier of an energy or utility service. Max Len: 40
+	MeterNumber string `json:",omitempty"`
+
+	// Optional. Location of the service for reporting and tax aggregation by location. Returned in Group.LocationCode.
+	// Max Len: 50. Requires APIVersion2.
+	LocationCode string `json:",omitempty" since:"2"`
+
+	// Optional. Revenue of the item subject to auxiliary taxes only, in format $$$$$$$$$.CCCC.
+	// Requires APIVersion2.
+	AuxRevenue string `json:",omitempty" since:"2"`
+
+	// Optional. Type of the auxiliary revenue. Requires APIVersion2.
+	AuxRevenueType string `json:",omitempty" since:"2"`
 }
 
 type Address struct {
@@ -525,10 +1133,40 @@ type Response struct {
 	// Transaction ID (integer) – provided by CCH SureTax
 	TransId int
 
+	// Transaction ID of the submission the request was part of, equal to TransId unless
+	// SureTax split a multi-part submission into several transactions. See Response.Master.
+	MasterTransId int
+
 	// Total Tax – a total of all taxes included in the TaxList
 	TotalTax string
 
 	GroupList []Group
+
+	// Set on responses made up by SuretaxClient.Estimator while SureTax couldn't be reached.
+	// They have no TransId and the taxes aren't reported to SureTax.
+	Estimated bool `json:"-"`
+
+	// Set on responses calculated by SuretaxClient.Fallback instead of SureTax.
+	Fallback bool `json:"-"`
+
+	// Set on responses returned from SuretaxClient.Idempotency for a request sent before with the same STAN.
+	Replayed bool `json:"-"`
+
+	// Set on responses of Quote: the taxes are a preview, nothing was recorded by SureTax. See FinalizeQuote.
+	Quoted bool `json:"-"`
+
+	// InvoiceNumber and CustomerNumber values rewritten before the request was sent,
+	// when SuretaxClient.IdentifierMode is IdentifierSanitize.
+	IdentifierChanges []IdentifierChange `json:"-"`
+
+	// request sent by Quote
+	quoted *Request
+
+	// GroupList JSON held back by SuretaxClient.LazyGroups
+	lazy *lazyGroups
+
+	// backing array of the TaxLists, kept by Reset
+	taxes []Tax
 }
 
 type ItemMessage struct {
@@ -6,13 +6,12 @@ import (
 	"os"
 	"net/http"
 	"io/ioutil"
-	"sync"
 	)
 
-var testCli = SuretaxClient{"", "", sync.Mutex{}, nil}
+var testCli = SuretaxClient{}
 
 func TestMain(m *testing.M) {
-	SetDebugLogger(nil)
+	SetLogLevel(LevelError, nil)
 
 	retCode := m.Run()
 	os.Exit(retCode)
@@ -125,7 +124,7 @@ func Test_getClient_default(t *testing.T) {
 
 	SetHttpClient(nil)
 
-	cli := SuretaxClient{"", "", sync.Mutex{}, nil}
+	cli := SuretaxClient{}
 
 	c := cli.getClient()
 
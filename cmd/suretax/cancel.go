@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+// Values of a flag that may be repeated, each holding one or more comma separated values.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
+
+// Cancels transactions after confirmation and prints the results as CSV. Exits with 1 when some
+// transactions couldn't be cancelled.
+func runCancel(e *env, args []string) error {
+	fs := flag.NewFlagSet("cancel", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	acc := &account{}
+	acc.register(fs)
+	var ids listFlag
+	fs.Var(&ids, "trans-id", "TransId to cancel, repeatable or comma separated")
+	file := fs.String("file", "", "file of TransIds, one per line")
+	dryRun := fs.Bool("dry-run", false, "list the transactions without cancelling them")
+	yes := fs.Bool("yes", false, "cancel without asking for confirmation")
+	rate := fs.Float64("rate", 2, "cancellations per second")
+	retries := fs.Int("retries", 3, "retries of transient failures per transaction")
+	checkpoint := fs.String("checkpoint", "", "file recording progress, so an interrupted run can be resumed")
+	report := fs.String("report", "", "report file, by default the report is printed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *file != "" {
+		read, err := readTransIds(*file)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, read...)
+	}
+	ids = dedupe(ids)
+	if len(ids) == 0 {
+		return fmt.Errorf("cancel takes -trans-id or -file")
+	}
+	if *rate <= 0 {
+		return fmt.Errorf("-rate must be positive")
+	}
+
+	if *dryRun {
+		for _, id := range ids {
+			fmt.Fprintln(e.stdout, id)
+		}
+		fmt.Fprintf(e.stderr, "Dry run: %d transactions would be cancelled.\n", len(ids))
+		return nil
+	}
+
+	p, err := acc.resolve(e)
+	if err != nil {
+		return err
+	}
+	if p.CancelUrl == "" {
+		return fmt.Errorf("no cancel url, set -cancel-url or a -config profile")
+	}
+
+	if !*yes {
+		fmt.Fprintf(e.stderr, "Cancel %d transactions of client %s at %s? Type yes to continue: ", len(ids), p.ClientNumber, p.CancelUrl)
+		answer, _ := bufio.NewReader(e.stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			return fmt.Errorf("cancelled nothing, not confirmed")
+		}
+	}
+
+	runner := &suretax.CancelRunner{
+		Client:     p.Client(),
+		Limiter:    suretax.NewIntervalLimiter(time.Duration(float64(time.Second) / *rate)),
+		MaxRetries: *retries,
+	}
+	if *checkpoint != "" {
+		runner.Checkpoint = &suretax.FileCancelCheckpoint{Path: *checkpoint}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	res, runErr := runner.Run(ctx, ids)
+	if res == nil {
+		return runErr
+	}
+
+	w := e.stdout
+	if *report != "" {
+		f, err := os.Create(*report)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := res.WriteCSV(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stderr, "%d cancelled, %d failed, %d not attempted\n", len(res.Cancelled), len(res.Failed), len(ids)-len(res.Cancelled)-len(res.Failed))
+	if runErr != nil {
+		return runErr
+	}
+	if len(res.Failed) > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
+
+// Reads one TransId per line, skipping blank lines and # comments.
+func readTransIds(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ids []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	return ids, sc.Err()
+}
+
+func dedupe(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
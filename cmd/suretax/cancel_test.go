@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+func Test_Cancel(t *testing.T) {
+
+	t.Setenv(envValidationKey, "key")
+
+	var cancelled []string
+	suretax.SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		wrapper := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&wrapper)
+		req := &suretax.CancelRequest{}
+		json.Unmarshal([]byte(wrapper["requestCancel"]), req)
+		cancelled = append(cancelled, req.TransId)
+
+		inner := `{"Successful":"Y","ResponseCode":"9999","HeaderMessage":"Success"}`
+		if req.TransId == "3" {
+			inner = `{"Successful":"N","ResponseCode":"1510","HeaderMessage":"Transaction is more than 60 days old"}`
+		}
+		body, _ := json.Marshal(map[string]string{"d": inner})
+		return &http.Response{StatusCode: 200, Status: "200 OK", Body: io.NopCloser(strings.NewReader(string(body)))}, nil
+	}))
+	defer suretax.SetHttpClient(nil)
+
+	ids := filepath.Join(t.TempDir(), "ids.txt")
+	os.WriteFile(ids, []byte("# voided run\n2\n3\n\n1\n"), 0600)
+	base := []string{"cancel", "-client-number", "000000001", "-cancel-url", "https://cert/cancel", "-rate", "1000", "-trans-id", "1", "-file", ids}
+
+	e, stdout, _ := newTestEnv("")
+	if code := run(e, append(base, "-dry-run")); code != 0 || stdout.String() != "1\n2\n3\n" || len(cancelled) != 0 {
+		t.Fatalf("Expected a dry run to list the unique ids only but got %v: %q, %v", code, stdout, cancelled)
+	}
+
+	e, _, stderr := newTestEnv("no\n")
+	if code := run(e, base); code != 1 || len(cancelled) != 0 || !strings.Contains(stderr.String(), "not confirmed") {
+		t.Fatalf("Expected nothing to be cancelled without confirmation but got %v: %s", code, stderr)
+	}
+
+	e, stdout, stderr = newTestEnv("yes\n")
+	if code := run(e, base); code != 1 {
+		t.Fatalf("Expected the failed cancellation to exit with 1 but got %v: %s", code, stderr)
+	}
+	if strings.Join(cancelled, ",") != "1,2,3" {
+		t.Fatalf("Expected all ids to be cancelled in order but got %v", cancelled)
+	}
+	if !strings.Contains(stdout.String(), "3,failed,too old,1510") || !strings.Contains(stderr.String(), "2 cancelled, 1 failed") {
+		t.Fatalf("Expected the results report but got %s %s", stdout, stderr)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+const (
+	envClientNumber  = "SURETAX_CLIENT_NUMBER"
+	envValidationKey = "SURETAX_VALIDATION_KEY"
+	envConfigKey     = "SURETAX_CONFIG_KEY"
+)
+
+// Flags selecting the account shared by the commands.
+type account struct {
+	clientNumber string
+	environment  string
+	url          string
+	cancelUrl    string
+	config       string
+	profile      string
+}
+
+func (a *account) register(fs *flag.FlagSet) {
+	fs.StringVar(&a.clientNumber, "client-number", os.Getenv(envClientNumber), "SureTax client number")
+	fs.StringVar(&a.environment, "env", "", "SureTax environment, cert or production, setting -url and -cancel-url")
+	fs.StringVar(&a.url, "url", "", "SureTax post request url")
+	fs.StringVar(&a.cancelUrl, "cancel-url", "", "SureTax cancel post request url")
+	fs.StringVar(&a.config, "config", "", "config file holding the profile, encrypted values use the key in "+envConfigKey)
+	fs.StringVar(&a.profile, "profile", "default", "profile of -config")
+}
+
+// Returns the profile of the account. Flags override the config profile, and the validation key
+// comes from the environment, the profile or the keychain.
+func (a *account) resolve(e *env) (suretax.Profile, error) {
+	p := suretax.Profile{}
+	if a.config != "" {
+		cfg, err := suretax.LoadConfig(a.config, suretax.ConfigKeyFromEnv(envConfigKey))
+		if err != nil {
+			return p, err
+		}
+		if p, err = cfg.Profile(a.profile); err != nil {
+			return p, err
+		}
+	}
+
+	if a.clientNumber != "" {
+		p.ClientNumber = a.clientNumber
+	}
+	if a.environment != "" {
+		env, err := suretax.ParseEnvironment(a.environment)
+		if err != nil {
+			return p, err
+		}
+		p.Url, p.CancelUrl = env.Url(), env.CancelUrl()
+	}
+	if a.url != "" {
+		p.Url = a.url
+	}
+	if a.cancelUrl != "" {
+		p.CancelUrl = a.cancelUrl
+	}
+	if p.ClientNumber == "" {
+		return p, fmt.Errorf("no client number, set -client-number or %s", envClientNumber)
+	}
+
+	if key := os.Getenv(envValidationKey); key != "" {
+		p.ValidationKey = key
+	}
+	if p.ValidationKey == "" {
+		key, err := e.keyring.Get(p.ClientNumber)
+		if errors.Is(err, errKeyNotFound) {
+			return p, fmt.Errorf("no validation key for %s, run suretax login or set %s", p.ClientNumber, envValidationKey)
+		}
+		if err != nil {
+			return p, err
+		}
+		p.ValidationKey = key
+	}
+	return p, nil
+}
+
+func runLogin(e *env, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	clientNumber := fs.String("client-number", os.Getenv(envClientNumber), "SureTax client number")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *clientNumber == "" {
+		return fmt.Errorf("no client number, set -client-number or %s", envClientNumber)
+	}
+
+	fmt.Fprintf(e.stderr, "Validation key for %s: ", *clientNumber)
+	line, err := bufio.NewReader(e.stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("reading the validation key: %v", err)
+	}
+	key := strings.TrimSpace(line)
+	if key == "" {
+		return fmt.Errorf("the validation key is empty")
+	}
+
+	if err := e.keyring.Set(*clientNumber, key); err != nil {
+		return err
+	}
+	fmt.Fprintln(e.stderr, "Stored in the keychain.")
+	return nil
+}
+
+func runLogout(e *env, args []string) error {
+	fs := flag.NewFlagSet("logout", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	clientNumber := fs.String("client-number", os.Getenv(envClientNumber), "SureTax client number")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *clientNumber == "" {
+		return fmt.Errorf("no client number, set -client-number or %s", envClientNumber)
+	}
+
+	err := e.keyring.Delete(*clientNumber)
+	if errors.Is(err, errKeyNotFound) {
+		return nil
+	}
+	return err
+}
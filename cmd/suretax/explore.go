@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+// A level of the response tree browsed by explore: the response, an invoice, a line, a jurisdiction or a tax.
+type node struct {
+	label    string
+	total    *big.Rat
+	children []*node
+
+	// Fields shown for a single tax.
+	detail []string
+}
+
+func newNode(label string) *node {
+	return &node{label: label, total: new(big.Rat)}
+}
+
+// Returns the child labelled label, adding it if needed.
+func (n *node) child(label string) *node {
+	for _, c := range n.children {
+		if c.label == label {
+			return c
+		}
+	}
+	c := newNode(label)
+	n.children = append(n.children, c)
+	return c
+}
+
+// Builds the invoice → line → jurisdiction → tax tree of res with the tax totals of every level.
+func responseTree(res *suretax.Response) (*node, error) {
+	groups, err := res.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	root := newNode(fmt.Sprintf("TransId %d", res.TransId))
+	for _, g := range groups {
+		invoice := root.child("Invoice " + orNone(g.InvoiceNumber))
+		line := invoice.child("Line " + g.LineNumber)
+
+		for _, t := range g.TaxList {
+			jurisdiction := line.child(jurisdictionLabel(g.StateCode, t))
+			tax := newNode(t.TaxTypeCode + " " + t.TaxTypeDesc)
+			tax.detail = []string{
+				"Authority:      " + t.TaxAuthorityName + " (" + t.TaxAuthorityID + ")",
+				"Revenue:        " + t.Revenue,
+				"Revenue base:   " + t.RevenueBase,
+				"Rate:           " + strconv.FormatFloat(t.TaxRate, 'f', -1, 64),
+				"Fee rate:       " + strconv.FormatFloat(t.FeeRate, 'f', -1, 64),
+				"Percent taxed:  " + strconv.FormatFloat(t.PercentTaxable, 'f', -1, 64),
+				"Tax on tax:     " + t.TaxOnTax,
+				"Amount:         " + t.TaxAmount,
+			}
+			jurisdiction.children = append(jurisdiction.children, tax)
+
+			amount, ok := new(big.Rat).SetString(strings.TrimSpace(t.TaxAmount))
+			if !ok {
+				if t.TaxAmount != "" {
+					return nil, fmt.Errorf("invalid TaxAmount %q of line %s", t.TaxAmount, g.LineNumber)
+				}
+				continue
+			}
+			for _, n := range []*node{root, invoice, line, jurisdiction, tax} {
+				n.total.Add(n.total, amount)
+			}
+		}
+	}
+	return root, nil
+}
+
+func jurisdictionLabel(state string, t suretax.Tax) string {
+	parts := []string{state}
+	for _, p := range []string{t.CountyName, t.CityName} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	label := strings.Join(parts, " / ")
+	if t.Juriscode != "" {
+		label += " [" + t.Juriscode + "]"
+	}
+	return label
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// Browses a stored response, or the quote of a request, interactively: entering the number of a row
+// opens it, .. goes back up and q quits.
+func runExplore(e *env, args []string) error {
+	fs := flag.NewFlagSet("explore", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	acc := &account{}
+	acc.register(fs)
+	resPath := fs.String("response", "", "stored response JSON file")
+	reqPath := fs.String("request", "", "request JSON file to quote, instead of -response")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var res *suretax.Response
+	switch {
+	case *resPath != "":
+		b, err := os.ReadFile(*resPath)
+		if err != nil {
+			return err
+		}
+		res = &suretax.Response{}
+		if err := json.Unmarshal(b, res); err != nil {
+			return fmt.Errorf("%s: %v", *resPath, err)
+		}
+	case *reqPath != "":
+		var err error
+		if res, err = quoteFile(e, acc, *reqPath); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("explore takes -response or -request")
+	}
+
+	for _, m := range res.ItemMessages {
+		fmt.Fprintf(e.stdout, "Item error line %s (%s): %s\n", m.LineNumber, m.ResponseCode, m.Message)
+	}
+
+	root, err := responseTree(res)
+	if err != nil {
+		return err
+	}
+	return browse(e.stdin, e.stdout, root)
+}
+
+// Sends the request in path as a quote.
+func quoteFile(e *env, acc *account, path string) (*suretax.Response, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	req := &suretax.Request{}
+	if err := json.Unmarshal(b, req); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	p, err := acc.resolve(e)
+	if err != nil {
+		return nil, err
+	}
+	if p.Url == "" {
+		return nil, fmt.Errorf("no url, set -url or a -config profile")
+	}
+	req.ClientNumber, req.ValidationKey = p.ClientNumber, p.ValidationKey
+	req.ReturnFileCode = "Q"
+
+	return p.Client().Send(req)
+}
+
+func browse(in io.Reader, out io.Writer, root *node) error {
+	path := []*node{root}
+	sc := bufio.NewScanner(in)
+
+	for {
+		show(out, path)
+		fmt.Fprint(out, "> ")
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return sc.Err()
+		}
+
+		cmd := strings.TrimSpace(sc.Text())
+		current := path[len(path)-1]
+		switch cmd {
+		case "q", "quit", "exit":
+			return nil
+		case "..", "u", "up":
+			if len(path) > 1 {
+				path = path[:len(path)-1]
+			}
+		case "":
+		default:
+			i, err := strconv.Atoi(cmd)
+			if err != nil || i < 1 || i > len(current.children) {
+				fmt.Fprintf(out, "Enter 1 to %d, .. to go back or q to quit\n", len(current.children))
+				continue
+			}
+			path = append(path, current.children[i-1])
+		}
+	}
+}
+
+func show(out io.Writer, path []*node) {
+	labels := make([]string, len(path))
+	for i, n := range path {
+		labels[i] = n.label
+	}
+	current := path[len(path)-1]
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "%s  total %s\n", strings.Join(labels, " > "), current.total.FloatString(2))
+	for _, d := range current.detail {
+		fmt.Fprintln(out, "  "+d)
+	}
+
+	width := 0
+	for _, c := range current.children {
+		width = max(width, len(c.label))
+	}
+	for i, c := range current.children {
+		fmt.Fprintf(out, "%4d  %-*s  %12s\n", i+1, width, c.label, c.total.FloatString(2))
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+func Test_Explore(t *testing.T) {
+
+	res := &suretax.Response{ResponseCode: "9999", Successful: "Y", TransId: 42, GroupList: []suretax.Group{
+		{InvoiceNumber: "INV1", LineNumber: "1", StateCode: "IL", TaxList: []suretax.Tax{
+			{TaxTypeCode: "035", TaxTypeDesc: "STATE SALES TAX", CountyName: "COOK", CityName: "CHICAGO", TaxAmount: "1.25", TaxRate: 0.0625},
+			{TaxTypeCode: "040", TaxTypeDesc: "CITY SALES TAX", CountyName: "COOK", CityName: "CHICAGO", TaxAmount: "0.50"},
+		}},
+		{InvoiceNumber: "INV1", LineNumber: "2", StateCode: "IL", TaxList: []suretax.Tax{{TaxTypeCode: "035", TaxAmount: "2.00"}}},
+		{InvoiceNumber: "INV2", LineNumber: "3", StateCode: "WI", TaxList: []suretax.Tax{{TaxTypeCode: "035", TaxAmount: "1.00"}}},
+	}}
+	b, _ := json.Marshal(res)
+	path := filepath.Join(t.TempDir(), "response.json")
+	os.WriteFile(path, b, 0600)
+
+	e, stdout, stderr := newTestEnv("1\n1\n1\n1\n..\n9\nq\n")
+	if code := run(e, []string{"explore", "-response", path}); code != 0 {
+		t.Fatalf("Expected explore to succeed but got %v: %s", code, stderr)
+	}
+
+	out := stdout.String()
+	for _, expected := range []string{
+		"TransId 42  total 4.75",
+		"TransId 42 > Invoice INV1  total 3.75",
+		"TransId 42 > Invoice INV1 > Line 1  total 1.75",
+		"Line 1 > IL / COOK / CHICAGO  total 1.75",
+		"> 035 STATE SALES TAX  total 1.25",
+		"Rate:           0.0625",
+		"Enter 1 to 2",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected the output to contain %q but got\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import "errors"
+
+// Keychain service name the validation keys are stored under, one entry per client number.
+const keyringService = "go-suretax"
+
+var errKeyNotFound = errors.New("validation key not found in the keychain")
+
+// Secret storage keyed by client number.
+type keyring interface {
+	Get(clientNumber string) (string, error)
+	Set(clientNumber, key string) error
+	Delete(clientNumber string) error
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// The macOS login keychain, through the security tool.
+type systemKeyring struct{}
+
+func (systemKeyring) Get(clientNumber string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", clientNumber, "-w").Output()
+	if err != nil {
+		return "", securityError(err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (systemKeyring) Set(clientNumber, key string) error {
+	// -U updates an existing item. The key is passed as an argument, visible to other processes
+	// of the same user for the duration of the call but kept out of shell history.
+	return securityError(exec.Command("security", "add-generic-password", "-U", "-s", keyringService, "-a", clientNumber, "-w", key).Run())
+}
+
+func (systemKeyring) Delete(clientNumber string) error {
+	return securityError(exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", clientNumber).Run())
+}
+
+// The security tool exits with 44 when the item doesn't exist.
+func securityError(err error) error {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 44 {
+		return errKeyNotFound
+	}
+	return err
+}
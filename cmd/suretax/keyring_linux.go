@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// The freedesktop Secret Service (GNOME Keyring, KWallet), through secret-tool.
+type systemKeyring struct{}
+
+func (systemKeyring) Get(clientNumber string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "account", clientNumber).Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) || (err == nil && len(out) == 0) {
+		// secret-tool exits with 1 and prints nothing for a missing item
+		return "", errKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (systemKeyring) Set(clientNumber, key string) error {
+	cmd := exec.Command("secret-tool", "store", "--label=SureTax validation key "+clientNumber, "service", keyringService, "account", clientNumber)
+	cmd.Stdin = strings.NewReader(key)
+	return cmd.Run()
+}
+
+func (systemKeyring) Delete(clientNumber string) error {
+	return exec.Command("secret-tool", "clear", "service", keyringService, "account", clientNumber).Run()
+}
@@ -0,0 +1,25 @@
+//go:build !darwin && !linux && !windows
+
+package main
+
+import (
+	"errors"
+	"runtime"
+)
+
+// No keychain is supported on this platform, the validation key must come from the environment or a config profile.
+type systemKeyring struct{}
+
+var errNoKeyring = errors.New("no OS keychain support on " + runtime.GOOS + ", set SURETAX_VALIDATION_KEY instead")
+
+func (systemKeyring) Get(clientNumber string) (string, error) {
+	return "", errNoKeyring
+}
+
+func (systemKeyring) Set(clientNumber, key string) error {
+	return errNoKeyring
+}
+
+func (systemKeyring) Delete(clientNumber string) error {
+	return errNoKeyring
+}
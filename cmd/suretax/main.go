@@ -0,0 +1,95 @@
+// Command suretax sends requests to SureTax from the command line.
+//
+//	suretax login -client-number 000000001
+//	suretax send -env cert request.json
+//	suretax quote -csv cdrs.csv -map mapping.yaml -client-number 000000001 -url https://...
+//	suretax validate -strict feed/*.csv
+//	suretax cancel -file trans-ids.txt -checkpoint cancel.progress
+//	suretax explore -response response.json
+//	suretax serve -addr :8080 -config suretax.json -profile production
+//
+// The validation key is read from the SURETAX_VALIDATION_KEY environment variable, the -config profile,
+// or the OS keychain where login stores it, in that order. It is never taken from a flag,
+// so it doesn't end up in shell history.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// A subcommand, run with the arguments following its name.
+type command struct {
+	usage string
+	run   func(env *env, args []string) error
+}
+
+var commands = map[string]command{
+	"cancel":   {"Cancels transactions after confirmation, see -dry-run", runCancel},
+	"explore":  {"Browses a stored response or a quote by invoice, line, jurisdiction and tax", runExplore},
+	"login":    {"Stores the validation key of a client number in the OS keychain", runLogin},
+	"logout":   {"Removes the validation key of a client number from the OS keychain", runLogout},
+	"quote":    {"Quotes the items of a CSV feed and prints the taxes by state and tax type", runQuote},
+	"send":     {"Sends the request in a JSON file and prints the response", runSend},
+	"serve":    {"Serves the plain JSON facade, see -openapi for its description", runServe},
+	"validate": {"Checks request JSON or item CSV files, exiting with 1 when errors are found", runValidate},
+}
+
+// Streams and the keychain used by the commands, replaced in tests.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	keyring keyring
+}
+
+func main() {
+	e := &env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, keyring: systemKeyring{}}
+	os.Exit(run(e, os.Args[1:]))
+}
+
+func run(e *env, args []string) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(e.stderr, "suretax: unknown command %q\n", args[0])
+		usage(e.stderr)
+		return 2
+	}
+	err := cmd.run(e, args[1:])
+	if code, ok := err.(exitCode); ok {
+		return int(code)
+	}
+	if err != nil {
+		fmt.Fprintln(e.stderr, "suretax:", err)
+		return 1
+	}
+	return 0
+}
+
+// Returned by commands that have reported their outcome and only need to set the exit status.
+type exitCode int
+
+func (c exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(c))
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: suretax <command> [flags]")
+	fmt.Fprintln(w)
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].usage)
+	}
+}
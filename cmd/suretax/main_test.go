@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+type memoryKeyring map[string]string
+
+func (k memoryKeyring) Get(clientNumber string) (string, error) {
+	key, ok := k[clientNumber]
+	if !ok {
+		return "", errKeyNotFound
+	}
+	return key, nil
+}
+
+func (k memoryKeyring) Set(clientNumber, key string) error {
+	k[clientNumber] = key
+	return nil
+}
+
+func (k memoryKeyring) Delete(clientNumber string) error {
+	if _, ok := k[clientNumber]; !ok {
+		return errKeyNotFound
+	}
+	delete(k, clientNumber)
+	return nil
+}
+
+type httpClientFunc func(r *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func newTestEnv(stdin string) (*env, *bytes.Buffer, *bytes.Buffer) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	return &env{stdin: strings.NewReader(stdin), stdout: stdout, stderr: stderr, keyring: memoryKeyring{}}, stdout, stderr
+}
+
+func Test_LoginSend(t *testing.T) {
+
+	t.Setenv(envValidationKey, "")
+	t.Setenv(envClientNumber, "")
+
+	var sent map[string]interface{}
+	suretax.SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		wrapper := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&wrapper)
+		json.Unmarshal([]byte(wrapper["request"]), &sent)
+		body := `{"d":"{\"ResponseCode\":\"9999\",\"HeaderMessage\":\"Success\",\"Successful\":\"Y\",\"TransId\":7,\"TotalTax\":\"1.00\"}"}`
+		return &http.Response{StatusCode: 200, Status: "200 OK", Body: io.NopCloser(strings.NewReader(body))}, nil
+	}))
+	defer suretax.SetHttpClient(nil)
+
+	path := filepath.Join(t.TempDir(), "request.json")
+	if err := os.WriteFile(path, []byte(`{"ReturnFileCode":"Q","ItemList":[{"LineNumber":"1"}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	e, stdout, stderr := newTestEnv("")
+	if code := run(e, []string{"send", "-client-number", "000000001", "-url", "https://cert/post", path}); code != 1 || !strings.Contains(stderr.String(), "suretax login") {
+		t.Fatalf("Expected send to fail without a validation key but got %v: %s", code, stderr)
+	}
+
+	e.stdin = strings.NewReader("secret-key\n")
+	if code := run(e, []string{"login", "-client-number", "000000001"}); code != 0 || strings.Contains(stderr.String(), "secret-key") {
+		t.Fatalf("Expected login to store the key but got %v: %s", code, stderr)
+	}
+
+	if code := run(e, []string{"send", "-client-number", "000000001", "-url", "https://cert/post", path}); code != 0 {
+		t.Fatalf("Expected send to succeed but got %v: %s", code, stderr)
+	}
+	if sent["ValidationKey"] != "secret-key" || sent["ClientNumber"] != "000000001" {
+		t.Fatalf("Expected the keychain credentials to be sent but got %v", sent)
+	}
+	if !strings.Contains(stdout.String(), `"TransId": 7`) {
+		t.Fatalf("Expected the response to be printed but got %s", stdout)
+	}
+
+	stdout.Reset()
+	if code := run(e, []string{"send", "-format", "text", "-client-number", "000000001", "-url", "https://cert/post", path}); code != 0 || !strings.Contains(stdout.String(), "Total") {
+		t.Fatalf("Expected the response table but got %v: %s", code, stdout)
+	}
+
+	t.Setenv(envValidationKey, "env-key")
+	if code := run(e, []string{"send", "-client-number", "000000001", "-url", "https://cert/post", path}); code != 0 || sent["ValidationKey"] != "env-key" {
+		t.Fatalf("Expected the environment key to take precedence but got %v", sent)
+	}
+
+	if code := run(e, []string{"logout", "-client-number", "000000001"}); code != 0 || len(e.keyring.(memoryKeyring)) != 0 {
+		t.Fatalf("Expected logout to remove the key but got %v", e.keyring)
+	}
+	if code := run(e, []string{"bogus"}); code != 2 {
+		t.Fatalf("Expected an unknown command to exit with 2 but got %v", code)
+	}
+}
+
+func Test_ServeOpenAPI(t *testing.T) {
+
+	e, stdout, stderr := newTestEnv("")
+	if code := run(e, []string{"serve", "-openapi"}); code != 0 {
+		t.Fatalf("Expected the document to be printed but got %v: %s", code, stderr)
+	}
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil || doc["openapi"] != "3.0.3" {
+		t.Fatalf("Expected an OpenAPI document but got %v, %s", err, stdout)
+	}
+}
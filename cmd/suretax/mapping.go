@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Column mapping of a CSV item feed, read from a YAML file like
+//
+//	columns:
+//	  cdr_id: InvoiceNumber
+//	  amount: Revenue
+//	  zip: Address.PostalCode
+//	defaults:
+//	  TaxSitusRule: "04"
+//	  TransTypeCode: "010101"
+//
+// A file without sections is read as the columns.
+type mapping struct {
+	columns  map[string]string
+	defaults map[string]string
+}
+
+// Reads the subset of YAML used by mapping files: comments, one level of sections and scalar values.
+func readMapping(r io.Reader) (*mapping, error) {
+	m := &mapping{columns: map[string]string{}, defaults: map[string]string{}}
+	section := m.columns
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("mapping line %d: expected key: value", n)
+		}
+		indented := strings.HasPrefix(key, " ") || strings.HasPrefix(key, "\t")
+		key, value = unquote(strings.TrimSpace(key)), unquote(strings.TrimSpace(value))
+
+		if !indented && value == "" {
+			switch key {
+			case "columns":
+				section = m.columns
+			case "defaults":
+				section = m.defaults
+			default:
+				return nil, fmt.Errorf("mapping line %d: unknown section %s", n, key)
+			}
+			continue
+		}
+		if value == "" {
+			return nil, fmt.Errorf("mapping line %d: %s has no value", n, key)
+		}
+		section[key] = value
+	}
+	return m, sc.Err()
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
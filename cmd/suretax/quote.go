@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+// Taxes of one state and tax type in the quote report.
+type taxSummary struct {
+	state, code, desc string
+	items             int
+	tax               *big.Rat
+}
+
+// Quotes the items of a CSV feed in chunks and writes the taxes summarized by state and tax type.
+// Nothing is posted: every request uses ReturnFileCode Q.
+func runQuote(e *env, args []string) error {
+	fs := flag.NewFlagSet("quote", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	acc := &account{}
+	acc.register(fs)
+	csvPath := fs.String("csv", "", "CSV file of the items")
+	mapPath := fs.String("map", "", "YAML mapping of the CSV columns to item fields, by default the headers are field names")
+	chunk := fs.Int("chunk", 1000, "items per quote request")
+	now := time.Now()
+	dataYear := fs.String("data-year", strconv.Itoa(now.Year()), "DataYear of the requests")
+	dataMonth := fs.String("data-month", strconv.Itoa(int(now.Month())), "DataMonth of the requests")
+	businessUnit := fs.String("business-unit", "", "BusinessUnit of the requests")
+	out := fs.String("out", "", "report file, by default the report is printed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *csvPath == "" {
+		return fmt.Errorf("quote takes the -csv file of the items")
+	}
+	if *chunk <= 0 {
+		return fmt.Errorf("-chunk must be positive")
+	}
+
+	items, err := readItems(*csvPath, *mapPath)
+	if err != nil {
+		return err
+	}
+
+	p, err := acc.resolve(e)
+	if err != nil {
+		return err
+	}
+	if p.Url == "" {
+		return fmt.Errorf("no url, set -url or a -config profile")
+	}
+	client := p.Client()
+
+	summaries := map[[2]string]*taxSummary{}
+	var itemErrors int
+	for start := 0; start < len(items); start += *chunk {
+		end := min(start+*chunk, len(items))
+		req := &suretax.Request{
+			BusinessUnit:   *businessUnit,
+			DataYear:       *dataYear,
+			DataMonth:      *dataMonth,
+			ReturnFileCode: "Q",
+			ResponseGroup:  "00",
+			ResponseType:   "D2",
+			ItemList:       items[start:end],
+		}
+
+		res, err := client.Send(req)
+		if err != nil {
+			return fmt.Errorf("items %d to %d: %v", start+1, end, err)
+		}
+		fmt.Fprintf(e.stderr, "Quoted items %d to %d, total tax %s\n", start+1, end, res.TotalTax)
+
+		itemErrors += len(res.ItemMessages)
+		if err := summarize(summaries, res); err != nil {
+			return err
+		}
+	}
+
+	w := e.stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeQuoteReport(w, summaries); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stderr, "%d items quoted, %d item errors\n", len(items), itemErrors)
+	return nil
+}
+
+func readItems(csvPath, mapPath string) ([]suretax.RequestItem, error) {
+	var m *mapping
+	if mapPath != "" {
+		f, err := os.Open(mapPath)
+		if err != nil {
+			return nil, err
+		}
+		m, err = readMapping(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", mapPath, err)
+		}
+	}
+
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var columns map[string]string
+	if m != nil && len(m.columns) > 0 {
+		columns = m.columns
+	}
+	items, err := suretax.ReadItemsCSV(f, columns)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", csvPath, err)
+	}
+	if m != nil {
+		if err := suretax.ApplyItemDefaults(items, m.defaults); err != nil {
+			return nil, fmt.Errorf("%s: %v", mapPath, err)
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s has no items", csvPath)
+	}
+	return items, nil
+}
+
+func summarize(summaries map[[2]string]*taxSummary, res *suretax.Response) error {
+	groups, err := res.Groups()
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		for _, t := range g.TaxList {
+			key := [2]string{g.StateCode, t.TaxTypeCode}
+			s, ok := summaries[key]
+			if !ok {
+				s = &taxSummary{state: g.StateCode, code: t.TaxTypeCode, desc: t.TaxTypeDesc, tax: new(big.Rat)}
+				summaries[key] = s
+			}
+			amount, ok := new(big.Rat).SetString(strings.TrimSpace(t.TaxAmount))
+			if t.TaxAmount != "" && !ok {
+				return fmt.Errorf("invalid TaxAmount %q of line %s", t.TaxAmount, g.LineNumber)
+			}
+			if ok {
+				s.tax.Add(s.tax, amount)
+			}
+			s.items++
+		}
+	}
+	return nil
+}
+
+func writeQuoteReport(w io.Writer, summaries map[[2]string]*taxSummary) error {
+	rows := make([]*taxSummary, 0, len(summaries))
+	for _, s := range summaries {
+		rows = append(rows, s)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].state != rows[j].state {
+			return rows[i].state < rows[j].state
+		}
+		return rows[i].code < rows[j].code
+	})
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"State", "TaxTypeCode", "TaxTypeDesc", "Items", "TaxAmount"})
+	total := new(big.Rat)
+	for _, s := range rows {
+		total.Add(total, s.tax)
+		cw.Write([]string{s.state, s.code, s.desc, strconv.Itoa(s.items), s.tax.FloatString(2)})
+	}
+	cw.Write([]string{"", "", "Total", "", total.FloatString(2)})
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+func Test_Quote(t *testing.T) {
+
+	t.Setenv(envValidationKey, "key")
+
+	var requests []*suretax.Request
+	suretax.SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		wrapper := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&wrapper)
+		req := &suretax.Request{}
+		json.Unmarshal([]byte(wrapper["request"]), req)
+		requests = append(requests, req)
+
+		res := &suretax.Response{ResponseCode: "9999", HeaderMessage: "Success", Successful: "Y", TotalTax: "0"}
+		for _, item := range req.ItemList {
+			res.GroupList = append(res.GroupList, suretax.Group{LineNumber: item.LineNumber, StateCode: "IL", TaxList: []suretax.Tax{
+				{TaxTypeCode: "106", TaxTypeDesc: "CA EMERG TEL. USERS SURCHARGE", TaxAmount: "0.50"},
+				{TaxTypeCode: "035", TaxTypeDesc: "STATE SALES TAX", TaxAmount: "1.25"},
+			}})
+		}
+		inner, _ := json.Marshal(res)
+		body, _ := json.Marshal(map[string]string{"d": string(inner)})
+		return &http.Response{StatusCode: 200, Status: "200 OK", Body: io.NopCloser(strings.NewReader(string(body)))}, nil
+	}))
+	defer suretax.SetHttpClient(nil)
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "cdrs.csv")
+	mapPath := filepath.Join(dir, "mapping.yaml")
+	os.WriteFile(csvPath, []byte("cdr,amount,zip\nA1,10.00,60601\nA2,20.00,60601\nA3,5.00,60602\n"), 0600)
+	os.WriteFile(mapPath, []byte("# tax team what-if\ncolumns:\n  cdr: InvoiceNumber\n  amount: Revenue\n  zip: Address.PostalCode\ndefaults:\n  TaxSitusRule: \"04\"\n  TransTypeCode: '010101' # voice\n"), 0600)
+
+	e, stdout, stderr := newTestEnv("")
+	code := run(e, []string{"quote", "-csv", csvPath, "-map", mapPath, "-chunk", "2", "-client-number", "000000001", "-url", "https://cert/post", "-data-year", "2026", "-data-month", "10"})
+	if code != 0 {
+		t.Fatalf("Expected the quote to succeed but got %v: %s", code, stderr)
+	}
+
+	if len(requests) != 2 || len(requests[0].ItemList) != 2 || len(requests[1].ItemList) != 1 {
+		t.Fatalf("Expected the items in chunks of 2 but got %d requests", len(requests))
+	}
+	item := requests[0].ItemList[0]
+	if requests[0].ReturnFileCode != "Q" || item.InvoiceNumber != "A1" || item.Address.PostalCode != "60601" || item.TaxSitusRule != "04" || item.TransTypeCode != "010101" {
+		t.Fatalf("Expected mapped quote items but got %+v", requests[0])
+	}
+
+	expected := "State,TaxTypeCode,TaxTypeDesc,Items,TaxAmount\n" +
+		"IL,035,STATE SALES TAX,3,3.75\n" +
+		"IL,106,CA EMERG TEL. USERS SURCHARGE,3,1.50\n" +
+		",,Total,,5.25\n"
+	if stdout.String() != expected {
+		t.Fatalf("Expected the summarized report but got\n%s", stdout)
+	}
+}
+
+func Test_readMapping(t *testing.T) {
+
+	m, err := readMapping(strings.NewReader("a: InvoiceNumber\nb: \"Revenue\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.columns["a"] != "InvoiceNumber" || m.columns["b"] != "Revenue" {
+		t.Fatalf("Expected a flat file to map columns but got %+v", m.columns)
+	}
+
+	if _, err := readMapping(strings.NewReader("other:\n  a: b\n")); err == nil {
+		t.Fatal("Expected an error for an unknown section")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+func runSend(e *env, args []string) error {
+	fs := flag.NewFlagSet("send", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	acc := &account{}
+	acc.register(fs)
+	format := fs.String("format", "json", "output format: json, text or markdown")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "json" && *format != "text" && *format != "markdown" {
+		return fmt.Errorf("unknown -format %s", *format)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("send takes the request file")
+	}
+
+	b, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	req := &suretax.Request{}
+	if err := json.Unmarshal(b, req); err != nil {
+		return fmt.Errorf("%s: %v", fs.Arg(0), err)
+	}
+
+	p, err := acc.resolve(e)
+	if err != nil {
+		return err
+	}
+	if p.Url == "" {
+		return fmt.Errorf("no url, set -url or a -config profile")
+	}
+	req.ClientNumber, req.ValidationKey = p.ClientNumber, p.ValidationKey
+
+	res, err := p.Client().Send(req)
+	if res != nil {
+		if outErr := writeResponse(e.stdout, res, *format); outErr != nil && err == nil {
+			err = outErr
+		}
+	}
+	return err
+}
+
+func writeResponse(w io.Writer, res *suretax.Response, format string) error {
+	switch format {
+	case "text":
+		return suretax.WriteTable(w, res, suretax.TableText)
+	case "markdown":
+		return suretax.WriteTable(w, res, suretax.TableMarkdown)
+	}
+	return writeJSON(w, res)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
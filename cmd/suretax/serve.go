@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+const envSigningKey = "SURETAX_FACADE_SIGNING_KEY"
+
+func runServe(e *env, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	acc := &account{}
+	acc.register(fs)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	openapi := fs.Bool("openapi", false, "print the OpenAPI document and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *openapi {
+		return writeJSON(e.stdout, suretax.FacadeOpenAPI())
+	}
+
+	p, err := acc.resolve(e)
+	if err != nil {
+		return err
+	}
+	if p.Url == "" || p.CancelUrl == "" {
+		return fmt.Errorf("no urls, set -url and -cancel-url or a -config profile")
+	}
+
+	f := &suretax.Facade{Client: p.Client()}
+	if key := os.Getenv(envSigningKey); key != "" {
+		f.Verifier = &suretax.HMACSigner{Key: []byte(key)}
+	}
+
+	fmt.Fprintln(e.stderr, "Serving the SureTax facade on", *addr)
+	return http.ListenAndServe(*addr, f)
+}
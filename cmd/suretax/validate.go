@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	suretax "github.com/glebteterin/go-suretax"
+)
+
+// Finding of a validated file, as printed with -json.
+type fileFinding struct {
+	File     string `json:"file"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// Checks request files for CI gating of billing feeds. Exits with 0 when no errors are found,
+// 1 when some are (or warnings with -strict) and 2 when a file can't be read.
+func runValidate(e *env, args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	strict := fs.Bool("strict", false, "fail on warnings too")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("validate takes one or more .json or .csv files")
+	}
+
+	findings := []fileFinding{}
+	failed := false
+	for _, path := range fs.Args() {
+		found, err := lintFile(path)
+		if err != nil {
+			fmt.Fprintln(e.stderr, "suretax:", err)
+			return exitCode(2)
+		}
+		for _, f := range found {
+			if f.Severity == suretax.LintError || *strict {
+				failed = true
+			}
+			findings = append(findings, fileFinding{path, f.Severity.String(), f.Path, f.Message})
+		}
+	}
+
+	if *asJSON {
+		if err := writeJSON(e.stdout, findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Fprintf(e.stdout, "%s: %s: %s: %s\n", f.File, f.Path, f.Severity, f.Message)
+		}
+	}
+
+	if failed {
+		return exitCode(1)
+	}
+	return nil
+}
+
+// Returns the findings of a request JSON file, or of the items of a CSV file with field path headers.
+func lintFile(path string) ([]suretax.LintFinding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		items, err := suretax.ReadItemsCSV(f, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return suretax.LintItems(items), nil
+	}
+
+	req := &suretax.Request{}
+	if err := json.NewDecoder(f).Decode(req); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return suretax.LintRequest(req), nil
+}
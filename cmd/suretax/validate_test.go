@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Validate(t *testing.T) {
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.csv")
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(good, []byte("LineNumber,TransDate,Revenue,TaxSitusRule,TransTypeCode,InvoiceNumber,OrigNumber\n1,10/14/2026,10.00,03,010101,INV-1,9043101723\n"), 0600)
+	os.WriteFile(bad, []byte(`{"DataYear":"2026","DataMonth":"13","ReturnFileCode":"0","ClientNumber":"000000001","ItemList":[{"LineNumber":"1","Revenue":"x"}]}`), 0600)
+
+	e, stdout, _ := newTestEnv("")
+	if code := run(e, []string{"validate", good}); code != 0 || !strings.Contains(stdout.String(), "good.csv: ItemList[0].InvoiceNumber: warning:") {
+		t.Fatalf("Expected warnings only to pass but got %v: %s", code, stdout)
+	}
+	if code := run(e, []string{"validate", "-strict", good}); code != 1 {
+		t.Fatalf("Expected warnings to fail with -strict but got %v", code)
+	}
+
+	e, stdout, _ = newTestEnv("")
+	if code := run(e, []string{"validate", "-json", good, bad}); code != 1 {
+		t.Fatalf("Expected errors to exit with 1 but got %v", code)
+	}
+	var findings []fileFinding
+	if err := json.Unmarshal(stdout.Bytes(), &findings); err != nil {
+		t.Fatal(err)
+	}
+	paths := map[string]bool{}
+	for _, f := range findings {
+		paths[f.Path] = true
+	}
+	for _, p := range []string{"DataMonth", "ItemList[0].Revenue", "ItemList[0].TransDate", "ItemList[0].TaxSitusRule"} {
+		if !paths[p] {
+			t.Fatalf("Expected a finding for %s but got %+v", p, findings)
+		}
+	}
+
+	if code := run(e, []string{"validate", filepath.Join(dir, "missing.json")}); code != 2 {
+		t.Fatalf("Expected an unreadable file to exit with 2 but got %v", code)
+	}
+}
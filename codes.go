@@ -0,0 +1,83 @@
+package suretax
+
+// Kind of customer an item is sold to, RequestItem.SalesTypeCode.
+type SalesTypeCode string
+
+const (
+	SalesResidential SalesTypeCode = "R"
+	SalesBusiness    SalesTypeCode = "B"
+	SalesIndustrial  SalesTypeCode = "I"
+	SalesLifeline    SalesTypeCode = "L"
+)
+
+var salesTypes = map[SalesTypeCode]string{
+	SalesResidential: "Residential customer",
+	SalesBusiness:    "Business customer",
+	SalesIndustrial:  "Industrial customer",
+	SalesLifeline:    "Lifeline customer",
+}
+
+// Reports whether s is a sales type SureTax knows.
+func (s SalesTypeCode) Valid() bool {
+	_, ok := salesTypes[s]
+	return ok
+}
+
+// Returns the SureTax description of s, empty for an unknown sales type.
+func (s SalesTypeCode) Description() string {
+	return salesTypes[s]
+}
+
+// Provider type of the seller, RequestItem.RegulatoryCode.
+type RegulatoryCode string
+
+const (
+	// Incumbent local exchange carrier
+	RegulatoryILEC RegulatoryCode = "00"
+
+	// Interexchange carrier
+	RegulatoryIXC RegulatoryCode = "01"
+
+	// Competitive local exchange carrier
+	RegulatoryCLEC RegulatoryCode = "02"
+
+	RegulatoryVOIP     RegulatoryCode = "03"
+	RegulatoryISP      RegulatoryCode = "04"
+	RegulatoryWireless RegulatoryCode = "05"
+
+	// Provider type configured for the account
+	RegulatoryDefault RegulatoryCode = "99"
+)
+
+var regulatoryCodes = map[RegulatoryCode]string{
+	RegulatoryILEC:     "ILEC",
+	RegulatoryIXC:      "IXC",
+	RegulatoryCLEC:     "CLEC",
+	RegulatoryVOIP:     "VOIP",
+	RegulatoryISP:      "ISP",
+	RegulatoryWireless: "Wireless",
+	RegulatoryDefault:  "Default",
+}
+
+// Reports whether r is a provider type SureTax knows.
+func (r RegulatoryCode) Valid() bool {
+	_, ok := regulatoryCodes[r]
+	return ok
+}
+
+// Returns the SureTax description of r, empty for an unknown provider type.
+func (r RegulatoryCode) Description() string {
+	return regulatoryCodes[r]
+}
+
+// Unit of RequestItem.Units, RequestItem.UnitType. Appendix F of the SureTax specification
+// lists the codes; utility codes depend on the account and are configured in UtilityUnits.
+type UnitType string
+
+// UnitType for the number of unique access lines, the default unit of per-line fees such as E911.
+const UnitTypeLines UnitType = "00"
+
+// Reports whether u is a 2 digit code. Utility codes are checked with UtilityUnits.Valid.
+func (u UnitType) Valid() bool {
+	return len(u) == 2 && isDigits(string(u))
+}
@@ -0,0 +1,20 @@
+package suretax
+
+import "testing"
+
+func Test_Codes(t *testing.T) {
+
+	if !SalesLifeline.Valid() || SalesTypeCode("X").Valid() || SalesBusiness.Description() != "Business customer" {
+		t.Fatalf("Expected L to be valid and X not, with a description for B, but got %q", SalesBusiness.Description())
+	}
+	if !RegulatoryDefault.Valid() || RegulatoryCode("42").Valid() || RegulatoryVOIP.Description() != "VOIP" {
+		t.Fatalf("Expected 99 to be valid and 42 not, with a description for 03, but got %q", RegulatoryVOIP.Description())
+	}
+
+	units := UtilityUnits{UtilityTherms: "TH"}
+	for u, expected := range map[UnitType]bool{UnitTypeLines: true, "07": true, "TH": true, "7": false, "K1": false} {
+		if units.Valid(u) != expected || u.Valid() != (expected && u != "TH") {
+			t.Fatalf("Expected UnitType %q valid %v", u, expected)
+		}
+	}
+}
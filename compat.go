@@ -0,0 +1,55 @@
+package suretax
+
+// Fills the fields of res that some endpoint versions leave out with the values derived from
+// the rest of the response, so callers can rely on them whatever the version:
+// Successful and HeaderMessage from ResponseCode, and TotalTax from the decoded groups.
+// Fields that are present are never changed.
+func (r *Response) Normalize() {
+	if r.ResponseCode == "" {
+		return
+	}
+
+	if r.Successful == "" {
+		if c := r.Category(); c == ResponseSuccess || c == ResponseSuccessWithErrors {
+			r.Successful = "Y"
+		} else {
+			r.Successful = "N"
+		}
+	}
+
+	if c, description := Classify(r.ResponseCode); r.HeaderMessage == "" && (c == ResponseSuccess || c == ResponseSuccessWithErrors) {
+		r.HeaderMessage = description
+	}
+
+	if r.TotalTax == "" && r.lazy == nil && len(r.GroupList) > 0 {
+		var amounts []string
+		for _, g := range r.GroupList {
+			for _, t := range g.TaxList {
+				amounts = append(amounts, t.TaxAmount)
+			}
+		}
+		if total, err := sumAmounts(amounts); err == nil {
+			r.TotalTax = total
+		}
+	}
+}
+
+// Same as Response.Normalize, for the Successful field of a cancel response.
+func (r *CancelResponse) Normalize() {
+	if r.ResponseCode == "" || r.Successful != "" {
+		return
+	}
+	if r.ResponseCode == "9999" {
+		r.Successful = "Y"
+	} else {
+		r.Successful = "N"
+	}
+}
+
+// Normalizes a decoded response, taking a missing TransId from MasterTransId.
+func normalizeResponse(res *Response) {
+	if res.TransId == 0 {
+		res.TransId = res.MasterTransId
+	}
+	res.Normalize()
+}
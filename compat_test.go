@@ -0,0 +1,25 @@
+package suretax
+
+import "testing"
+
+func Test_normalizeResponse(t *testing.T) {
+
+	res, err := testCli.decodeResponse([]byte(envelope(`{"ResponseCode":"9001","MasterTransId":616039832,` +
+		`"GroupList":[{"LineNumber":"1","TaxList":[{"TaxAmount":"1.25"},{"TaxAmount":"0.50"}]}]}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TransId != 616039832 || res.Successful != "Y" || res.HeaderMessage != "Success with Item errors" || res.TotalTax != "1.75" {
+		t.Fatalf("Expected the missing fields to be derived but got %+v", res)
+	}
+
+	res, _ = testCli.decodeResponse([]byte(envelope(`{"ResponseCode":"1151","HeaderMessage":"Failure - Invalid Validation Key","TransId":7,"MasterTransId":8}`)))
+	if res.TransId != 7 || res.Successful != "N" || res.HeaderMessage != "Failure - Invalid Validation Key" || res.TotalTax != "" {
+		t.Fatalf("Expected present fields to be kept but got %+v", res)
+	}
+
+	cancel, _ := testCli.decodeCancelResponse([]byte(envelope(`{"ResponseCode":"9410"}`)))
+	if cancel.Successful != "N" {
+		t.Fatalf("Expected Successful N but got %v", cancel.Successful)
+	}
+}
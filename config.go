@@ -0,0 +1,175 @@
+package suretax
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Prefix of config values encrypted with EncryptConfigValue.
+const encryptedValuePrefix = "enc:v1:"
+
+// Connection settings and credentials of one SureTax account.
+type Profile struct {
+	Url       string `json:"url"`
+	CancelUrl string `json:"cancelUrl"`
+
+	ClientNumber  string `json:"clientNumber"`
+	ValidationKey string `json:"validationKey"`
+}
+
+// Returns the credentials of the profile.
+func (p Profile) Credentials() Credentials {
+	return Credentials{ClientNumber: p.ClientNumber, ValidationKey: p.ValidationKey}
+}
+
+// Returns a client posting to the profile URLs with the profile credentials.
+func (p Profile) Client() *SuretaxClient {
+	return &SuretaxClient{Url: p.Url, CancelUrl: p.CancelUrl, Credentials: p.Credentials()}
+}
+
+// Sets the credentials of req that are empty.
+func (p Profile) Apply(req *Request) {
+	p.Credentials().Apply(req)
+}
+
+// Sets the credentials of req that are empty.
+func (p Profile) ApplyCancel(req *CancelRequest) {
+	p.Credentials().ApplyCancel(req)
+}
+
+// Named profiles loaded by LoadConfig from a file like
+//
+//	{"profiles": {
+//	  "cert": {"url": "https://...", "cancelUrl": "https://...", "clientNumber": "enc:v1:...", "validationKey": "enc:v1:..."}
+//	}}
+//
+// ClientNumber and ValidationKey may be stored encrypted with EncryptConfigValue.
+type Config struct {
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Returns the profile called name.
+func (c *Config) Profile(name string) (Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("Config has no profile %s", name)
+	}
+	return p, nil
+}
+
+// Returns the 32-byte AES key of encrypted config values, e.g. fetched from a KMS.
+type ConfigKeyFunc func() ([]byte, error)
+
+// Returns a ConfigKeyFunc reading the base64 encoded key from the environment variable name.
+func ConfigKeyFromEnv(name string) ConfigKeyFunc {
+	return func() ([]byte, error) {
+		v := os.Getenv(name)
+		if v == "" {
+			return nil, fmt.Errorf("Environment variable %s is not set", name)
+		}
+		key, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("Environment variable %s is not base64: %v", name, err)
+		}
+		return key, nil
+	}
+}
+
+// Reads the config at path, decrypting encrypted values with the key returned by key.
+// key may be nil when no value is encrypted; it is only called if one is.
+func LoadConfig(path string, key ConfigKeyFunc) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("Config %s Unmarshal Failed. Error: %v", path, err)
+	}
+
+	var aead cipher.AEAD
+	decrypt := func(profile, field string, v *string) error {
+		if !strings.HasPrefix(*v, encryptedValuePrefix) {
+			return nil
+		}
+		if aead == nil {
+			if key == nil {
+				return fmt.Errorf("Config %s has encrypted values but no key was given", path)
+			}
+			k, err := key()
+			if err != nil {
+				return err
+			}
+			if aead, err = newConfigCipher(k); err != nil {
+				return err
+			}
+		}
+		plain, err := openConfigValue(aead, *v)
+		if err != nil {
+			return fmt.Errorf("Config %s profile %s %s: %v", path, profile, field, err)
+		}
+		*v = plain
+		return nil
+	}
+
+	for name, p := range cfg.Profiles {
+		if err := decrypt(name, "clientNumber", &p.ClientNumber); err != nil {
+			return nil, err
+		}
+		if err := decrypt(name, "validationKey", &p.ValidationKey); err != nil {
+			return nil, err
+		}
+		cfg.Profiles[name] = p
+	}
+
+	return cfg, nil
+}
+
+// Returns plaintext encrypted with AES-256-GCM under key, in the form stored in config files.
+func EncryptConfigValue(key []byte, plaintext string) (string, error) {
+	aead, err := newConfigCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func newConfigCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("Config key must be 32 bytes but is %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func openConfigValue(aead cipher.AEAD, v string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(v, encryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("encrypted value is not base64: %v", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypted value is truncated")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("encrypted value can't be decrypted with the key")
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,65 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_LoadConfig_Encrypted(t *testing.T) {
+
+	key := bytes.Repeat([]byte{7}, 32)
+	clientNumber, err := EncryptConfigValue(key, "000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validationKey, err := EncryptConfigValue(key, "secret-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(validationKey, "secret") {
+		t.Fatalf("Expected the value to be encrypted but got %v", validationKey)
+	}
+
+	path := filepath.Join(t.TempDir(), "suretax.json")
+	doc := `{"profiles": {
+		"cert": {"url": "https://cert/post", "cancelUrl": "https://cert/cancel", "clientNumber": "` + clientNumber + `", "validationKey": "` + validationKey + `"},
+		"plain": {"url": "https://plain/post", "clientNumber": "000000002"}
+	}}`
+	if err := os.WriteFile(path, []byte(doc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SURETAX_TEST_CONFIG_KEY", base64.StdEncoding.EncodeToString(key))
+	cfg, err := LoadConfig(path, ConfigKeyFromEnv("SURETAX_TEST_CONFIG_KEY"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := cfg.Profile("cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ClientNumber != "000000001" || p.ValidationKey != "secret-key" || p.Client().Url != "https://cert/post" {
+		t.Fatalf("Expected the decrypted profile but got %+v", p)
+	}
+
+	req := &Request{}
+	p.Apply(req)
+	if req.ClientNumber != "000000001" || req.ValidationKey != "secret-key" {
+		t.Fatalf("Expected the credentials to be applied but got %+v", req)
+	}
+
+	if _, err := LoadConfig(path, nil); err == nil {
+		t.Fatal("Expected an error loading encrypted values without a key")
+	}
+	wrong := func() ([]byte, error) { return bytes.Repeat([]byte{8}, 32), nil }
+	if _, err := LoadConfig(path, wrong); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("Expected an error decrypting with the wrong key but got %v", err)
+	}
+	if _, err := cfg.Profile("missing"); err == nil {
+		t.Fatal("Expected an error for a missing profile")
+	}
+}
@@ -0,0 +1,136 @@
+package suretax
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Conformance suite run against the live SureTax CERT environment, certifying that upgrades of the package
+// still parse its responses. Skipped unless CERT credentials are set:
+//
+//	SURETAX_CERT_CLIENT_NUMBER, SURETAX_CERT_VALIDATION_KEY  required
+//	SURETAX_CERT_URL, SURETAX_CERT_CANCEL_URL                 default to the CERT endpoints
+//	SURETAX_CERT_OLD_TRANSID                                  a TransId past the cancellation window, for the too-old scenario
+//
+// Run with: go test -run Test_Conformance -v
+const (
+	certUrl       = "https://testapi.taxrating.net/Services/Communications/V01/SureTax.asmx/PostRequest"
+	certCancelUrl = "https://testapi.taxrating.net/Services/Communications/V01/SureTax.asmx/CancelPostRequest"
+)
+
+func certConfig(t *testing.T) (*SuretaxClient, string, string) {
+	clientNumber, key := os.Getenv("SURETAX_CERT_CLIENT_NUMBER"), os.Getenv("SURETAX_CERT_VALIDATION_KEY")
+	if clientNumber == "" || key == "" {
+		t.Skip("SURETAX_CERT_CLIENT_NUMBER and SURETAX_CERT_VALIDATION_KEY not set")
+	}
+
+	cli := &SuretaxClient{Url: os.Getenv("SURETAX_CERT_URL"), CancelUrl: os.Getenv("SURETAX_CERT_CANCEL_URL")}
+	if cli.Url == "" {
+		cli.Url = certUrl
+	}
+	if cli.CancelUrl == "" {
+		cli.CancelUrl = certCancelUrl
+	}
+	return cli, clientNumber, key
+}
+
+func certRequest(clientNumber, key string) *Request {
+	now := time.Now()
+	req := getTestRequest()
+	req.ClientNumber, req.ValidationKey = clientNumber, key
+	req.DataYear, req.DataMonth = strconv.Itoa(now.Year()), now.Format("01")
+	req.CmplDataYear, req.CmplDataMonth = req.DataYear, req.DataMonth
+	req.ClientTracking = "conformance"
+	req.ItemList[0].TransDate = now.Format("01/02/2006")
+	return req
+}
+
+func Test_Conformance(t *testing.T) {
+
+	cli, clientNumber, key := certConfig(t)
+	SetHttpClient(nil)
+
+	var transId int
+
+	t.Run("Success", func(t *testing.T) {
+		res, err := cli.Send(certRequest(clientNumber, key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.ResponseCode != "9999" || res.Successful != "Y" || res.TransId == 0 {
+			t.Fatalf("Expected a successful response but got %v %v %v: %v", res.ResponseCode, res.Successful, res.TransId, res.HeaderMessage)
+		}
+		if _, err := parseAmount(res.TotalTax); err != nil {
+			t.Fatalf("Expected a parsable TotalTax but got %v", res.TotalTax)
+		}
+		if len(res.GroupList) == 0 || lineKey(res.GroupList[0].LineNumber) != "1" || len(res.GroupList[0].TaxList) == 0 {
+			t.Fatalf("Expected the taxes of line 1 but got %+v", res.GroupList)
+		}
+		if res.ClientTracking != "conformance" {
+			t.Fatalf("Expected ClientTracking conformance but got %v", res.ClientTracking)
+		}
+		transId = res.TransId
+	})
+
+	t.Run("ItemErrors", func(t *testing.T) {
+		req := certRequest(clientNumber, key)
+		bad := req.ItemList[0]
+		bad.LineNumber, bad.TransTypeCode = "02", "999999"
+		req.ItemList = append(req.ItemList, bad)
+		req.TotalRevenue = "200"
+
+		res, err := cli.Send(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.ResponseCode != "9001" || res.Successful != "Y" {
+			t.Fatalf("Expected 9001 but got %v: %v", res.ResponseCode, res.HeaderMessage)
+		}
+		if len(res.ItemMessages) == 0 || lineKey(res.ItemMessages[0].LineNumber) != "2" {
+			t.Fatalf("Expected an item message for line 2 but got %+v", res.ItemMessages)
+		}
+		if !IsItemError(res.Err()) {
+			t.Fatalf("Expected an item error but got %v", res.Err())
+		}
+		if transId == 0 {
+			transId = res.TransId
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		if transId == 0 {
+			t.Skip("No transaction to cancel")
+		}
+		req := &CancelRequest{ClientNumber: clientNumber, ValidationKey: key, TransId: strconv.Itoa(transId)}
+
+		res, err := cli.Cancel(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Outcome() != CancelOutcomeCancelled {
+			t.Fatalf("Expected the transaction to be cancelled but got %v: %v", res.ResponseCode, res.HeaderMessage)
+		}
+
+		res, err = cli.Cancel(req)
+		if res == nil || res.Outcome() != CancelOutcomeAlreadyCancelled {
+			t.Fatalf("Expected the transaction to be already cancelled but got %v", err)
+		}
+	})
+
+	t.Run("CancelTooOld", func(t *testing.T) {
+		old := os.Getenv("SURETAX_CERT_OLD_TRANSID")
+		if old == "" {
+			t.Skip("SURETAX_CERT_OLD_TRANSID not set")
+		}
+
+		res, err := cli.Cancel(&CancelRequest{ClientNumber: clientNumber, ValidationKey: key, TransId: old})
+		if res == nil || res.Outcome() != CancelOutcomeTooOld {
+			t.Fatalf("Expected the transaction to be too old to cancel but got %v", err)
+		}
+		if !IsValidationError(err) {
+			t.Fatalf("Expected a non-retryable error but got %v", err)
+		}
+	})
+}
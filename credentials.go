@@ -0,0 +1,124 @@
+package suretax
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SureTax account the requests of a client are sent for. Held as SuretaxClient.Credentials, it's set on
+// every Request and CancelRequest the client sends, so payloads built by the application carry no secrets.
+type Credentials struct {
+	ClientNumber  string
+	ValidationKey string
+
+	// Optional default BusinessUnit of the requests.
+	BusinessUnit string
+}
+
+// Sets the fields of req that are empty.
+func (c Credentials) Apply(req *Request) {
+	if req.ClientNumber == "" {
+		req.ClientNumber = c.ClientNumber
+	}
+	if req.ValidationKey == "" {
+		req.ValidationKey = c.ValidationKey
+	}
+	if req.BusinessUnit == "" {
+		req.BusinessUnit = c.BusinessUnit
+	}
+}
+
+// Sets the credentials of req that are empty.
+func (c Credentials) ApplyCancel(req *CancelRequest) {
+	if req.ClientNumber == "" {
+		req.ClientNumber = c.ClientNumber
+	}
+	if req.ValidationKey == "" {
+		req.ValidationKey = c.ValidationKey
+	}
+}
+
+// Source of the client credentials, consulted on every call, e.g. a secrets manager holding
+// a validation key that is rotated while the service runs. Must be safe for concurrent use.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// Adapts a function to a CredentialProvider.
+type CredentialProviderFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// CredentialProvider caching the credentials of a slower one, e.g. Vault or AWS Secrets Manager, for TTL.
+// A client invalidates it when SureTax rejects the credentials, so a rotated key is fetched on the next call.
+// When a refresh fails, the previous credentials are kept until they are invalidated.
+// Safe for concurrent use.
+type CachedCredentials struct {
+	provider CredentialProvider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	creds   Credentials
+	fetched time.Time
+	valid   bool
+	now     func() time.Time
+}
+
+// Returns a provider caching the credentials of p for ttl.
+func NewCachedCredentials(p CredentialProvider, ttl time.Duration) *CachedCredentials {
+	return &CachedCredentials{provider: p, ttl: ttl, now: time.Now}
+}
+
+func (c *CachedCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.valid && c.now().Sub(c.fetched) < c.ttl {
+		return c.creds, nil
+	}
+
+	creds, err := c.provider.Credentials(ctx)
+	if err != nil {
+		if c.valid {
+			logger.Warn("Credentials refresh failed, keeping the previous ones", "Error", err)
+			return c.creds, nil
+		}
+		return Credentials{}, err
+	}
+
+	c.creds, c.fetched, c.valid = creds, c.now(), true
+	return creds, nil
+}
+
+// Drops the cached credentials, so the next call fetches them from the provider.
+func (c *CachedCredentials) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.valid = false
+}
+
+// Returns the credentials of a call: those of the CredentialProvider if set, the client Credentials otherwise.
+func (c *SuretaxClient) credentials(ctx context.Context) (Credentials, error) {
+	if c.CredentialProvider == nil {
+		return c.Credentials, nil
+	}
+	creds, err := c.CredentialProvider.Credentials(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("Credentials unavailable: %w", err)
+	}
+	return creds, nil
+}
+
+// Invalidates the credentials of a provider that caches them once SureTax has rejected them.
+func (c *SuretaxClient) credentialsRejected(err error) {
+	if err == nil || !IsAuthError(err) {
+		return
+	}
+	if inv, ok := c.CredentialProvider.(interface{ Invalidate() }); ok {
+		inv.Invalidate()
+	}
+}
@@ -0,0 +1,98 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_Credentials(t *testing.T) {
+
+	var sent *Request
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		sent = decodeTestRequest(t, r)
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)), nil
+	})
+	creds := Credentials{ClientNumber: "000000002", ValidationKey: "key", BusinessUnit: "retail"}
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport), WithCredentials(creds))
+
+	req := getTestRequest()
+	req.ClientNumber, req.ValidationKey, req.BusinessUnit = "", "", ""
+	if _, err := cli.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	if sent.ClientNumber != "000000002" || sent.ValidationKey != "key" || sent.BusinessUnit != "retail" {
+		t.Fatalf("Expected the client credentials but got %+v", sent)
+	}
+
+	req = getTestRequest()
+	req.BusinessUnit = "wholesale"
+	if _, err := cli.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	if sent.ClientNumber != "000000001" || sent.BusinessUnit != "wholesale" {
+		t.Fatalf("Expected the request values to be kept but got %+v", sent)
+	}
+
+	p := Profile{Url: "https://cert/post", ClientNumber: "000000003", ValidationKey: "other"}
+	if c := p.Client(); c.Credentials.ClientNumber != "000000003" || c.Credentials.ValidationKey != "other" {
+		t.Fatalf("Expected the profile credentials on the client but got %+v", c.Credentials)
+	}
+}
+
+func Test_CachedCredentials(t *testing.T) {
+
+	keys := []string{"old", "new"}
+	var fetches int
+	provider := CredentialProviderFunc(func(ctx context.Context) (Credentials, error) {
+		fetches++
+		if len(keys) == 0 {
+			return Credentials{}, errors.New("vault sealed")
+		}
+		key := keys[0]
+		keys = keys[1:]
+		return Credentials{ClientNumber: "000000001", ValidationKey: key}, nil
+	})
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	cached := NewCachedCredentials(provider, time.Hour)
+	cached.now = func() time.Time { return now }
+
+	var sent []string
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		key := decodeTestRequest(t, r).ValidationKey
+		sent = append(sent, key)
+		if key == "old" {
+			return okResponse(envelope(`{"ResponseCode":"1151","Successful":"N","HeaderMessage":"Failure - Invalid Validation Key"}`)), nil
+		}
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)), nil
+	})
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport), WithCredentialProvider(cached))
+
+	newRequest := func() *Request {
+		req := getTestRequest()
+		req.ValidationKey = ""
+		return req
+	}
+
+	if _, err := cli.Send(newRequest()); !IsAuthError(err) {
+		t.Fatalf("Expected the old key to be rejected but got %v", err)
+	}
+	if _, err := cli.Send(newRequest()); err != nil {
+		t.Fatalf("Expected the rotated key to be fetched after the rejection but got %v", err)
+	}
+	if _, err := cli.Send(newRequest()); err != nil || fetches != 2 {
+		t.Fatalf("Expected the cached key to be reused but got %v after %v fetches", err, fetches)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := cli.Send(newRequest()); err != nil || fetches != 3 || sent[3] != "new" {
+		t.Fatalf("Expected the previous key to be kept when the refresh fails but got %v, %v", err, sent)
+	}
+
+	cached.Invalidate()
+	if _, err := cli.Send(newRequest()); err == nil || len(sent) != 4 {
+		t.Fatalf("Expected the call to fail without credentials but got %v", err)
+	}
+}
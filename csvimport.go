@@ -0,0 +1,164 @@
+package suretax
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Reads request items from CSV with a header row. mapping maps column headers to RequestItem field paths,
+// e.g. "zip" to "Address.PostalCode"; columns it doesn't name are ignored. With a nil mapping the headers
+// must be field paths themselves. TaxExemptionCodeList values are separated by "|".
+func ReadItemsCSV(r io.Reader, mapping map[string]string) ([]RequestItem, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Reading CSV header failed. Error: %v", err)
+	}
+
+	setters := make([]func(*RequestItem, string), len(header))
+	for i, col := range header {
+		col = strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))
+		path := col
+		if mapping != nil {
+			if path = mapping[col]; path == "" {
+				continue
+			}
+		}
+		set, err := itemFieldSetter(path)
+		if err != nil {
+			return nil, fmt.Errorf("CSV column %s: %v", col, err)
+		}
+		setters[i] = set
+	}
+	for col, path := range mapping {
+		if _, err := itemFieldSetter(path); err != nil {
+			return nil, fmt.Errorf("CSV mapping of %s: %v", col, err)
+		}
+	}
+
+	var items []RequestItem
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		item := RequestItem{}
+		for i, v := range record {
+			if i < len(setters) && setters[i] != nil {
+				setters[i](&item, strings.TrimSpace(v))
+			}
+		}
+		items = append(items, item)
+	}
+}
+
+var (
+	requestItemType = reflect.TypeOf(RequestItem{})
+	stringSliceType = reflect.TypeOf([]string(nil))
+)
+
+// Sets the fields at the paths of defaults, e.g. "TaxSitusRule" to "04", on the items where they are empty.
+func ApplyItemDefaults(items []RequestItem, defaults map[string]string) error {
+	for path, value := range defaults {
+		set, err := itemFieldSetter(path)
+		if err != nil {
+			return err
+		}
+		get, _ := itemFieldGetter(path)
+		for i := range items {
+			if get(&items[i]) == "" {
+				set(&items[i], value)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the index path of the RequestItem field at path, e.g. "Revenue" or "Address.PostalCode".
+func itemFieldIndex(path string) ([][]int, error) {
+	t := requestItemType
+	var index [][]int
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%s is not a field of RequestItem", path)
+		}
+		sf, ok := t.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+		if !ok || sf.PkgPath != "" {
+			return nil, fmt.Errorf("%s is not a field of RequestItem", path)
+		}
+		index = append(index, sf.Index)
+		t = sf.Type
+	}
+	if t.Kind() != reflect.String && t != stringSliceType {
+		return nil, fmt.Errorf("%s is not a text field", path)
+	}
+	return index, nil
+}
+
+// Returns the field at index of item. With alloc, nil pointers on the way are allocated,
+// otherwise an invalid Value is returned for them.
+func itemField(item *RequestItem, index [][]int, alloc bool) reflect.Value {
+	v := reflect.ValueOf(item).Elem()
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByIndex(i)
+	}
+	return v
+}
+
+// Returns a function setting the RequestItem field at path to a non-empty value.
+func itemFieldSetter(path string) (func(*RequestItem, string), error) {
+	index, err := itemFieldIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(item *RequestItem, value string) {
+		if value == "" {
+			return
+		}
+		v := itemField(item, index, true)
+		if v.Kind() == reflect.String {
+			v.SetString(value)
+			return
+		}
+		v.Set(reflect.ValueOf(strings.Split(value, "|")))
+	}, nil
+}
+
+// Returns a function reading the RequestItem field at path, list values joined with "|".
+func itemFieldGetter(path string) (func(*RequestItem) string, error) {
+	index, err := itemFieldIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(item *RequestItem) string {
+		v := itemField(item, index, false)
+		switch {
+		case !v.IsValid():
+			return ""
+		case v.Kind() == reflect.String:
+			return v.String()
+		}
+		return strings.Join(v.Interface().([]string), "|")
+	}, nil
+}
@@ -0,0 +1,53 @@
+package suretax
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_ReadItemsCSV(t *testing.T) {
+
+	data := "LineNumber,Revenue,address.postalcode,TaxExemptionCodeList,ServiceAddress.State\n" +
+		"1, 10.50 ,60601,00|01,IL\n" +
+		"2,5,,,\n"
+
+	items, err := ReadItemsCSV(strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Revenue != "10.50" || items[0].Address.PostalCode != "60601" || len(items[0].TaxExemptionCodeList) != 2 {
+		t.Fatalf("Expected the items from the CSV but got %+v", items)
+	}
+	if items[0].ServiceAddress == nil || items[0].ServiceAddress.State != "IL" || items[1].ServiceAddress != nil {
+		t.Fatalf("Expected ServiceAddress only where set but got %+v, %+v", items[0].ServiceAddress, items[1].ServiceAddress)
+	}
+
+	mapped, err := ReadItemsCSV(strings.NewReader("cdr_id,amount,unused\nA1,3.00,x\n"), map[string]string{"cdr_id": "InvoiceNumber", "amount": "Revenue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapped[0].InvoiceNumber != "A1" || mapped[0].Revenue != "3.00" {
+		t.Fatalf("Expected the mapped columns but got %+v", mapped[0])
+	}
+
+	if _, err := ReadItemsCSV(strings.NewReader("Bogus\n1\n"), nil); err == nil {
+		t.Fatal("Expected an error for an unknown column")
+	}
+	if _, err := ReadItemsCSV(strings.NewReader("a\n1\n"), map[string]string{"a": "Address"}); err == nil {
+		t.Fatal("Expected an error mapping to a non-text field")
+	}
+}
+
+func Test_ApplyItemDefaults(t *testing.T) {
+
+	items := []RequestItem{{TaxSitusRule: "05"}, {}}
+	if err := ApplyItemDefaults(items, map[string]string{"TaxSitusRule": "04", "ServiceAddress.Country": "US"}); err != nil {
+		t.Fatal(err)
+	}
+	if items[0].TaxSitusRule != "05" || items[1].TaxSitusRule != "04" || items[1].ServiceAddress == nil || items[1].ServiceAddress.Country != "US" {
+		t.Fatalf("Expected the defaults on empty fields only but got %+v", items)
+	}
+	if err := ApplyItemDefaults(items, map[string]string{"Nope": "1"}); err == nil {
+		t.Fatal("Expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,97 @@
+package suretax
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layout of the dates set from a time.Time, one of the formats SureTax accepts.
+type DateFormat string
+
+const (
+	// MM/DD/YYYY
+	DateFormatUS DateFormat = "01/02/2006"
+
+	// YYYY-MM-DDTHH:MM:SS
+	DateFormatISO DateFormat = "2006-01-02T15:04:05"
+)
+
+// Formats t with f, DateFormatUS if f is empty.
+func formatDate(t time.Time, f DateFormat) string {
+	if f == "" {
+		f = DateFormatUS
+	}
+	return t.Format(string(f))
+}
+
+// Parses a date in any of the formats SureTax accepts. Empty strings are the zero time.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range transDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid date %q", s)
+}
+
+// Sets TransDate to t formatted with f.
+func (item *RequestItem) SetTransDate(t time.Time, f DateFormat) {
+	item.TransDate = formatDate(t, f)
+}
+
+// Returns TransDate as a time. A missing TransDate is the zero time.
+func (item *RequestItem) TransTime() (time.Time, error) {
+	return parseDate(item.TransDate)
+}
+
+// Sets BillingPeriodStartDate and BillingPeriodEndDate to start and end formatted with f.
+func (item *RequestItem) SetBillingPeriod(start, end time.Time, f DateFormat) {
+	item.BillingPeriodStartDate = formatDate(start, f)
+	item.BillingPeriodEndDate = formatDate(end, f)
+}
+
+// Returns BillingPeriodStartDate and BillingPeriodEndDate as times. Missing dates are the zero time.
+func (item *RequestItem) BillingPeriod() (start, end time.Time, err error) {
+	if start, err = parseDate(item.BillingPeriodStartDate); err != nil {
+		return
+	}
+	end, err = parseDate(item.BillingPeriodEndDate)
+	return
+}
+
+// Sets DataYear/DataMonth and CmplDataYear/CmplDataMonth to the year and month of t,
+// so taxes are calculated and recorded for remittance in the same period.
+func (r *Request) SetDataPeriod(t time.Time) {
+	r.DataYear, r.DataMonth = t.Format("2006"), t.Format("01")
+	r.SetCmplDataPeriod(t)
+}
+
+// Sets CmplDataYear and CmplDataMonth to the year and month of t, for recording the
+// tax calculations in a remittance period other than DataYear/DataMonth.
+func (r *Request) SetCmplDataPeriod(t time.Time) {
+	r.CmplDataYear, r.CmplDataMonth = t.Format("2006"), t.Format("01")
+}
+
+// Returns the first day of DataYear/DataMonth in UTC.
+func (r *Request) DataPeriod() (time.Time, error) {
+	return parsePeriod(r.DataYear, r.DataMonth)
+}
+
+// Returns the first day of CmplDataYear/CmplDataMonth in UTC.
+func (r *Request) CmplDataPeriod() (time.Time, error) {
+	return parsePeriod(r.CmplDataYear, r.CmplDataMonth)
+}
+
+func parsePeriod(year, month string) (time.Time, error) {
+	if len(month) == 1 {
+		month = "0" + month
+	}
+	t, err := time.Parse("2006-01", year+"-"+month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid period %q/%q", year, month)
+	}
+	return t, nil
+}
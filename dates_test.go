@@ -0,0 +1,47 @@
+package suretax
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Dates(t *testing.T) {
+
+	day := time.Date(2026, 3, 9, 14, 30, 0, 0, time.UTC)
+
+	item := &RequestItem{}
+	item.SetTransDate(day, DateFormatISO)
+	if item.TransDate != "2026-03-09T14:30:00" {
+		t.Fatalf("Expected an ISO date but got %v", item.TransDate)
+	}
+	if tt, err := item.TransTime(); err != nil || !tt.Equal(day) {
+		t.Fatalf("Expected %v but got %v, %v", day, tt, err)
+	}
+
+	item.SetBillingPeriod(day.AddDate(0, 0, -8), day, "")
+	if item.BillingPeriodStartDate != "03/01/2026" || item.BillingPeriodEndDate != "03/09/2026" {
+		t.Fatalf("Expected MM/DD/YYYY dates but got %v and %v", item.BillingPeriodStartDate, item.BillingPeriodEndDate)
+	}
+	if start, _, err := item.BillingPeriod(); err != nil || start.Day() != 1 {
+		t.Fatalf("Expected the billing period to start on the 1st but got %v, %v", start, err)
+	}
+
+	item.TransDate = "2026/03/09"
+	if _, err := item.TransTime(); err == nil {
+		t.Fatal("Expected an unsupported date format to fail")
+	}
+
+	req := &Request{}
+	req.SetDataPeriod(day)
+	if req.DataYear != "2026" || req.DataMonth != "03" || req.CmplDataYear != "2026" || req.CmplDataMonth != "03" {
+		t.Fatalf("Expected 2026/03 for both periods but got %+v", req)
+	}
+	req.DataMonth = "3"
+	if p, err := req.DataPeriod(); err != nil || p.Month() != time.March {
+		t.Fatalf("Expected March but got %v, %v", p, err)
+	}
+	req.CmplDataMonth = "13"
+	if _, err := req.CmplDataPeriod(); err == nil {
+		t.Fatal("Expected an invalid month to fail")
+	}
+}
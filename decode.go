@@ -0,0 +1,185 @@
+package suretax
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+type decodeOptions struct {
+	// goroutines decoding the GroupList
+	workers int
+
+	// keep the GroupList JSON and decode it on demand
+	lazy bool
+}
+
+// JSON names of the exported fields of a struct type mapped to their index.
+type jsonFields struct {
+	names []string
+	index map[string]int
+}
+
+func newJsonFields(t reflect.Type) jsonFields {
+	f := jsonFields{names: make([]string, t.NumField()), index: make(map[string]int)}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Name
+		if tag := sf.Tag.Get("json"); tag != "" {
+			if tag == "-" || sf.PkgPath != "" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		} else if sf.PkgPath != "" {
+			continue
+		}
+		f.names[i] = name
+		f.index[name] = i
+	}
+	return f
+}
+
+// Returns the index of the field for key, matching case-insensitively like encoding/json, or -1.
+func (f jsonFields) lookup(key string) int {
+	if i, ok := f.index[key]; ok {
+		return i
+	}
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(name, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+var responseFields = newJsonFields(reflect.TypeOf(Response{}))
+
+// Decodes the inner response JSON token by token into res.
+// GroupList is sized up front and the TaxLists of all groups share one backing array,
+// so a large response costs a handful of large allocations instead of growing a slice per group.
+func decodeResponseJSON(inner string, res *Response, opts decodeOptions) error {
+	if opts.lazy {
+		if ok, err := decodeHeader(inner, res, opts.workers); ok {
+			return err
+		}
+	}
+
+	dec := json.NewDecoder(strings.NewReader(inner))
+
+	return decodeObject(dec, func(key string) error {
+		if strings.EqualFold(key, "GroupList") {
+			return decodeGroups(dec, inner, res, opts.workers)
+		}
+		return decodeField(dec, reflect.ValueOf(res).Elem(), responseFields, key)
+	})
+}
+
+// With more than one worker the GroupList is split into its elements, which are decoded in parallel.
+func decodeGroups(dec *json.Decoder, inner string, res *Response, workers int) error {
+	if workers > 1 {
+		return decodeGroupListParallel(dec, res, workers)
+	}
+	return decodeGroupList(dec, inner, res)
+}
+
+func decodeGroupList(dec *json.Decoder, inner string, res *Response) error {
+	ok, err := openArray(dec, "GroupList")
+	if !ok {
+		res.GroupList = nil
+		return err
+	}
+
+	// Quoted keys can't occur inside string values, where quotes are escaped,
+	// so these are exact unless a value happens to equal the key name.
+	groups := res.groupBuffer(strings.Count(inner, `"TaxList"`))[:0]
+	taxes := res.taxBuffer(strings.Count(inner, `"TaxTypeCode"`))
+
+	for dec.More() {
+		groups = append(groups, Group{})
+		g := &groups[len(groups)-1]
+
+		g.TaxList = taxes[:0]
+		if err := dec.Decode(g); err != nil {
+			return err
+		}
+		taxes = claimTaxes(g, taxes)
+	}
+	res.GroupList = groups
+
+	_, err = dec.Token()
+	return err
+}
+
+// Called after decoding g with its TaxList set to taxes[:0]. encoding/json appends array elements
+// to a non-nil slice in place, so the TaxList fills the head of taxes. Returns the unused remainder.
+func claimTaxes(g *Group, taxes []Tax) []Tax {
+	n := len(g.TaxList)
+	if n > 0 && len(taxes) > 0 && &g.TaxList[0] == &taxes[0] {
+		taxes = taxes[n:]
+	}
+	if g.TaxList != nil {
+		// keeps appends to one group's TaxList from overwriting the next
+		g.TaxList = g.TaxList[:n:n]
+	}
+	return taxes
+}
+
+// Reads an object, calling field with every key while the decoder is positioned at the value.
+// A JSON null is accepted and leaves the target untouched.
+func decodeObject(dec *json.Decoder, field func(key string) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("Expected object but got %v", tok)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := field(tok.(string)); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Consumes the opening bracket of an array. Returns false without an error for a JSON null.
+func openArray(dec *json.Decoder, name string) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	if tok == nil {
+		return false, nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return false, fmt.Errorf("Expected %s array but got %v", name, tok)
+	}
+	return true, nil
+}
+
+// Decodes the value for key into the matching field of v, skipping unknown keys.
+func decodeField(dec *json.Decoder, v reflect.Value, fields jsonFields, key string) error {
+	i := fields.lookup(key)
+	if i < 0 {
+		var skip json.RawMessage
+		return dec.Decode(&skip)
+	}
+	return dec.Decode(v.Field(i).Addr().Interface())
+}
@@ -0,0 +1,137 @@
+package suretax
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func Test_decodeResponseJSON(t *testing.T) {
+
+	inner := decodeTestInner(t)
+
+	expected := &Response{}
+	if err := json.Unmarshal([]byte(inner), expected); err != nil {
+		t.Fatal(err)
+	}
+
+	res := &Response{}
+	if err := decodeResponseJSON(inner, res, decodeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(withoutBuffers(res), expected) {
+		t.Fatalf("Expected %+v but got %+v", expected, res)
+	}
+}
+
+func Test_decodeResponseJSON_EdgeCases(t *testing.T) {
+
+	inputs := []string{
+		`{"ResponseCode":"9999","GroupList":null}`,
+		`{"ResponseCode":"9999","GroupList":[]}`,
+		`{"ResponseCode":"9999","GroupList":[{"LineNumber":"1","TaxList":[]},{"LineNumber":"2","TaxList":null}]}`,
+		`{"responsecode":"9999","grouplist":[{"linenumber":"1","taxlist":[{"taxamount":"1.00"}]}]}`,
+		`{"Unknown":{"a":[1,2]},"GroupList":[{"Extra":true,"TaxList":[{"TaxTypeCode":"035","Extra":1}]}],"TransId":5}`,
+		`{"ItemMessages":[{"LineNumber":"1","Message":"TaxList","ResponseCode":"9131"}],"GroupList":[{"TaxList":[{"TaxTypeCode":"035"},{"TaxTypeCode":"060"}]},{"TaxList":[{"TaxTypeCode":"127"}]}]}`,
+	}
+
+	for _, in := range inputs {
+		expected := &Response{}
+		if err := json.Unmarshal([]byte(in), expected); err != nil {
+			t.Fatal(err)
+		}
+
+		res := &Response{}
+		if err := decodeResponseJSON(in, res, decodeOptions{}); err != nil {
+			t.Fatalf("Expected no error for %s but got %v", in, err)
+		}
+
+		if !reflect.DeepEqual(withoutBuffers(res), expected) {
+			t.Fatalf("Expected %+v but got %+v for %s", expected, res, in)
+		}
+	}
+}
+
+func Test_decodeResponseJSON_Invalid(t *testing.T) {
+
+	inputs := []string{
+		``,
+		`[]`,
+		`{"GroupList":{}}`,
+		`{"GroupList":[{"TaxList":"x"}]}`,
+		`{"GroupList":[{"TaxList":[{"TaxRate":"x"}]}]}`,
+		`{"ResponseCode":"9999"`,
+	}
+
+	for _, in := range inputs {
+		if err := decodeResponseJSON(in, &Response{}, decodeOptions{}); err == nil {
+			t.Fatalf("Expected an error for %s", in)
+		}
+	}
+}
+
+// Returns a copy of r without the unexported decoding state, for comparison with json.Unmarshal.
+func withoutBuffers(r *Response) *Response {
+	c := *r
+	c.lazy = nil
+	c.taxes = nil
+	return &c
+}
+
+func decodeTestInner(t testing.TB) string {
+	body, err := ioutil.ReadAll(getTestResponse().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	respw := ResponseWrapper{}
+	if err := json.Unmarshal(body, &respw); err != nil {
+		t.Fatal(err)
+	}
+	return respw.D
+}
+
+func getLargeTestInner(b *testing.B, groups int) string {
+	res := &Response{}
+	if err := json.Unmarshal([]byte(decodeTestInner(b)), res); err != nil {
+		b.Fatal(err)
+	}
+
+	group := res.GroupList[0]
+	res.GroupList = make([]Group, groups)
+	for i := range res.GroupList {
+		res.GroupList[i] = group
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return string(data)
+}
+
+func Benchmark_decodeResponseJSON_10k(b *testing.B) {
+	inner := getLargeTestInner(b, 10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := decodeResponseJSON(inner, &Response{}, decodeOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_unmarshalResponse_10k(b *testing.B) {
+	inner := getLargeTestInner(b, 10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := json.Unmarshal([]byte(inner), &Response{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// Smallest number of groups worth handing to a separate goroutine.
+const minGroupsPerWorker = 256
+
+func decodeGroupListParallel(dec *json.Decoder, res *Response, workers int) error {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	if raw[0] != '[' {
+		// null, or a type mismatch reported like the sequential decoder does
+		return json.Unmarshal(raw, &res.GroupList)
+	}
+
+	elems := splitArray(raw)
+	groups := res.groupBuffer(len(elems))
+
+	if max := len(elems) / minGroupsPerWorker; workers > max {
+		workers = max
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	size := (len(elems) + workers - 1) / workers
+	errs := make([]error, workers)
+
+	// every worker gets its own part of one shared Tax array
+	counts := make([]int, workers)
+	total := 0
+	for w := range counts {
+		for _, e := range elems[min(w*size, len(elems)):min((w+1)*size, len(elems))] {
+			counts[w] += bytes.Count(e, []byte(`"TaxTypeCode"`))
+		}
+		total += counts[w]
+	}
+	taxes := res.taxBuffer(total)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo, hi := min(w*size, len(elems)), min((w+1)*size, len(elems))
+
+		wg.Add(1)
+		go func(w int, taxes []Tax) {
+			defer wg.Done()
+			errs[w] = decodeGroupElems(elems[lo:hi], groups[lo:hi], taxes)
+		}(w, taxes[:counts[w]:counts[w]])
+		taxes = taxes[counts[w]:]
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	res.GroupList = groups
+	return nil
+}
+
+// Decodes each element into the matching group, filling the TaxLists from taxes.
+func decodeGroupElems(elems [][]byte, groups []Group, taxes []Tax) error {
+	for i, e := range elems {
+		g := &groups[i]
+		g.TaxList = taxes[:0]
+		if err := json.Unmarshal(e, g); err != nil {
+			return err
+		}
+		taxes = claimTaxes(g, taxes)
+	}
+	return nil
+}
+
+// Returns the top-level elements of the valid JSON array raw.
+func splitArray(raw []byte) [][]byte {
+	var elems [][]byte
+
+	depth, start := 0, -1
+	inString, escaped := false, false
+
+	for i, b := range raw {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '"':
+			inString = true
+		case '[', '{':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				if start >= 0 {
+					elems = append(elems, bytes.TrimSpace(raw[start:i]))
+				}
+				return elems
+			}
+		case ',':
+			if depth == 1 {
+				elems = append(elems, bytes.TrimSpace(raw[start:i]))
+				start = -1
+				continue
+			}
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	return elems
+}
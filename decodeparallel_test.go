@@ -0,0 +1,101 @@
+package suretax
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func Test_decodeResponseJSON_Parallel(t *testing.T) {
+
+	res := &Response{ResponseCode: "9999", TransId: 42}
+	for i := 0; i < 2000; i++ {
+		g := Group{LineNumber: fmt.Sprint(i), InvoiceNumber: fmt.Sprintf(`INV "%d" [x]`, i)}
+		for j := 0; j < i%4; j++ {
+			g.TaxList = append(g.TaxList, Tax{TaxTypeCode: fmt.Sprint(j), TaxAmount: `1\2{}`, TaxRate: 0.1})
+		}
+		res.GroupList = append(res.GroupList, g)
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Response{}
+	if err := json.Unmarshal(data, expected); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{2, 3, 8} {
+		actual := &Response{}
+		if err := decodeResponseJSON(string(data), actual, decodeOptions{workers: workers}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(withoutBuffers(actual), expected) {
+			t.Fatalf("Expected parallel decoding with %d workers to match json.Unmarshal", workers)
+		}
+	}
+}
+
+func Test_decodeResponseJSON_ParallelEdgeCases(t *testing.T) {
+
+	inputs := []string{
+		`{"ResponseCode":"9999","GroupList":null}`,
+		`{"ResponseCode":"9999","GroupList":[]}`,
+		`{"ResponseCode":"9999","GroupList": [ {"LineNumber":"1","TaxList":[]} , {"LineNumber":"2","TaxList":null} ] }`,
+		`{"GroupList":[{"TaxList":[{"TaxTypeCode":"035"},{"TaxTypeCode":"060"}]},{"TaxList":[{"TaxTypeCode":"127"}]}]}`,
+	}
+
+	for _, in := range inputs {
+		expected := &Response{}
+		if err := json.Unmarshal([]byte(in), expected); err != nil {
+			t.Fatal(err)
+		}
+
+		res := &Response{}
+		if err := decodeResponseJSON(in, res, decodeOptions{workers: 4}); err != nil {
+			t.Fatalf("Expected no error for %s but got %v", in, err)
+		}
+
+		if !reflect.DeepEqual(withoutBuffers(res), expected) {
+			t.Fatalf("Expected %+v but got %+v for %s", expected, res, in)
+		}
+	}
+
+	for _, in := range []string{`{"GroupList":{}}`, `{"GroupList":[{"TaxList":"x"}]}`} {
+		if err := decodeResponseJSON(in, &Response{}, decodeOptions{workers: 4}); err == nil {
+			t.Fatalf("Expected an error for %s", in)
+		}
+	}
+}
+
+func Test_splitArray(t *testing.T) {
+
+	elems := splitArray([]byte(` [ {"a":"],\"{"} ,[1,[2]], "x" ,3 ] `))
+
+	expected := []string{`{"a":"],\"{"}`, `[1,[2]]`, `"x"`, `3`}
+	if len(elems) != len(expected) {
+		t.Fatalf("Expected %d elements but got %d", len(expected), len(elems))
+	}
+	for i := range elems {
+		if string(elems[i]) != expected[i] {
+			t.Fatalf("Expected element %s but got %s", expected[i], elems[i])
+		}
+	}
+}
+
+func Benchmark_decodeResponseJSON_Parallel_10k(b *testing.B) {
+	inner := getLargeTestInner(b, 10000)
+	workers := runtime.GOMAXPROCS(0)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := decodeResponseJSON(inner, &Response{}, decodeOptions{workers: workers}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
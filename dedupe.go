@@ -0,0 +1,59 @@
+package suretax
+
+import (
+	"sync"
+	"time"
+)
+
+// Remembers keys for a limited time window. Used to detect reuse of STAN values.
+type DedupeStore interface {
+	// Records key for the given window. Returns false if key was already recorded
+	// and its window has not expired yet.
+	Add(key string, window time.Duration) (bool, error)
+
+	// Forgets key, so it may be added again.
+	Delete(key string) error
+}
+
+// In-memory DedupeStore. Safe for concurrent use.
+type MemoryDedupeStore struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+	adds int
+	now  func() time.Time
+}
+
+func NewMemoryDedupeStore() *MemoryDedupeStore {
+	return &MemoryDedupeStore{seen: make(map[string]time.Time), now: time.Now}
+}
+
+func (s *MemoryDedupeStore) Add(key string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	s.adds++
+	if s.adds%1024 == 0 {
+		for k, exp := range s.seen {
+			if !now.Before(exp) {
+				delete(s.seen, k)
+			}
+		}
+	}
+
+	if exp, ok := s.seen[key]; ok && now.Before(exp) {
+		return false, nil
+	}
+
+	s.seen[key] = now.Add(window)
+	return true, nil
+}
+
+func (s *MemoryDedupeStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.seen, key)
+	return nil
+}
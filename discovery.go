@@ -0,0 +1,210 @@
+package suretax
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint URLs of one environment and region listed in a discovery document.
+type DiscoveryEndpoint struct {
+	Environment string `json:"environment"`
+
+	// Empty for the entry used when no entry matches the region.
+	Region string `json:"region,omitempty"`
+
+	Url       string `json:"url"`
+	CancelUrl string `json:"cancelUrl"`
+}
+
+// Discovery document listing endpoint URLs by environment and region, e.g.
+//
+//	{"endpoints": [
+//	  {"environment": "production", "region": "us-east", "url": "https://...", "cancelUrl": "https://..."},
+//	  {"environment": "production", "url": "https://...", "cancelUrl": "https://..."}
+//	]}
+type DiscoveryDocument struct {
+	Endpoints []DiscoveryEndpoint `json:"endpoints"`
+}
+
+// Returns the entry for environment and region, matched case-insensitively, falling back to the
+// entry of the environment without a region.
+func (d *DiscoveryDocument) Lookup(environment, region string) (DiscoveryEndpoint, bool) {
+	var fallback *DiscoveryEndpoint
+	for i := range d.Endpoints {
+		e := &d.Endpoints[i]
+		if !strings.EqualFold(e.Environment, environment) {
+			continue
+		}
+		if strings.EqualFold(e.Region, region) {
+			return *e, true
+		}
+		if e.Region == "" && fallback == nil {
+			fallback = e
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return DiscoveryEndpoint{}, false
+}
+
+// Resolves the client URLs from a discovery document, so URL changes announced by CCH can be rolled out
+// by updating the document instead of redeploying. Set it as SuretaxClient.Discovery and call Refresh
+// once before sending, then Run to keep it current. Safe for concurrent use.
+type Discovery struct {
+	// Path of a local file or an http(s) URL of the document.
+	Source string
+
+	Environment string
+	Region      string
+
+	// Client fetching a remote document. Defaults to http.DefaultClient.
+	HttpClient HttpClient
+
+	// Optional callback receiving the endpoint whenever a refresh changes it.
+	OnChange func(DiscoveryEndpoint)
+
+	mu       sync.RWMutex
+	current  DiscoveryEndpoint
+	loaded   bool
+	loadedAt time.Time
+
+	now func() time.Time
+}
+
+// Returns a Discovery reading source for environment and region.
+func NewDiscovery(source, environment, region string) *Discovery {
+	return &Discovery{Source: source, Environment: environment, Region: region, now: time.Now}
+}
+
+// Loads the document and updates the endpoint. On failure the previous endpoint is kept.
+func (d *Discovery) Refresh(ctx context.Context) error {
+	doc, err := d.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	e, ok := doc.Lookup(d.Environment, d.Region)
+	if !ok {
+		return fmt.Errorf("Discovery document %s has no endpoint for %s %s", d.Source, d.Environment, d.Region)
+	}
+	if e.Url == "" || e.CancelUrl == "" {
+		return fmt.Errorf("Discovery document %s lists an incomplete endpoint for %s %s", d.Source, d.Environment, d.Region)
+	}
+
+	d.mu.Lock()
+	changed := !d.loaded || d.current != e
+	d.current, d.loaded = e, true
+	if d.now != nil {
+		d.loadedAt = d.now()
+	} else {
+		d.loadedAt = time.Now()
+	}
+	onChange := d.OnChange
+	d.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange(e)
+	}
+	return nil
+}
+
+// Refreshes the endpoint every interval until ctx is done. Failures are logged and the previous endpoint kept.
+func (d *Discovery) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := d.Refresh(ctx); err != nil {
+				logger.Warn("Discovery refresh failed", "Error", err)
+			}
+		}
+	}
+}
+
+// Returns the current endpoint and the time it was loaded, or false before the first successful Refresh.
+func (d *Discovery) Endpoint() (DiscoveryEndpoint, time.Time, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.current, d.loadedAt, d.loaded
+}
+
+func (d *Discovery) load(ctx context.Context) (*DiscoveryDocument, error) {
+	var body []byte
+	var err error
+	if strings.HasPrefix(d.Source, "http://") || strings.HasPrefix(d.Source, "https://") {
+		body, err = d.fetch(ctx)
+	} else {
+		body, err = os.ReadFile(d.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &DiscoveryDocument{}
+	if err := json.Unmarshal(body, doc); err != nil {
+		return nil, fmt.Errorf("Discovery document %s Unmarshal Failed. Error: %v", d.Source, err)
+	}
+	return doc, nil
+}
+
+func (d *Discovery) fetch(ctx context.Context) ([]byte, error) {
+	r, err := http.NewRequest("GET", d.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(ctx)
+
+	client := d.HttpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HttpError{resp.StatusCode, resp.Status}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Returns the post request url: the active Failover endpoint, or from Discovery when it has loaded an endpoint.
+func (c *SuretaxClient) postUrl() string {
+	if c.Failover != nil && len(c.Failover.Endpoints) > 0 {
+		return c.Failover.Active().Url
+	}
+	if c.Discovery != nil {
+		if e, _, ok := c.Discovery.Endpoint(); ok {
+			return e.Url
+		}
+	}
+	return c.Url
+}
+
+// Returns the cancel post request url, chosen like postUrl.
+func (c *SuretaxClient) cancelPostUrl() string {
+	if c.Failover != nil && len(c.Failover.Endpoints) > 0 {
+		return c.Failover.Active().CancelUrl
+	}
+	if c.Discovery != nil {
+		if e, _, ok := c.Discovery.Endpoint(); ok {
+			return e.CancelUrl
+		}
+	}
+	return c.CancelUrl
+}
@@ -0,0 +1,89 @@
+package suretax
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDiscoveryDocument = `{"endpoints": [
+	{"environment": "production", "region": "us-east", "url": "https://east/post", "cancelUrl": "https://east/cancel"},
+	{"environment": "production", "url": "https://default/post", "cancelUrl": "https://default/cancel"},
+	{"environment": "cert", "url": "https://cert/post"}
+]}`
+
+func Test_Discovery_File(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "discovery.json")
+	if err := os.WriteFile(path, []byte(testDiscoveryDocument), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []DiscoveryEndpoint
+	d := NewDiscovery(path, "PRODUCTION", "us-east")
+	d.OnChange = func(e DiscoveryEndpoint) { changes = append(changes, e) }
+
+	cli := &SuretaxClient{Url: "https://static/post", CancelUrl: "https://static/cancel", Discovery: d}
+	if cli.postUrl() != "https://static/post" {
+		t.Fatalf("Expected the static url before the first refresh but got %v", cli.postUrl())
+	}
+
+	if err := d.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if cli.postUrl() != "https://east/post" || cli.cancelPostUrl() != "https://east/cancel" {
+		t.Fatalf("Expected the regional urls but got %v %v", cli.postUrl(), cli.cancelPostUrl())
+	}
+
+	if err := d.Refresh(context.Background()); err != nil || len(changes) != 1 {
+		t.Fatalf("Expected a single change but got %v, %v", changes, err)
+	}
+
+	d.Region = "eu-west"
+	if err := d.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if cli.postUrl() != "https://default/post" || len(changes) != 2 {
+		t.Fatalf("Expected the environment default but got %v", cli.postUrl())
+	}
+
+	d.Environment = "cert"
+	if err := d.Refresh(context.Background()); err == nil {
+		t.Fatal("Expected an error for an incomplete endpoint")
+	}
+	d.Environment = "missing"
+	if err := d.Refresh(context.Background()); err == nil {
+		t.Fatal("Expected an error for an unknown environment")
+	}
+	if cli.postUrl() != "https://default/post" {
+		t.Fatalf("Expected the previous endpoint to be kept but got %v", cli.postUrl())
+	}
+}
+
+func Test_Discovery_Remote(t *testing.T) {
+
+	status := 200
+	d := NewDiscovery("https://config.example.com/suretax.json", "production", "")
+	d.HttpClient = httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method != "GET" || r.URL.Host != "config.example.com" {
+			t.Fatalf("Expected a GET of the document but got %v %v", r.Method, r.URL)
+		}
+		resp := okResponse(testDiscoveryDocument)
+		resp.StatusCode = status
+		return resp, nil
+	})
+
+	if err := d.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if e, _, ok := d.Endpoint(); !ok || e.Url != "https://default/post" {
+		t.Fatalf("Expected the default endpoint but got %+v", e)
+	}
+
+	status = 500
+	if err := d.Refresh(context.Background()); !IsTransient(err) {
+		t.Fatalf("Expected a transient HttpError but got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package suretax
+
+import "math/big"
+
+// Tax of a line or invoice relative to its revenue.
+type EffectiveRate struct {
+	// Empty for invoice and request totals.
+	LineNumber    string
+	InvoiceNumber string
+
+	Revenue string
+
+	// Sum of TaxAmount, tax on tax included.
+	Tax string
+
+	// Sum of TaxOnTax, the part of Tax levied on other taxes.
+	TaxOnTax string
+
+	// Tax / Revenue. Nil if Revenue is zero.
+	Rate *big.Rat
+
+	// (Tax - TaxOnTax) / Revenue, the rate levied on the revenue itself. Nil if Revenue is zero.
+	BaseRate *big.Rat
+}
+
+// Returns Rate as a percentage with the given decimal places, e.g. "12.35%". Empty if Rate is nil.
+func (e EffectiveRate) Percent(places int) string {
+	if e.Rate == nil {
+		return ""
+	}
+	return new(big.Rat).Mul(e.Rate, big.NewRat(100, 1)).FloatString(places) + "%"
+}
+
+// Effective rates of every line and invoice of a request, see EffectiveRates.
+type EffectiveRateReport struct {
+	// In request order
+	Lines []EffectiveRate
+
+	// Keyed by InvoiceNumber
+	Invoices map[string]EffectiveRate
+
+	Total EffectiveRate
+}
+
+type rateTotals struct {
+	revenue, tax, taxOnTax []string
+}
+
+func (t *rateTotals) rate(lineNumber, invoiceNumber string) (EffectiveRate, error) {
+	e := EffectiveRate{LineNumber: lineNumber, InvoiceNumber: invoiceNumber}
+
+	var err error
+	if e.Revenue, err = sumAmounts(t.revenue); err != nil {
+		return e, err
+	}
+	if e.Tax, err = sumAmounts(t.tax); err != nil {
+		return e, err
+	}
+	if e.TaxOnTax, err = sumAmounts(t.taxOnTax); err != nil {
+		return e, err
+	}
+
+	revenue, _ := parseAmount(e.Revenue)
+	if revenue.Sign() == 0 {
+		return e, nil
+	}
+	tax, _ := parseAmount(e.Tax)
+	taxOnTax, _ := parseAmount(e.TaxOnTax)
+	e.Rate = new(big.Rat).Quo(tax, revenue)
+	e.BaseRate = new(big.Rat).Quo(tax.Sub(tax, taxOnTax), revenue)
+	return e, nil
+}
+
+// Returns the effective tax rate of every item of req, of every invoice and of the whole request,
+// from the taxes of res, the response returned for req. Revenue is the item Revenue as sent, so for
+// items with TaxIncludedCode 1 the rate is relative to the tax-inclusive amount.
+func EffectiveRates(req *Request, res *Response) (*EffectiveRateReport, error) {
+	groups, err := res.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]rateTotals, len(req.ItemList))
+	index := make(map[string]int, len(req.ItemList))
+	for i, item := range req.ItemList {
+		lines[i].revenue = []string{item.Revenue}
+		index[lineKey(item.LineNumber)] = i
+	}
+	for _, g := range groups {
+		i, ok := index[lineKey(g.LineNumber)]
+		if !ok {
+			continue
+		}
+		for _, t := range g.TaxList {
+			lines[i].tax = append(lines[i].tax, t.TaxAmount)
+			lines[i].taxOnTax = append(lines[i].taxOnTax, t.TaxOnTax)
+		}
+	}
+
+	report := &EffectiveRateReport{Lines: make([]EffectiveRate, len(lines)), Invoices: map[string]EffectiveRate{}}
+	invoices := map[string]*rateTotals{}
+	var total rateTotals
+	for i, item := range req.ItemList {
+		l := &lines[i]
+		if report.Lines[i], err = l.rate(item.LineNumber, item.InvoiceNumber); err != nil {
+			return nil, err
+		}
+
+		inv, ok := invoices[item.InvoiceNumber]
+		if !ok {
+			inv = &rateTotals{}
+			invoices[item.InvoiceNumber] = inv
+		}
+		for _, t := range []*rateTotals{inv, &total} {
+			t.revenue = append(t.revenue, l.revenue...)
+			t.tax = append(t.tax, l.tax...)
+			t.taxOnTax = append(t.taxOnTax, l.taxOnTax...)
+		}
+	}
+
+	for number, inv := range invoices {
+		if report.Invoices[number], err = inv.rate("", number); err != nil {
+			return nil, err
+		}
+	}
+	if report.Total, err = total.rate("", ""); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
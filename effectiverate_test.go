@@ -0,0 +1,46 @@
+package suretax
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_EffectiveRates(t *testing.T) {
+
+	req := getTestRequest()
+	second := req.ItemList[0]
+	second.LineNumber, second.Revenue = "02", "50"
+	third := second
+	third.LineNumber, third.InvoiceNumber, third.Revenue = "03", "INV-003", "0"
+	req.ItemList = append(req.ItemList, second, third)
+
+	res := &Response{GroupList: []Group{
+		{LineNumber: "1", TaxList: []Tax{{TaxAmount: "10.00"}, {TaxAmount: "2.50", TaxOnTax: "0.50"}}},
+		{LineNumber: "2", TaxList: []Tax{{TaxAmount: "3.00"}}},
+		{LineNumber: "3", TaxList: []Tax{{TaxAmount: "1.00"}}},
+	}}
+
+	report, err := EffectiveRates(req, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := report.Lines[0]
+	if first.Tax != "12.50" || first.TaxOnTax != "0.50" || first.Rate.Cmp(big.NewRat(1, 8)) != 0 || first.BaseRate.Cmp(big.NewRat(12, 100)) != 0 {
+		t.Fatalf("Expected a 12.5%% rate with 12%% on the revenue but got %+v", first)
+	}
+	if p := first.Percent(1); p != "12.5%" {
+		t.Fatalf("Expected 12.5%% but got %v", p)
+	}
+	if report.Lines[2].Rate != nil || report.Lines[2].Percent(2) != "" {
+		t.Fatalf("Expected no rate for a zero revenue but got %+v", report.Lines[2])
+	}
+
+	inv := report.Invoices["INV-002"]
+	if inv.Revenue != "150" || inv.Tax != "15.50" || inv.Percent(2) != "10.33%" {
+		t.Fatalf("Expected the invoice rate to blend its lines but got %+v", inv)
+	}
+	if report.Total.Tax != "16.50" || report.Total.Percent(0) != "11%" {
+		t.Fatalf("Unexpected request total %+v", report.Total)
+	}
+}
@@ -0,0 +1,63 @@
+package suretax
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SureTax environment, selecting the request and cancel URLs of a client.
+type Environment string
+
+const (
+	// Certification (test) environment. Transactions are not billed.
+	EnvironmentCert Environment = "cert"
+
+	EnvironmentProduction Environment = "production"
+)
+
+type environmentUrls struct {
+	url       string
+	cancelUrl string
+}
+
+var environments = map[Environment]environmentUrls{
+	EnvironmentCert: {
+		"https://testapi.taxrating.net/Services/Communications/V01/SureTax.asmx/PostRequest",
+		"https://testapi.taxrating.net/Services/Communications/V01/SureTax.asmx/CancelPostRequest",
+	},
+	EnvironmentProduction: {
+		"https://api.taxrating.net/Services/Communications/V01/SureTax.asmx/PostRequest",
+		"https://api.taxrating.net/Services/Communications/V01/SureTax.asmx/CancelPostRequest",
+	},
+}
+
+// Returns the environment called s, "cert" or "production", ignoring case.
+func ParseEnvironment(s string) (Environment, error) {
+	env := Environment(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := environments[env]; !ok {
+		return "", fmt.Errorf("Unknown SureTax environment %q, expected %s or %s", s, EnvironmentCert, EnvironmentProduction)
+	}
+	return env, nil
+}
+
+// Returns the post request url of the environment. Empty for an unknown environment.
+func (e Environment) Url() string {
+	return environments[e].url
+}
+
+// Returns the cancel post request url of the environment. Empty for an unknown environment.
+func (e Environment) CancelUrl() string {
+	return environments[e].cancelUrl
+}
+
+// Returns a client posting to the URLs of env on behalf of the account of creds, configured by opts.
+//
+//	client, err := suretax.NewClientForEnvironment(suretax.EnvironmentCert, suretax.Credentials{ClientNumber: "000000001", ValidationKey: key})
+func NewClientForEnvironment(env Environment, creds Credentials, opts ...Option) (*SuretaxClient, error) {
+	if _, ok := environments[env]; !ok {
+		return nil, fmt.Errorf("Unknown SureTax environment %q", string(env))
+	}
+	c := NewClient(env.Url(), env.CancelUrl(), opts...)
+	c.Credentials = creds
+	return c, nil
+}
@@ -0,0 +1,45 @@
+package suretax
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_NewClientForEnvironment(t *testing.T) {
+
+	var posted *http.Request
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		posted = r
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)), nil
+	})
+
+	cli, err := NewClientForEnvironment(EnvironmentCert, Credentials{ClientNumber: "000000002", ValidationKey: "key"}, WithHttpClient(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.Url != "https://testapi.taxrating.net/Services/Communications/V01/SureTax.asmx/PostRequest" ||
+		cli.CancelUrl != "https://testapi.taxrating.net/Services/Communications/V01/SureTax.asmx/CancelPostRequest" {
+		t.Fatalf("Expected the CERT urls but got %v %v", cli.Url, cli.CancelUrl)
+	}
+
+	req := getTestRequest()
+	req.ClientNumber, req.ValidationKey = "", ""
+	if _, err := cli.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	if sent := decodeTestRequest(t, posted); sent.ClientNumber != "000000002" || sent.ValidationKey != "key" {
+		t.Fatalf("Expected the client credentials but got %v %v", sent.ClientNumber, sent.ValidationKey)
+	}
+
+	cancel := &CancelRequest{TransId: "1"}
+	if _, err := cli.Cancel(cancel); err != nil || cancel.ClientNumber != "000000002" || posted.URL.Path != "/Services/Communications/V01/SureTax.asmx/CancelPostRequest" {
+		t.Fatalf("Expected the credentials set on the cancellation but got %+v, %v", cancel, err)
+	}
+
+	if _, err := NewClientForEnvironment("staging", Credentials{}); err == nil {
+		t.Fatal("Expected an error for an unknown environment")
+	}
+	if env, err := ParseEnvironment(" Production "); err != nil || env.Url() != "https://api.taxrating.net/Services/Communications/V01/SureTax.asmx/PostRequest" {
+		t.Fatalf("Expected the production environment but got %v, %v", env, err)
+	}
+}
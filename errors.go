@@ -0,0 +1,144 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// Describes a request that failed client-side validation and was not sent to SureTax.
+type ValidationError struct {
+	// Line number of the offending item. Empty for request-level problems.
+	LineNumber string
+
+	// Name of the Request or RequestItem field that failed validation.
+	Field string
+
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.LineNumber != "" {
+		return fmt.Sprintf("Validation failed for item %s, field %s: %s", e.LineNumber, e.Field, e.Message)
+	}
+	return fmt.Sprintf("Validation failed for field %s: %s", e.Field, e.Message)
+}
+
+// Returned when SureTax answers with a non-200 HTTP status.
+type HttpError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HttpError) Error() string {
+	return "SureTax returned " + e.Status
+}
+
+// Describes a SureTax response with a ResponseCode other than 9999.
+// Send returns it, together with the response, for declined requests (Successful = N).
+// For 9001 "Success with Item errors" it's only available via Response.Err.
+type ResponseError struct {
+	ResponseCode  string
+	HeaderMessage string
+
+	Response *Response
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("SureTax response %s: %s", e.ResponseCode, e.HeaderMessage)
+}
+
+// Reports whether err was caused by missing or invalid SureTax credentials.
+func IsAuthError(err error) bool {
+	var herr *HttpError
+	if errors.As(err, &herr) {
+		return herr.StatusCode == http.StatusUnauthorized || herr.StatusCode == http.StatusForbidden
+	}
+
+	var rerr *ResponseError
+	if errors.As(err, &rerr) {
+		c, _ := Classify(rerr.ResponseCode)
+		return c == ResponseAuthFailure
+	}
+
+	var cerr *CancelError
+	if errors.As(err, &cerr) {
+		return cerr.Outcome == CancelOutcomeInvalidKey
+	}
+
+	return false
+}
+
+// Reports whether err was caused by invalid request data, detected either client-side
+// or by SureTax declining the request. Resending the same request will fail again.
+func IsValidationError(err error) bool {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return true
+	}
+
+	var herr *HttpError
+	if errors.As(err, &herr) {
+		return herr.StatusCode == http.StatusBadRequest
+	}
+
+	var rerr *ResponseError
+	if errors.As(err, &rerr) {
+		c, _ := Classify(rerr.ResponseCode)
+		return c != ResponseAuthFailure && c != ResponseSuccessWithErrors
+	}
+
+	var cerr *CancelError
+	if errors.As(err, &cerr) {
+		return cerr.Outcome != CancelOutcomeInvalidKey
+	}
+
+	return false
+}
+
+// Reports whether err is a temporary failure (server errors, throttling, timeouts, refused or reset connections)
+// and the request may succeed if retried. Requests cancelled by the caller and other network failures,
+// e.g. an untrusted TLS certificate, aren't transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var herr *HttpError
+	if errors.As(err, &herr) {
+		return herr.StatusCode >= 500 ||
+			herr.StatusCode == http.StatusTooManyRequests ||
+			herr.StatusCode == http.StatusRequestTimeout
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrCircuitOpen) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	// Every *url.Error returned by http.Client.Do is a net.Error, so only its timeouts count.
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// Reports whether err describes items SureTax could not process while the rest of the request succeeded.
+func IsItemError(err error) bool {
+	var ierr *ItemError
+	if errors.As(err, &ierr) {
+		return true
+	}
+
+	var rerr *ResponseError
+	if errors.As(err, &rerr) {
+		return rerr.ResponseCode == ResponseCodeSuccessWithItemErrors
+	}
+
+	return false
+}
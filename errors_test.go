@@ -0,0 +1,156 @@
+package suretax
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// Returns canned responses and records received requests.
+type fakeHttpClient struct {
+	status int
+	bodies []string
+	err    error
+
+	requests []*http.Request
+}
+
+func (f *fakeHttpClient) Do(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	body := f.bodies[0]
+	if len(f.bodies) > 1 {
+		f.bodies = f.bodies[1:]
+	}
+
+	status := f.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}, nil
+}
+
+// Wraps an inner response JSON into the {"d": "..."} envelope.
+func envelope(inner string) string {
+	b := new(bytes.Buffer)
+	b.WriteString(`{"d":`)
+	fmt.Fprintf(b, "%q", inner)
+	b.WriteString(`}`)
+	return b.String()
+}
+
+func Test_errorPredicates(t *testing.T) {
+
+	cases := []struct {
+		err                               error
+		auth, validation, transient, item bool
+	}{
+		{&ValidationError{Field: "STAN"}, false, true, false, false},
+		{&HttpError{StatusCode: 401}, true, false, false, false},
+		{&HttpError{StatusCode: 400}, false, true, false, false},
+		{&HttpError{StatusCode: 503}, false, false, true, false},
+		{&ResponseError{ResponseCode: "1151"}, true, false, false, false},
+		{&ResponseError{ResponseCode: "1120"}, false, true, false, false},
+		{&ResponseError{ResponseCode: "9001"}, false, false, false, true},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), false, false, true, false},
+		{&url.Error{Op: "Post", Err: context.Canceled}, false, false, false, false},
+		{&url.Error{Op: "Post", Err: x509.UnknownAuthorityError{}}, false, false, false, false},
+		{&url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, false, false, true, false},
+		{&url.Error{Op: "Post", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, false, false, true, false},
+		{errors.New("other"), false, false, false, false},
+	}
+
+	for _, c := range cases {
+		if IsAuthError(c.err) != c.auth {
+			t.Fatalf("Expected IsAuthError(%v) %v", c.err, c.auth)
+		}
+		if IsValidationError(c.err) != c.validation {
+			t.Fatalf("Expected IsValidationError(%v) %v", c.err, c.validation)
+		}
+		if IsTransient(c.err) != c.transient {
+			t.Fatalf("Expected IsTransient(%v) %v", c.err, c.transient)
+		}
+		if IsItemError(c.err) != c.item {
+			t.Fatalf("Expected IsItemError(%v) %v", c.err, c.item)
+		}
+	}
+}
+
+func Test_Send_declined(t *testing.T) {
+
+	fake := &fakeHttpClient{bodies: []string{envelope(`{"ResponseCode":"1151","HeaderMessage":"Failure - Invalid Validation Key","Successful":"N"}`)}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{}
+
+	res, err := cli.Send(getTestRequest())
+
+	if !IsAuthError(err) {
+		t.Fatalf("Expected auth error but got %v", err)
+	}
+
+	if res == nil || res.ResponseCode != "1151" {
+		t.Fatal("Expected the declined response to be returned with the error")
+	}
+}
+
+type httpClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Returns a 200 response with the given body.
+func okResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}
+}
+
+// Decodes the Request sent in a {"request": "..."} wrapper.
+func decodeTestRequest(t *testing.T, r *http.Request) *Request {
+	rw := requestWrapper{}
+	if err := json.NewDecoder(r.Body).Decode(&rw); err != nil {
+		t.Fatal(err)
+	}
+	req := &Request{}
+	if err := json.Unmarshal([]byte(rw.Request), req); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+// Decodes a wrapped payload: the wrapper's only field is a JSON string holding v.
+func decodeWrapped(t *testing.T, r *http.Request, inner *string, v interface{}) {
+	wrapper := map[string]string{}
+	if err := json.NewDecoder(r.Body).Decode(&wrapper); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range wrapper {
+		*inner = s
+	}
+	if err := json.Unmarshal([]byte(*inner), v); err != nil {
+		t.Fatal(err)
+	}
+}
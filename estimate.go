@@ -0,0 +1,285 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"syscall"
+)
+
+// Returned by an EstimateProvider that has nothing to base an estimate on.
+var ErrNoEstimate = errors.New("No tax estimate available")
+
+// Estimates the taxes of a request without calling SureTax. See SuretaxClient.Estimator.
+type EstimateProvider interface {
+	Estimate(req *Request) (*Response, error)
+}
+
+// Optionally implemented by an EstimateProvider to learn from the responses of successful requests.
+type EstimateObserver interface {
+	Observe(req *Request, res *Response)
+}
+
+// Reports whether err shows the request didn't reach SureTax, or was refused or declined without being
+// recorded, so it can be sent again without being recorded twice.
+func notSent(err error) bool {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return true
+	}
+	var herr *HttpError
+	if errors.As(err, &herr) {
+		return herr.StatusCode < http.StatusInternalServerError
+	}
+	var rerr *ResponseError
+	if errors.As(err, &rerr) {
+		return rerr.ResponseCode != ResponseCodeSuccessWithItemErrors
+	}
+	return errors.Is(err, ErrCircuitOpen) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// Returns the estimate for a request that failed with a transient error, queueing the request in the outbox.
+// Other errors, and failures of the provider, return the original error. So do final requests SureTax may
+// have recorded, e.g. on a timeout, when they would be queued: sending them again could file them twice.
+// Queued final requests get a STAN if they have none.
+func (c *SuretaxClient) estimateOnFailure(req *Request, res *Response, err error) (*Response, error) {
+	if err == nil {
+		if o, ok := c.Estimator.(EstimateObserver); ok && !res.declined() {
+			o.Observe(req, res)
+		}
+		return res, nil
+	}
+	if !IsTransient(err) {
+		return res, err
+	}
+
+	queue := c.Outbox != nil && req.ReturnFileCode != "Q"
+	if queue {
+		if !notSent(err) {
+			logger.Warn("SureTax may have recorded the request, not queueing it", "ClientTracking", req.ClientTracking, "Error", err)
+			return res, err
+		}
+		if req.STAN == "" {
+			req.STAN = NewStan()
+		}
+	}
+
+	est, eerr := c.Estimator.Estimate(req)
+	if eerr != nil {
+		logger.Warn("SureTax unavailable and no estimate", "Error", eerr)
+		return res, err
+	}
+	est.Estimated = true
+	if est.ClientTracking == "" {
+		est.ClientTracking = req.ClientTracking
+	}
+
+	if queue {
+		if qerr := c.Outbox.Enqueue(req); qerr != nil {
+			logger.Error("Queueing failed", "ClientTracking", req.ClientTracking, "Error", qerr)
+			return res, err
+		}
+	}
+
+	logger.Warn("SureTax unavailable, returning estimate", "ClientTracking", req.ClientTracking, "Error", err)
+	return est, nil
+}
+
+// Holds requests to be sent to SureTax later.
+type Outbox interface {
+	Enqueue(req *Request) error
+}
+
+// In-memory Outbox. Safe for concurrent use.
+type MemoryOutbox struct {
+	mu       sync.Mutex
+	requests []*Request
+}
+
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{}
+}
+
+func (o *MemoryOutbox) Enqueue(req *Request) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.requests = append(o.requests, req)
+	return nil
+}
+
+// Returns the queued requests, oldest first.
+func (o *MemoryOutbox) Pending() []*Request {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]*Request(nil), o.requests...)
+}
+
+// Sends the queued requests in order, removing each one SureTax answered. Stops at the first error,
+// leaving it and the rest queued, except for a final request whose send failed without telling whether
+// SureTax recorded it, e.g. on a timeout: it is removed and the error wraps ErrOutcomeUnknown, so it isn't
+// filed twice by the next Flush. Look it up in SureTax by its STAN or ClientTracking.
+// Requests are sent without the client's Quotas, Estimator and Outbox.
+func (o *MemoryOutbox) Flush(ctx context.Context, c *SuretaxClient) ([]*Response, error) {
+	var responses []*Response
+	for {
+		o.mu.Lock()
+		if len(o.requests) == 0 {
+			o.mu.Unlock()
+			return responses, nil
+		}
+		req := o.requests[0]
+		o.mu.Unlock()
+
+		res, err := c.send(ctx, req, &Response{})
+		unknown := err != nil && req.ReturnFileCode != "Q" &&
+			(IsTransient(err) && !notSent(err) || errors.Is(err, ErrOutcomeUnknown))
+		if err != nil && !unknown {
+			return responses, err
+		}
+		if err == nil {
+			responses = append(responses, res)
+		}
+
+		o.mu.Lock()
+		if len(o.requests) > 0 && o.requests[0] == req {
+			o.requests = o.requests[1:]
+		}
+		o.mu.Unlock()
+
+		if unknown {
+			return responses, fmt.Errorf("Queued request %s may have been recorded by SureTax: %w: %w", req.STAN, ErrOutcomeUnknown, err)
+		}
+	}
+}
+
+// EstimateProvider applying the last effective tax rate (total tax over revenue) seen per state and trans type.
+// Items without a known rate use Default, if set. Safe for concurrent use.
+type EffectiveRateEstimator struct {
+	// Rate used for items without an observed rate, e.g. "0.08". Empty fails the estimate.
+	Default string
+
+	mu    sync.Mutex
+	rates map[string]*big.Rat
+}
+
+func NewEffectiveRateEstimator() *EffectiveRateEstimator {
+	return &EffectiveRateEstimator{rates: make(map[string]*big.Rat)}
+}
+
+// Returns the state of the item's address, or of its P2P address.
+func itemState(item *RequestItem) string {
+	if item.Address.State != "" {
+		return item.Address.State
+	}
+	return item.P2PAddress.State
+}
+
+func rateKey(item *RequestItem) string {
+	return itemState(item) + "|" + item.TransTypeCode
+}
+
+// Records the effective rate of every item of req taxed without error.
+func (e *EffectiveRateEstimator) Observe(req *Request, res *Response) {
+	groups, err := res.Groups()
+	if err != nil {
+		return
+	}
+
+	failed := map[string]bool{}
+	for _, m := range res.ItemMessages {
+		failed[lineKey(m.LineNumber)] = true
+	}
+	taxes := map[string][]string{}
+	for _, g := range groups {
+		for _, t := range g.TaxList {
+			taxes[lineKey(g.LineNumber)] = append(taxes[lineKey(g.LineNumber)], t.TaxAmount)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range req.ItemList {
+		item := &req.ItemList[i]
+		l := lineKey(item.LineNumber)
+		revenue, err := parseAmount(item.Revenue)
+		if failed[l] || err != nil || revenue.Sign() == 0 {
+			continue
+		}
+		total, err := sumAmounts(taxes[l])
+		if err != nil {
+			continue
+		}
+		tax, _ := parseAmount(total)
+		e.rates[rateKey(item)] = tax.Quo(tax, revenue)
+	}
+}
+
+// Returns the effective rate recorded for the state and trans type of item.
+func (e *EffectiveRateEstimator) Rate(item *RequestItem) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r, ok := e.rates[rateKey(item)]
+	if !ok {
+		return "", false
+	}
+	return r.FloatString(6), true
+}
+
+// Returns a response with one estimated tax per item. Fails with ErrNoEstimate when an item has no rate.
+func (e *EffectiveRateEstimator) Estimate(req *Request) (*Response, error) {
+	var def *big.Rat
+	if e.Default != "" {
+		var err error
+		if def, err = parseAmount(e.Default); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &Response{ResponseCode: "9999", HeaderMessage: "Success", Successful: "Y", STAN: req.STAN}
+	total := new(big.Rat)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range req.ItemList {
+		item := &req.ItemList[i]
+		rate, ok := e.rates[rateKey(item)]
+		if !ok {
+			rate = def
+		}
+		if rate == nil {
+			return nil, ErrNoEstimate
+		}
+
+		revenue, err := parseAmount(item.Revenue)
+		if err != nil {
+			return nil, err
+		}
+		tax := new(big.Rat).Mul(revenue, rate)
+		taxRate, _ := rate.Float64()
+		total.Add(total, tax)
+
+		res.GroupList = append(res.GroupList, Group{
+			LineNumber:     item.LineNumber,
+			InvoiceNumber:  item.InvoiceNumber,
+			CustomerNumber: item.CustomerNumber,
+			TaxList: []Tax{{
+				TaxTypeDesc: "ESTIMATED TAX",
+				TaxAmount:   formatAmount(tax, 2),
+				Revenue:     item.Revenue,
+				RevenueBase: item.Revenue,
+				TaxRate:     taxRate,
+			}},
+		})
+	}
+	res.TotalTax = formatAmount(total, 2)
+
+	return res, nil
+}
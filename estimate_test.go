@@ -0,0 +1,94 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func Test_Estimator_Send(t *testing.T) {
+
+	ok := envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1,"TotalTax":"8.00","GroupList":[{"LineNumber":"1","TaxList":[` +
+		`{"TaxAmount":"5.00"},{"TaxAmount":"3.00"}]}]}`)
+	fake := &fakeHttpClient{bodies: []string{ok, ok}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	estimator := NewEffectiveRateEstimator()
+	outbox := NewMemoryOutbox()
+	cli := &SuretaxClient{Estimator: estimator, Outbox: outbox}
+
+	newRequest := func(revenue string) *Request {
+		req := getTestRequest()
+		req.ItemList[0].Revenue = revenue
+		req.ItemList[0].Address = Address{State: "FL", PostalCode: "32034"}
+		return req
+	}
+
+	res, err := cli.Send(newRequest("100"))
+	if err != nil || res.Estimated {
+		t.Fatalf("Expected a calculated response but got %v", err)
+	}
+	if rate, _ := estimator.Rate(&newRequest("1").ItemList[0]); rate != "0.080000" {
+		t.Fatalf("Expected rate 0.080000 but got %v", rate)
+	}
+
+	refused := &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	fake.err = refused
+	res, err = cli.Send(newRequest("50"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Estimated || res.TotalTax != "4.00" || res.TransId != 0 {
+		t.Fatalf("Expected an estimate of 4.00 but got %+v", res)
+	}
+	if pending := outbox.Pending(); len(pending) != 1 || pending[0].STAN == "" || res.STAN != pending[0].STAN {
+		t.Fatalf("Expected the request to be queued with a STAN but got %+v", pending)
+	}
+
+	fake.err = io.ErrUnexpectedEOF
+	if _, err := cli.Send(newRequest("50")); !errors.Is(err, io.ErrUnexpectedEOF) || len(outbox.Pending()) != 1 {
+		t.Fatalf("Expected a request SureTax may have recorded not to be queued but got %v", err)
+	}
+
+	fake.err = refused
+	other := newRequest("50")
+	other.ItemList[0].Address.State = "GA"
+	if _, err := cli.Send(other); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Fatalf("Expected the send error without a known rate but got %v", err)
+	}
+	estimator.Default = "0.1"
+	if res, err := cli.Send(other); err != nil || res.TotalTax != "5.00" {
+		t.Fatalf("Expected the default rate to apply but got %v", err)
+	}
+
+	fake.err = io.ErrUnexpectedEOF
+	if _, err := outbox.Flush(context.Background(), cli); !errors.Is(err, ErrOutcomeUnknown) || len(outbox.Pending()) != 1 {
+		t.Fatalf("Expected a request SureTax may have recorded to be taken out of the outbox but got %v", err)
+	}
+
+	fake.err = nil
+	sent, err := outbox.Flush(context.Background(), cli)
+	if err != nil || len(sent) != 1 || len(outbox.Pending()) != 0 {
+		t.Fatalf("Expected the queued request to be sent but got %v, %v", len(sent), err)
+	}
+}
+
+func Test_Estimator_NotTransient(t *testing.T) {
+
+	fake := &fakeHttpClient{status: 400, bodies: []string{""}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	estimator := NewEffectiveRateEstimator()
+	estimator.Default = "0.1"
+	cli := &SuretaxClient{Estimator: estimator}
+
+	if _, err := cli.Send(getTestRequest()); !IsValidationError(err) {
+		t.Fatalf("Expected the validation error but got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package suretax
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// Controls which identifying fields appear in exported files.
+type ExportProfile int
+
+const (
+	// All columns, including customer and invoice numbers.
+	ExportFull ExportProfile = iota
+
+	// Amounts, jurisdictions and invoice numbers. Customer numbers and tracking values are omitted.
+	ExportFinance
+
+	// Transaction references and jurisdictions for troubleshooting. Customer and invoice numbers are omitted.
+	ExportSupport
+)
+
+type exportColumn struct {
+	name string
+
+	// Included in ExportFinance
+	finance bool
+
+	// Included in ExportSupport
+	support bool
+
+	// Typed as Number by ExportExcel
+	number bool
+
+	value func(res *Response, g *Group, t *Tax) string
+}
+
+var exportColumns = []exportColumn{
+	{"TransId", true, true, false, func(r *Response, g *Group, t *Tax) string { return strconv.Itoa(r.TransId) }},
+	{"ClientTracking", false, true, false, func(r *Response, g *Group, t *Tax) string { return r.ClientTracking }},
+	{"CustomerNumber", false, false, false, func(r *Response, g *Group, t *Tax) string { return g.CustomerNumber }},
+	{"InvoiceNumber", true, false, false, func(r *Response, g *Group, t *Tax) string { return g.InvoiceNumber }},
+	{"LineNumber", true, true, false, func(r *Response, g *Group, t *Tax) string { return g.LineNumber }},
+	{"StateCode", true, true, false, func(r *Response, g *Group, t *Tax) string { return g.StateCode }},
+	{"CountyName", true, true, false, func(r *Response, g *Group, t *Tax) string { return t.CountyName }},
+	{"CityName", true, true, false, func(r *Response, g *Group, t *Tax) string { return t.CityName }},
+	{"TaxAuthorityID", true, true, false, func(r *Response, g *Group, t *Tax) string { return t.TaxAuthorityID }},
+	{"TaxAuthorityName", true, true, false, func(r *Response, g *Group, t *Tax) string { return t.TaxAuthorityName }},
+	{"TaxTypeCode", true, true, false, func(r *Response, g *Group, t *Tax) string { return t.TaxTypeCode }},
+	{"TaxTypeDesc", true, true, false, func(r *Response, g *Group, t *Tax) string { return t.TaxTypeDesc }},
+	{"TaxRate", true, true, true, func(r *Response, g *Group, t *Tax) string { return strconv.FormatFloat(t.TaxRate, 'f', -1, 64) }},
+	{"FeeRate", true, true, true, func(r *Response, g *Group, t *Tax) string { return strconv.FormatFloat(t.FeeRate, 'f', -1, 64) }},
+	{"PercentTaxable", true, true, true, func(r *Response, g *Group, t *Tax) string { return strconv.FormatFloat(t.PercentTaxable, 'f', -1, 64) }},
+	{"Revenue", true, true, true, func(r *Response, g *Group, t *Tax) string { return t.Revenue }},
+	{"RevenueBase", true, true, true, func(r *Response, g *Group, t *Tax) string { return t.RevenueBase }},
+	{"TaxAmount", true, true, true, func(r *Response, g *Group, t *Tax) string { return t.TaxAmount }},
+	{"TaxOnTax", true, true, true, func(r *Response, g *Group, t *Tax) string { return t.TaxOnTax }},
+}
+
+func (p ExportProfile) columns() []exportColumn {
+	var cols []exportColumn
+	for _, c := range exportColumns {
+		if p == ExportFull || (p == ExportFinance && c.finance) || (p == ExportSupport && c.support) {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// Calls fn with the exported values of every tax line of res.
+func exportRows(res *Response, cols []exportColumn, fn func(values []string) error) error {
+	groups, err := res.Groups()
+	if err != nil {
+		return err
+	}
+
+	values := make([]string, len(cols))
+	for gi := range groups {
+		g := &groups[gi]
+		for ti := range g.TaxList {
+			t := &g.TaxList[ti]
+			for i, c := range cols {
+				values[i] = c.value(res, g, t)
+			}
+			if err := fn(values); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Writes one CSV row per tax line of res, preceded by a header row.
+func ExportCSV(w io.Writer, res *Response, profile ExportProfile) error {
+	cols := profile.columns()
+
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	if err := exportRows(res, cols, cw.Write); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes one JSON object per line for every tax line of res.
+func ExportNDJSON(w io.Writer, res *Response, profile ExportProfile) error {
+	cols := profile.columns()
+
+	enc := json.NewEncoder(w)
+
+	return exportRows(res, cols, func(values []string) error {
+		row := make(map[string]string, len(cols))
+		for i, c := range cols {
+			row[c.name] = values[i]
+		}
+		return enc.Encode(row)
+	})
+}
+
+// Writes res as an Excel-compatible XML Spreadsheet 2003 document with one row per tax line.
+// Amounts and rates are written as Number cells, the other columns as String cells.
+func ExportExcel(w io.Writer, res *Response, profile ExportProfile) error {
+	cols := profile.columns()
+
+	type cell struct {
+		Data struct {
+			Type  string `xml:"ss:Type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+	}
+	type row struct {
+		Cells []cell `xml:"Cell"`
+	}
+
+	newRow := func(values []string, cols []exportColumn) row {
+		r := row{Cells: make([]cell, len(values))}
+		for i, v := range values {
+			r.Cells[i].Data.Type = "String"
+			if cols != nil && cols[i].number {
+				// Excel rejects empty or malformed Number cells
+				if _, err := strconv.ParseFloat(v, 64); err == nil {
+					r.Cells[i].Data.Type = "Number"
+				}
+			}
+			r.Cells[i].Data.Value = v
+		}
+		return r
+	}
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+	rows := []row{newRow(header, nil)}
+
+	err := exportRows(res, cols, func(values []string) error {
+		rows = append(rows, newRow(values, cols))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	doc := struct {
+		XMLName xml.Name `xml:"Workbook"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		XmlnsSS string   `xml:"xmlns:ss,attr"`
+		Sheet   struct {
+			Name string `xml:"ss:Name,attr"`
+			Rows []row  `xml:"Table>Row"`
+		} `xml:"Worksheet"`
+	}{
+		Xmlns:   "urn:schemas-microsoft-com:office:spreadsheet",
+		XmlnsSS: "urn:schemas-microsoft-com:office:spreadsheet",
+	}
+	doc.Sheet.Name = "Taxes"
+	doc.Sheet.Rows = rows
+
+	if _, err := io.WriteString(w, xml.Header+`<?mso-application progid="Excel.Sheet"?>`+"\n"); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
@@ -0,0 +1,91 @@
+package suretax
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_ExportCSV_profiles(t *testing.T) {
+
+	res, err := testCli.parseResponse(getTestResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var full, finance, support bytes.Buffer
+
+	if err := ExportCSV(&full, res, ExportFull); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportCSV(&finance, res, ExportFinance); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportCSV(&support, res, ExportSupport); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Count(full.String(), "\n"); lines != 5 {
+		t.Fatalf("Expected %v lines but got %v", 5, lines)
+	}
+
+	if !strings.Contains(full.String(), "CustomerNumber") {
+		t.Fatal("Expected full export to contain CustomerNumber")
+	}
+
+	if strings.Contains(finance.String(), "CustomerNumber") || !strings.Contains(finance.String(), "INV-002") {
+		t.Fatal("Expected finance export to contain invoice numbers only")
+	}
+
+	if strings.Contains(support.String(), "INV-002") || !strings.Contains(support.String(), "Certi") {
+		t.Fatal("Expected support export to omit invoice numbers and keep ClientTracking")
+	}
+}
+
+func Test_ExportNDJSON(t *testing.T) {
+
+	res, err := testCli.parseResponse(getTestResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportNDJSON(&buf, res, ExportFinance); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Count(buf.String(), "\n"); lines != 4 {
+		t.Fatalf("Expected %v lines but got %v", 4, lines)
+	}
+
+	if strings.Contains(buf.String(), "CustomerNumber") {
+		t.Fatal("Expected finance export to omit CustomerNumber")
+	}
+}
+
+func Test_ExportExcel(t *testing.T) {
+
+	res, err := testCli.parseResponse(getTestResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportExcel(&buf, res, ExportFinance); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{`<Data ss:Type="Number">8.46</Data>`, `<Data ss:Type="String">INV-002</Data>`, `<Data ss:Type="String">TaxAmount</Data>`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("Expected the workbook to contain %s but got %s", s, buf.String())
+		}
+	}
+
+	res = &Response{}
+	if err := decodeResponseJSON(`{"GroupList":[{"TaxList":"x"}]}`, res, decodeOptions{lazy: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportExcel(&bytes.Buffer{}, res, ExportFull); err == nil {
+		t.Fatal("Expected the error decoding the groups to be returned")
+	}
+}
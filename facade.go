@@ -0,0 +1,128 @@
+package suretax
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Paths served by Facade.
+const (
+	FacadeSendPath    = "/v1/send"
+	FacadeCancelPath  = "/v1/cancel"
+	FacadeOpenAPIPath = "/openapi.json"
+)
+
+// http.Handler exposing the client as a plain JSON service for callers that don't use Go:
+// requests and responses are posted as is, without the SureTax request wrapper and "d" envelope,
+// and the credentials are filled in from the client Credentials. GET /openapi.json describes the endpoints.
+//
+//	http.ListenAndServe(":8080", &suretax.Facade{Client: profile.Client()})
+type Facade struct {
+	Client *SuretaxClient
+
+	// Optional account whose credentials are set on requests that have none,
+	// before those of the client.
+	Profile *Profile
+
+	// Optional verifier of the request signatures, see HMACSigner. Unsigned requests are refused.
+	Verifier *HMACSigner
+}
+
+// Body of the facade error responses.
+type FacadeError struct {
+	Error string `json:"error"`
+}
+
+func (f *Facade) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case FacadeOpenAPIPath:
+		if r.Method != http.MethodGet {
+			writeFacadeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		writeFacadeJSON(w, http.StatusOK, FacadeOpenAPI())
+	case FacadeSendPath:
+		f.serveSend(w, r)
+	case FacadeCancelPath:
+		f.serveCancel(w, r)
+	default:
+		writeFacadeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// Reads the JSON body of a POST into v, writing the error response and returning false on failure.
+func (f *Facade) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeFacadeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return false
+	}
+	if f.Verifier != nil {
+		if err := f.Verifier.Verify(r); err != nil {
+			writeFacadeError(w, http.StatusUnauthorized, err.Error())
+			return false
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeFacadeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func (f *Facade) serveSend(w http.ResponseWriter, r *http.Request) {
+	req := &Request{}
+	if !f.decode(w, r, req) {
+		return
+	}
+	if f.Profile != nil {
+		f.Profile.Apply(req)
+	}
+
+	res, err := f.Client.SendContext(r.Context(), req)
+	if res != nil {
+		// declined requests and item errors are reported in the response itself
+		writeFacadeJSON(w, http.StatusOK, res)
+		return
+	}
+	writeFacadeError(w, facadeStatus(err), err.Error())
+}
+
+func (f *Facade) serveCancel(w http.ResponseWriter, r *http.Request) {
+	req := &CancelRequest{}
+	if !f.decode(w, r, req) {
+		return
+	}
+	if f.Profile != nil {
+		f.Profile.ApplyCancel(req)
+	}
+
+	res, err := f.Client.CancelContext(r.Context(), req)
+	if res != nil {
+		writeFacadeJSON(w, http.StatusOK, res)
+		return
+	}
+	writeFacadeError(w, facadeStatus(err), err.Error())
+}
+
+// Returns the status of a call that failed without a response.
+func facadeStatus(err error) int {
+	var verr *ValidationError
+	switch {
+	case errors.As(err, &verr):
+		return http.StatusBadRequest
+	case IsTransient(err):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+func writeFacadeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeFacadeError(w http.ResponseWriter, status int, msg string) {
+	writeFacadeJSON(w, status, &FacadeError{Error: msg})
+}
@@ -0,0 +1,95 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Facade_Send(t *testing.T) {
+
+	var sent *Request
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		sent = decodeTestRequest(t, r)
+		return okResponse(envelope(decodeTestInner(t))), nil
+	}))
+	defer SetHttpClient(nil)
+
+	profile := &Profile{ClientNumber: "000000001", ValidationKey: "secret"}
+	signer := &HMACSigner{Key: []byte("proxy")}
+	f := &Facade{Client: &SuretaxClient{}, Profile: profile, Verifier: signer}
+
+	req := getTestRequest()
+	req.ClientNumber, req.ValidationKey = "", ""
+	body, _ := json.Marshal(req)
+
+	r := httptest.NewRequest("POST", FacadeSendPath, bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	f.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected an unsigned request to be refused but got %v", w.Code)
+	}
+
+	r = httptest.NewRequest("POST", FacadeSendPath, bytes.NewReader(body))
+	signer.Sign(r, body)
+	w = httptest.NewRecorder()
+	f.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 but got %v: %s", w.Code, w.Body)
+	}
+
+	res := &Response{}
+	if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalTax != "28.65" || sent.ValidationKey != "secret" || sent.ClientNumber != "000000001" {
+		t.Fatalf("Expected the profile credentials to be sent and the bare response returned but got %+v, %+v", sent, res)
+	}
+
+	f.Verifier = nil
+	r = httptest.NewRequest("POST", FacadeSendPath, bytes.NewReader([]byte("{")))
+	w = httptest.NewRecorder()
+	f.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for an invalid body but got %v", w.Code)
+	}
+}
+
+func Test_FacadeOpenAPI(t *testing.T) {
+
+	w := httptest.NewRecorder()
+	(&Facade{}).ServeHTTP(w, httptest.NewRequest("GET", FacadeOpenAPIPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 but got %v", w.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string
+		Paths      map[string]interface{}
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]interface{}
+			}
+		}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != "3.0.3" || doc.Paths[FacadeSendPath] == nil || doc.Paths[FacadeCancelPath] == nil {
+		t.Fatalf("Expected the facade paths but got %+v", doc.Paths)
+	}
+
+	res := doc.Components.Schemas["Response"].Properties
+	if res["TransId"]["type"] != "integer" || res["GroupList"]["type"] != "array" {
+		t.Fatalf("Expected the Response schema from the struct but got %v", res)
+	}
+	if _, ok := res["Estimated"]; ok {
+		t.Fatal("Expected fields excluded from JSON to be left out")
+	}
+	if _, ok := doc.Components.Schemas["RequestItem"]; !ok {
+		t.Fatal("Expected nested structs to be described")
+	}
+}
@@ -0,0 +1,247 @@
+package suretax
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// One SureTax site of a Failover set.
+type FailoverEndpoint struct {
+	Url       string
+	CancelUrl string
+}
+
+// Health of a Failover endpoint.
+type FailoverHealth struct {
+	FailoverEndpoint
+
+	Healthy bool
+
+	// Consecutive failed calls.
+	Failures int
+
+	// Time the endpoint is tried again while unhealthy.
+	RetryAt time.Time
+
+	// Error of the last failed call.
+	LastError error
+}
+
+type endpointHealth struct {
+	failures  int
+	retryAt   time.Time
+	lastError error
+}
+
+// Primary and secondary (DR) SureTax sites. Calls go to the first healthy endpoint in order;
+// when one fails with a connection error or a 5xx response, the request is resent to the next one.
+// An endpoint failing FailureThreshold calls in a row is avoided for RetryAfter, after which it's tried
+// again, so calls fail back to the primary once it has recovered.
+// Set as SuretaxClient.Failover, it takes precedence over Url, CancelUrl and Discovery.
+// Must not be copied after first use. Safe for concurrent use.
+type Failover struct {
+	// Endpoints in order of preference, the primary first.
+	Endpoints []FailoverEndpoint
+
+	// Consecutive failures marking an endpoint unhealthy. Defaults to 1.
+	FailureThreshold int
+
+	// Time an unhealthy endpoint is avoided. Defaults to 30 seconds.
+	RetryAfter time.Duration
+
+	// Optional callback invoked when calls move to another endpoint, on failover and on fail-back.
+	OnChange func(from, to FailoverEndpoint)
+
+	mu     sync.Mutex
+	health []endpointHealth
+	active int
+	now    func() time.Time
+}
+
+func (f *Failover) clock() time.Time {
+	if f.now != nil {
+		return f.now()
+	}
+	return time.Now()
+}
+
+func (f *Failover) threshold() int {
+	if f.FailureThreshold > 0 {
+		return f.FailureThreshold
+	}
+	return 1
+}
+
+func (f *Failover) retryAfter() time.Duration {
+	if f.RetryAfter > 0 {
+		return f.RetryAfter
+	}
+	return 30 * time.Second
+}
+
+// called with mu held
+func (f *Failover) init() {
+	if len(f.health) != len(f.Endpoints) {
+		f.health = make([]endpointHealth, len(f.Endpoints))
+	}
+}
+
+// Returns the endpoint calls are currently sent to.
+func (f *Failover) Active() FailoverEndpoint {
+	if order := f.order(); len(order) > 0 {
+		return f.Endpoints[order[0]]
+	}
+	return FailoverEndpoint{}
+}
+
+// Returns the health of the endpoints, in order of preference.
+func (f *Failover) Health() []FailoverHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.init()
+
+	now := f.clock()
+	health := make([]FailoverHealth, len(f.Endpoints))
+	for i, h := range f.health {
+		health[i] = FailoverHealth{
+			FailoverEndpoint: f.Endpoints[i],
+			Healthy:          !h.retryAt.After(now),
+			Failures:         h.failures,
+			RetryAt:          h.retryAt,
+			LastError:        h.lastError,
+		}
+	}
+	return health
+}
+
+// Returns the indexes of the endpoints in the order they are tried: those available in order of preference,
+// then the unhealthy ones, soonest to be retried first.
+func (f *Failover) order() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.init()
+
+	now := f.clock()
+	order := make([]int, len(f.Endpoints))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := f.health[order[i]].retryAt, f.health[order[j]].retryAt
+		if !a.After(now) || !b.After(now) {
+			return !a.After(now) && b.After(now)
+		}
+		return a.Before(b)
+	})
+	return order
+}
+
+func (f *Failover) report(i int, err error) {
+	f.mu.Lock()
+	f.init()
+
+	h := &f.health[i]
+	if err != nil {
+		h.failures++
+		h.lastError = err
+		if h.failures >= f.threshold() {
+			h.retryAt = f.clock().Add(f.retryAfter())
+		}
+		f.mu.Unlock()
+		return
+	}
+
+	*h = endpointHealth{}
+	from := f.active
+	f.active = i
+	f.mu.Unlock()
+
+	if from != i && f.OnChange != nil {
+		f.OnChange(f.Endpoints[from], f.Endpoints[i])
+	}
+}
+
+// Returns the index of the endpoint whose Url or CancelUrl is u, and whether it's the cancel one.
+func (f *Failover) lookup(u string) (int, bool, bool) {
+	for i, e := range f.Endpoints {
+		if e.Url == u {
+			return i, false, true
+		}
+		if e.CancelUrl == u {
+			return i, true, true
+		}
+	}
+	return 0, false, false
+}
+
+// HttpClient resending failed requests to the next endpoint of a Failover.
+type failoverClient struct {
+	failover *Failover
+	client   HttpClient
+}
+
+func (c failoverClient) Do(r *http.Request) (*http.Response, error) {
+	f := c.failover
+	_, cancel, ok := f.lookup(r.URL.String())
+	if !ok || r.GetBody == nil {
+		return c.client.Do(r)
+	}
+
+	order := f.order()
+	var resp *http.Response
+	var err error
+	for n, i := range order {
+		target := f.Endpoints[i].Url
+		if cancel {
+			target = f.Endpoints[i].CancelUrl
+		}
+
+		var attempt *http.Request
+		if attempt, err = retarget(r, target); err != nil {
+			return nil, err
+		}
+
+		resp, err = c.client.Do(attempt)
+		failure := err
+		if err == nil && resp.StatusCode >= 500 {
+			failure = &HttpError{resp.StatusCode, resp.Status}
+		}
+		if failure == nil || (err != nil && !IsTransient(err)) || r.Context().Err() != nil {
+			if failure == nil {
+				f.report(i, nil)
+			}
+			return resp, err
+		}
+
+		f.report(i, failure)
+		if n == len(order)-1 {
+			break
+		}
+		logger.Warn("SureTax endpoint failed, failing over", "Url", target, "Error", failure)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+	return resp, err
+}
+
+// Returns a copy of r posting its body to target.
+func retarget(r *http.Request, target string) (*http.Request, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	c := r.Clone(r.Context())
+	c.URL = u
+	c.Host = ""
+	if c.Body, err = r.GetBody(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
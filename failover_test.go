@@ -0,0 +1,99 @@
+package suretax
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_Failover(t *testing.T) {
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	var changes []string
+	f := &Failover{
+		Endpoints: []FailoverEndpoint{
+			{Url: "https://primary/post", CancelUrl: "https://primary/cancel"},
+			{Url: "https://dr/post", CancelUrl: "https://dr/cancel"},
+		},
+		RetryAfter: time.Minute,
+		OnChange:   func(from, to FailoverEndpoint) { changes = append(changes, from.Url+">"+to.Url) },
+		now:        func() time.Time { return now },
+	}
+
+	primaryDown := true
+	var hosts []string
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		hosts = append(hosts, r.URL.Host+r.URL.Path)
+		decodeTestRequest(t, r)
+		if r.URL.Host == "primary" && primaryDown {
+			return nil, &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+		}
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)), nil
+	})
+	cli := NewClient("https://ignored/post", "https://ignored/cancel", WithHttpClient(transport))
+	cli.Failover = f
+
+	if _, err := cli.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "[primary/post dr/post dr/post]"; fmt.Sprint(hosts) != expected {
+		t.Fatalf("Expected %v but got %v", expected, hosts)
+	}
+	if h := f.Health(); h[0].Healthy || h[0].Failures != 1 || !h[1].Healthy {
+		t.Fatalf("Expected the primary to be unhealthy but got %+v", h)
+	}
+
+	primaryDown = false
+	now = now.Add(time.Minute)
+	hosts = nil
+	if _, err := cli.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(hosts) != "[primary/post]" || f.Active().Url != "https://primary/post" {
+		t.Fatalf("Expected calls to fail back to the primary but got %v", hosts)
+	}
+	if expected := "[https://primary/post>https://dr/post https://dr/post>https://primary/post]"; fmt.Sprint(changes) != expected {
+		t.Fatalf("Expected %v but got %v", expected, changes)
+	}
+}
+
+func Test_Failover_5xx(t *testing.T) {
+
+	f := &Failover{Endpoints: []FailoverEndpoint{
+		{Url: "https://primary/post", CancelUrl: "https://primary/cancel"},
+		{Url: "https://dr/post", CancelUrl: "https://dr/cancel"},
+	}}
+
+	var hosts []string
+	status := map[string]int{"primary": 503, "dr": 503}
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		hosts = append(hosts, r.URL.Host+r.URL.Path)
+		if s := status[r.URL.Host]; s != 200 {
+			return &http.Response{StatusCode: s, Status: http.StatusText(s), Body: http.NoBody}, nil
+		}
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y"}`)), nil
+	})
+	cli := NewClient("", "", WithHttpClient(transport))
+	cli.Failover = f
+
+	_, err := cli.Cancel(&CancelRequest{ClientNumber: "000000001", ValidationKey: "key", TransId: "1"})
+	var herr *HttpError
+	if !errors.As(err, &herr) || herr.StatusCode != 503 || fmt.Sprint(hosts) != "[primary/cancel dr/cancel]" {
+		t.Fatalf("Expected the last 503 after trying both sites but got %v from %v", err, hosts)
+	}
+
+	status["primary"] = 400
+	hosts = nil
+	cli.Failover = &Failover{Endpoints: f.Endpoints}
+	if _, err := cli.Send(getTestRequest()); err == nil || fmt.Sprint(hosts) != "[primary/post]" {
+		t.Fatalf("Expected a client error not to fail over but got %v from %v", err, hosts)
+	}
+}
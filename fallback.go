@@ -0,0 +1,85 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+)
+
+// Secondary tax engine, such as another provider or an internal rate table, taking the same
+// requests as SureTax. See SuretaxClient.Fallback.
+type FallbackEngine interface {
+	Send(ctx context.Context, req *Request) (*Response, error)
+}
+
+// Adapts a function to the FallbackEngine interface.
+type FallbackFunc func(ctx context.Context, req *Request) (*Response, error)
+
+func (f FallbackFunc) Send(ctx context.Context, req *Request) (*Response, error) {
+	return f(ctx, req)
+}
+
+// Reports whether the fallback engine should calculate a request SureTax failed with err.
+type FallbackPolicy func(err error) bool
+
+// Engages the fallback when SureTax can't be reached or fails temporarily (see IsTransient).
+func FallbackOnUnavailable(err error) bool {
+	return IsTransient(err)
+}
+
+// Also engages the fallback for authentication failures, e.g. while credentials are being rotated.
+func FallbackOnUnavailableOrAuth(err error) bool {
+	return IsTransient(err) || IsAuthError(err)
+}
+
+// Engages the fallback for every failure except invalid requests, which would fail there too.
+func FallbackOnError(err error) bool {
+	var verr *ValidationError
+	return !errors.As(err, &verr)
+}
+
+func (p FallbackPolicy) engage(err error) bool {
+	if p == nil {
+		return FallbackOnUnavailable(err)
+	}
+	return p(err)
+}
+
+// Calculates req with the fallback engine after SureTax failed with cause.
+// Reports false when the fallback failed too, in which case the SureTax error stands,
+// and for final requests SureTax may have recorded when they would be queued, see estimateOnFailure.
+func (c *SuretaxClient) sendFallback(ctx context.Context, req *Request, cause error) (*Response, bool) {
+	queue := c.Outbox != nil && req.ReturnFileCode != "Q"
+	if queue {
+		if !notSent(cause) {
+			logger.Warn("SureTax may have recorded the request, not queueing it", "ClientTracking", req.ClientTracking, "Error", cause)
+			return nil, false
+		}
+		if req.STAN == "" {
+			req.STAN = NewStan()
+		}
+	}
+
+	res, err := c.Fallback.Send(ctx, req)
+	if err == nil && res == nil {
+		err = errors.New("Fallback engine returned no response")
+	}
+	if err != nil {
+		logger.Warn("Fallback failed", "ClientTracking", req.ClientTracking, "Error", err)
+		return nil, false
+	}
+
+	res.Fallback = true
+	if res.ClientTracking == "" {
+		res.ClientTracking = req.ClientTracking
+	}
+
+	if queue {
+		if err := c.Outbox.Enqueue(req); err != nil {
+			logger.Error("Queueing failed", "ClientTracking", req.ClientTracking, "Error", err)
+			return nil, false
+		}
+	}
+
+	logger.Warn("SureTax failed, calculated by fallback", "ClientTracking", req.ClientTracking, "Error", cause)
+	return res, true
+}
@@ -0,0 +1,77 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func Test_Fallback_Send(t *testing.T) {
+
+	refused := &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	fake := &fakeHttpClient{err: refused}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	var calls int
+	engine := FallbackFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		calls++
+		if req.ItemList[0].Revenue == "0" {
+			return nil, errors.New("No rate")
+		}
+		return &Response{ResponseCode: "9999", Successful: "Y", TotalTax: "1.00"}, nil
+	})
+
+	outbox := NewMemoryOutbox()
+	cli := &SuretaxClient{Fallback: engine, Outbox: outbox}
+
+	req := getTestRequest()
+	req.ClientTracking = "track"
+	res, err := cli.Send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Fallback || res.TotalTax != "1.00" || res.ClientTracking != "track" {
+		t.Fatalf("Expected the fallback response but got %+v", res)
+	}
+	if pending := outbox.Pending(); len(pending) != 1 || pending[0].STAN == "" {
+		t.Fatalf("Expected the request to be queued with a STAN but got %+v", pending)
+	}
+
+	req = getTestRequest()
+	req.ItemList[0].Revenue = "0"
+	if _, err := cli.Send(req); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Fatalf("Expected the SureTax error when the fallback fails but got %v", err)
+	}
+
+	fake.err = io.ErrUnexpectedEOF
+	if _, err := cli.Send(getTestRequest()); !errors.Is(err, io.ErrUnexpectedEOF) || calls != 2 || len(outbox.Pending()) != 1 {
+		t.Fatalf("Expected no fallback for a request SureTax may have recorded but got %v", err)
+	}
+
+	fake.err, fake.status, fake.bodies = nil, 401, []string{""}
+	if _, err := cli.Send(getTestRequest()); !IsAuthError(err) || calls != 2 {
+		t.Fatalf("Expected the default policy to skip auth errors but got %v", err)
+	}
+
+	cli.FallbackPolicy = FallbackOnUnavailableOrAuth
+	fake.bodies = []string{""}
+	if res, err := cli.Send(getTestRequest()); err != nil || !res.Fallback {
+		t.Fatalf("Expected the fallback for auth errors but got %v", err)
+	}
+}
+
+func Test_FallbackPolicies(t *testing.T) {
+
+	verr := &ValidationError{Field: "Revenue", Message: "invalid"}
+	if FallbackOnError(verr) || !FallbackOnError(&HttpError{StatusCode: 401}) {
+		t.Fatal("Expected FallbackOnError to skip validation errors only")
+	}
+	if FallbackPolicy(nil).engage(&HttpError{StatusCode: 400}) || !FallbackPolicy(nil).engage(&HttpError{StatusCode: 503}) {
+		t.Fatal("Expected the default policy to engage for transient errors only")
+	}
+}
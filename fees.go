@@ -0,0 +1,142 @@
+package suretax
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Number of lines served at one location.
+type LocationLines struct {
+	Address Address
+	Lines   int
+}
+
+// Splits item into one item per location, so unit-based fees (E911 and other per-line fees)
+// are charged in the right jurisdiction for the right number of lines. Units is set to the line
+// count of each location with UnitType 00, and Revenue is allocated in proportion to the lines with
+// 4 decimal places or as many as Revenue has, the rounding remainder going to the last item. Line numbers are left empty for AssignLineNumbers.
+func ItemsPerLocation(item RequestItem, locations []LocationLines) ([]RequestItem, error) {
+	total := 0
+	for _, l := range locations {
+		if l.Lines <= 0 {
+			return nil, &ValidationError{LineNumber: item.LineNumber, Field: "Units", Message: "line counts must be positive"}
+		}
+		total += l.Lines
+	}
+	if total == 0 {
+		return nil, &ValidationError{LineNumber: item.LineNumber, Field: "Units", Message: "no locations"}
+	}
+
+	revenue, err := parseAmount(item.Revenue)
+	if err != nil {
+		return nil, &ValidationError{LineNumber: item.LineNumber, Field: "Revenue", Message: err.Error()}
+	}
+	decimals := splitPlaces(item.Revenue)
+
+	items := make([]RequestItem, len(locations))
+	allocated := new(big.Rat)
+	for i, l := range locations {
+		share := new(big.Rat).Mul(revenue, big.NewRat(int64(l.Lines), int64(total)))
+		amount := formatAmount(share, decimals)
+		if i == len(locations)-1 {
+			amount = formatAmount(new(big.Rat).Sub(revenue, allocated), decimals)
+		}
+		rounded, _ := parseAmount(amount)
+		allocated.Add(allocated, rounded)
+
+		it := item
+		it.LineNumber = ""
+		it.Address = l.Address
+		it.Revenue = amount
+		it.Units = strconv.Itoa(l.Lines)
+		it.UnitType = UnitTypeLines
+		items[i] = it
+	}
+
+	return items, nil
+}
+
+// Checks that Units holds a whole number of units (Format: 99999), that UnitType is a 2 digit code,
+// and that items of the same invoice, trans type and location agree on UnitType.
+// Returns one *ValidationError per problem found. See UtilityUnits.ValidateUnits for utility codes.
+func ValidateUnits(req *Request) []*ValidationError {
+	return UtilityUnits(nil).ValidateUnits(req)
+}
+
+// Same as ValidateUnits, also accepting the configured utility codes, whose Units hold a metered quantity.
+func (u UtilityUnits) ValidateUnits(req *Request) []*ValidationError {
+	var errs []*ValidationError
+
+	type chargeKey struct{ invoice, transType, postalCode, geocode string }
+	unitTypes := map[chargeKey]UnitType{}
+
+	for _, item := range req.ItemList {
+		fail := func(field, format string, a ...interface{}) {
+			errs = append(errs, &ValidationError{LineNumber: item.LineNumber, Field: field, Message: fmt.Sprintf(format, a...)})
+		}
+
+		switch {
+		case item.Units == "":
+		case u.Has(item.UnitType):
+			if r, err := parseAmount(item.Units); err != nil || r.Sign() < 0 {
+				fail("Units", "must be a non-negative quantity, got %q", item.Units)
+			}
+		default:
+			if n, err := strconv.Atoi(item.Units); err != nil || n < 0 || len(item.Units) > 5 {
+				fail("Units", "must be a whole number of units up to 99999, got %q", item.Units)
+			}
+		}
+
+		if item.UnitType != "" && !u.Valid(item.UnitType) {
+			fail("UnitType", "must be a 2 digit code, got %q", item.UnitType)
+		}
+
+		key := chargeKey{item.InvoiceNumber, item.TransTypeCode, item.Address.PostalCode, item.Address.Geocode}
+		if prev, ok := unitTypes[key]; ok && prev != item.UnitType {
+			fail("UnitType", "is %q while another item of invoice %s with trans type %s at the same location uses %q",
+				item.UnitType, item.InvoiceNumber, item.TransTypeCode, prev)
+		} else if !ok {
+			unitTypes[key] = item.UnitType
+		}
+	}
+
+	return errs
+}
+
+// A tax together with the group it was returned in.
+type GroupTax struct {
+	Group *Group
+	Tax   *Tax
+}
+
+// Taxes of a response split by how they are computed.
+type FeeView struct {
+	// Fixed fees per unit (FeeRate set), e.g. E911 per-line fees
+	UnitFees []GroupTax
+
+	// Taxes computed as a percentage of revenue (TaxRate)
+	PercentTaxes []GroupTax
+}
+
+// Returns the taxes of r split into per-unit fees and percentage taxes.
+func (r *Response) FeeView() (*FeeView, error) {
+	groups, err := r.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &FeeView{}
+	for gi := range groups {
+		g := &groups[gi]
+		for ti := range g.TaxList {
+			t := &g.TaxList[ti]
+			if t.FeeRate != 0 {
+				v.UnitFees = append(v.UnitFees, GroupTax{g, t})
+			} else {
+				v.PercentTaxes = append(v.PercentTaxes, GroupTax{g, t})
+			}
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,83 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_ItemsPerLocation(t *testing.T) {
+
+	item := getTestRequest().ItemList[0]
+	item.Revenue = "100.00"
+
+	items, err := ItemsPerLocation(item, []LocationLines{
+		{Address{PostalCode: "32034"}, 1},
+		{Address{PostalCode: "30301"}, 1},
+		{Address{PostalCode: "10001"}, 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revenues := []string{"33.3333", "33.3333", "33.3334"}
+	for i, it := range items {
+		if it.Revenue != revenues[i] || it.Units != "1" || it.UnitType != UnitTypeLines || it.LineNumber != "" {
+			t.Fatalf("Expected revenue %v for 1 line but got %+v", revenues[i], it)
+		}
+	}
+	if items[1].Address.PostalCode != "30301" || items[1].TransTypeCode != item.TransTypeCode {
+		t.Fatalf("Expected the location and other fields to be kept but got %+v", items[1])
+	}
+
+	item.Revenue = "10"
+	items, err = ItemsPerLocation(item, []LocationLines{{Address{PostalCode: "32034"}, 1}, {Address{PostalCode: "30301"}, 2}})
+	if err != nil || items[0].Revenue != "3.3333" || items[1].Revenue != "6.6667" || items[1].Units != "2" {
+		t.Fatalf("Expected revenue 3.3333/6.6667 but got %+v, %v", items, err)
+	}
+
+	if _, err := ItemsPerLocation(item, []LocationLines{{Address{}, 0}}); !IsValidationError(err) {
+		t.Fatalf("Expected a validation error for a location without lines but got %v", err)
+	}
+}
+
+func Test_ValidateUnits(t *testing.T) {
+
+	req := getTestRequest()
+	second := req.ItemList[0]
+	second.LineNumber = "2"
+	second.UnitType = "01"
+	third := req.ItemList[0]
+	third.LineNumber = "3"
+	third.Units = "1.5"
+	req.ItemList = append(req.ItemList, second, third)
+
+	errs := ValidateUnits(req)
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors but got %v", errs)
+	}
+	if errs[0].LineNumber != "2" || errs[0].Field != "UnitType" || errs[1].LineNumber != "3" || errs[1].Field != "Units" {
+		t.Fatalf("Expected a UnitType conflict on line 2 and invalid Units on line 3 but got %v", errs)
+	}
+
+	if errs := ValidateUnits(getTestRequest()); len(errs) != 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+}
+
+func Test_FeeView(t *testing.T) {
+
+	res := &Response{GroupList: []Group{{LineNumber: "1", TaxList: []Tax{
+		{TaxTypeCode: "127", TaxRate: 0.0744},
+		{TaxTypeCode: "911", FeeRate: 0.5, TaxTypeDesc: "E911"},
+	}}}}
+
+	v, err := res.FeeView()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.UnitFees) != 1 || v.UnitFees[0].Tax.TaxTypeCode != "911" || v.UnitFees[0].Group.LineNumber != "1" {
+		t.Fatalf("Expected the E911 fee as the only unit fee but got %+v", v.UnitFees)
+	}
+	if len(v.PercentTaxes) != 1 || v.PercentTaxes[0].Tax.TaxTypeCode != "127" {
+		t.Fatalf("Expected one percentage tax but got %+v", v.PercentTaxes)
+	}
+}
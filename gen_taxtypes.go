@@ -0,0 +1,44 @@
+//go:build ignore
+
+// Generates taxtypes_table.go from taxtypes.csv.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func main() {
+	f, err := os.Open("taxtypes.csv")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b := new(bytes.Buffer)
+	b.WriteString("// Code generated by gen_taxtypes.go from taxtypes.csv; DO NOT EDIT.\n\n")
+	b.WriteString("package suretax\n\n")
+	b.WriteString("var taxTypeTable = map[string]taxTypeInfo{\n")
+	for _, r := range records[1:] {
+		fmt.Fprintf(b, "\t%q: {%s, %s}, // %s\n", r[0], r[1], r[2], r[3])
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("taxtypes_table.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
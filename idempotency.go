@@ -0,0 +1,200 @@
+package suretax
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Returned for a request resent with the STAN of a request whose call failed without a response,
+// e.g. on a timeout: SureTax may have recorded it, so it isn't sent again within SuretaxClient.IdempotencyWindow.
+// Look the transaction up in SureTax, e.g. by ClientTracking, before sending it with a new STAN.
+var ErrOutcomeUnknown = errors.New("Request with this STAN was sent before and its outcome is unknown")
+
+// Response SureTax returned for a STAN, kept by an IdempotencyStore.
+type IdempotencyRecord struct {
+	// SHA-256 of the request the response was returned for, ClientTracking left out.
+	Fingerprint string
+
+	// Nil while Pending.
+	Response *Response
+
+	// Set while the request is sent and kept if the call fails without a response.
+	Pending bool
+}
+
+// Keeps the responses of sent requests by STAN, so a request resent with the same STAN gets
+// the original response back instead of being recorded by SureTax a second time. A request is stored
+// as pending before it is sent, so a resend after a timeout that may have hidden a successful call
+// is refused with ErrOutcomeUnknown. See SuretaxClient.Idempotency.
+type IdempotencyStore interface {
+	// Returns the record stored under key, nil if there is none or its window has expired.
+	Get(key string) (*IdempotencyRecord, error)
+
+	// Stores rec under key for the given window.
+	Put(key string, rec *IdempotencyRecord, window time.Duration) error
+
+	// Removes the record stored under key, if any.
+	Delete(key string) error
+}
+
+// In-memory IdempotencyStore. Safe for concurrent use.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyRecord
+	puts    int
+	now     func() time.Time
+}
+
+type memoryIdempotencyRecord struct {
+	rec     *IdempotencyRecord
+	expires time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]memoryIdempotencyRecord), now: time.Now}
+}
+
+func (s *MemoryIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if !ok || !s.now().Before(r.expires) {
+		return nil, nil
+	}
+	return r.rec, nil
+}
+
+func (s *MemoryIdempotencyStore) Put(key string, rec *IdempotencyRecord, window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	s.puts++
+	if s.puts%1024 == 0 {
+		for k, r := range s.records {
+			if !now.Before(r.expires) {
+				delete(s.records, k)
+			}
+		}
+	}
+
+	s.records[key] = memoryIdempotencyRecord{rec: rec, expires: now.Add(window)}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// Makes the client answer requests resent with a STAN it has already sent within window
+// with the stored response, see SuretaxClient.Idempotency. A zero window defaults to DefaultStanWindow.
+func WithIdempotency(store IdempotencyStore, window time.Duration) Option {
+	return func(c *SuretaxClient) {
+		c.Idempotency = store
+		c.IdempotencyWindow = window
+	}
+}
+
+func idempotencyKey(req *Request) string {
+	return req.ClientNumber + "/" + req.STAN
+}
+
+// Returns the SHA-256 of req without its ClientTracking, which SuretaxClient.GenerateClientTracking
+// sets anew every time a request is rebuilt.
+func idempotencyFingerprint(req *Request) (string, error) {
+	c := *req
+	c.ClientTracking = ""
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c *SuretaxClient) idempotencyWindow() time.Duration {
+	if c.IdempotencyWindow <= 0 {
+		return DefaultStanWindow
+	}
+	return c.IdempotencyWindow
+}
+
+// Returns the stored response for req, nil if it wasn't sent before.
+// Returns a *ValidationError if the STAN was sent with a different request
+// and ErrOutcomeUnknown if the request was sent but no response was received.
+func (c *SuretaxClient) replay(req *Request, fingerprint string) (*Response, error) {
+	rec, err := c.Idempotency.Get(idempotencyKey(req))
+	if err != nil || rec == nil {
+		return nil, err
+	}
+	if rec.Fingerprint != fingerprint {
+		return nil, &ValidationError{
+			Field:   "STAN",
+			Message: fmt.Sprintf("STAN %s was already sent with a different request", req.STAN),
+		}
+	}
+	if rec.Pending || rec.Response == nil {
+		return nil, fmt.Errorf("STAN %s: %w", req.STAN, ErrOutcomeUnknown)
+	}
+	return cloneResponse(rec.Response), nil
+}
+
+// Stores req as pending before it is sent. The request isn't sent if that fails,
+// a resend couldn't be recognized.
+func (c *SuretaxClient) markPending(req *Request, fingerprint string) error {
+	rec := &IdempotencyRecord{Fingerprint: fingerprint, Pending: true}
+	return c.Idempotency.Put(idempotencyKey(req), rec, c.idempotencyWindow())
+}
+
+// Removes the pending record of req, SureTax answered without recording it.
+func (c *SuretaxClient) forget(req *Request) {
+	if err := c.Idempotency.Delete(idempotencyKey(req)); err != nil {
+		logger.Error("Idempotency store update failed", "STAN", req.STAN, "Error", err)
+	}
+}
+
+// Stores res as the response for req. Failures are logged, the response was received anyway.
+func (c *SuretaxClient) remember(req *Request, fingerprint string, res *Response) {
+	if _, err := res.Groups(); err != nil {
+		return
+	}
+
+	rec := &IdempotencyRecord{Fingerprint: fingerprint, Response: cloneResponse(res)}
+	if err := c.Idempotency.Put(idempotencyKey(req), rec, c.idempotencyWindow()); err != nil {
+		logger.Error("Idempotency store update failed", "TransId", res.TransId, "Error", err)
+	}
+}
+
+// Returns a copy of res sharing no slices with it, so a Response reused by SendInto
+// can't overwrite it. res must have its groups decoded.
+func cloneResponse(res *Response) *Response {
+	c := *res
+	c.lazy, c.taxes, c.quoted = nil, nil, nil
+
+	c.GroupList = cloneGroups(res.GroupList)
+	if res.ItemMessages != nil {
+		c.ItemMessages = append([]ItemMessage(nil), res.ItemMessages...)
+	}
+	return &c
+}
+
+// Returns a copy of groups sharing no TaxList with them.
+func cloneGroups(groups []Group) []Group {
+	c := make([]Group, len(groups))
+	for i, g := range groups {
+		g.TaxList = append([]Tax(nil), g.TaxList...)
+		c[i] = g
+	}
+	return c
+}
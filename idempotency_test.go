@@ -0,0 +1,143 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_Idempotency(t *testing.T) {
+
+	var calls int
+	code, successful := "9999", "Y"
+	store := NewMemoryIdempotencyStore()
+	cli := NewClient("", "", WithIdempotency(store, time.Hour), WithHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return okResponse(envelope(`{"Successful":"` + successful + `","ResponseCode":"` + code + `","HeaderMessage":"Success","TransId":7,"TotalTax":"1.00",` +
+			`"GroupList":[{"LineNumber":"01","TaxList":[{"TaxTypeCode":"035","TaxAmount":"1.00"}]}]}`)), nil
+	})))
+
+	req := getTestRequest()
+	req.STAN = "STAN-1"
+	first, err := cli.Send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed := &Response{}
+	if err := cli.SendInto(req, replayed); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || !replayed.Replayed || replayed.TransId != 7 || first.Replayed {
+		t.Fatalf("Expected the response to be replayed without a call but got %+v after %v calls", replayed, calls)
+	}
+
+	replayed.GroupList[0].TaxList[0].TaxAmount = "9.99"
+	if again, _ := cli.Send(req); again.GroupList[0].TaxList[0].TaxAmount != "1.00" {
+		t.Fatal("Expected the stored response to be independent of the returned ones")
+	}
+
+	req.ItemList[0].Revenue = "200"
+	if _, err := cli.Send(req); !IsValidationError(err) || calls != 1 {
+		t.Fatalf("Expected a different request with the same STAN to be refused but got %v", err)
+	}
+
+	store.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if res, err := cli.Send(req); err != nil || res.Replayed || calls != 2 {
+		t.Fatalf("Expected an expired STAN to be sent again but got %v", err)
+	}
+	store.now = time.Now
+
+	code, successful = "1101", "N"
+	req.STAN = "STAN-2"
+	cli.Send(req)
+	cli.Send(req)
+	if calls != 4 {
+		t.Fatalf("Expected declined requests not to be stored but got %v calls", calls)
+	}
+}
+
+func Test_Idempotency_outcomeUnknown(t *testing.T) {
+
+	var calls int
+	var fail error
+	status := 200
+	cli := NewClient("", "", WithIdempotency(NewMemoryIdempotencyStore(), time.Hour), WithGeneratedIds(),
+		WithHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			if fail != nil {
+				return nil, fail
+			}
+			resp := okResponse(envelope(`{"Successful":"Y","ResponseCode":"9999","TransId":7,"TotalTax":"0","GroupList":[]}`))
+			resp.StatusCode = status
+			return resp, nil
+		})))
+
+	newRequest := func() *Request {
+		req := getTestRequest()
+		req.ClientTracking = ""
+		req.STAN = "STAN-1"
+		return req
+	}
+
+	fail = context.DeadlineExceeded
+	if _, err := cli.Send(newRequest()); err == nil {
+		t.Fatal("Expected the timeout to be returned")
+	}
+	fail = nil
+	if _, err := cli.Send(newRequest()); !errors.Is(err, ErrOutcomeUnknown) || calls != 1 {
+		t.Fatalf("Expected a resend after a timeout to be refused but got %v after %v calls", err, calls)
+	}
+
+	status = 400
+	req := newRequest()
+	req.STAN = "STAN-2"
+	if _, err := cli.Send(req); !IsValidationError(err) {
+		t.Fatalf("Expected the request to be refused but got %v", err)
+	}
+	status = 200
+	if _, err := cli.Send(req); err != nil || calls != 3 {
+		t.Fatalf("Expected a request refused with a 4xx to be sent again but got %v after %v calls", err, calls)
+	}
+
+	// Rebuilt with another generated ClientTracking
+	req = newRequest()
+	req.STAN = "STAN-2"
+	if res, err := cli.Send(req); err != nil || !res.Replayed || calls != 3 {
+		t.Fatalf("Expected the rebuilt request to be replayed but got %+v, %v", res, err)
+	}
+}
+
+func Test_Idempotency_circuitOpen(t *testing.T) {
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	status := 503
+	var calls int
+	cli := NewClient("", "", WithIdempotency(NewMemoryIdempotencyStore(), time.Hour),
+		WithHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			if status != 200 {
+				return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: http.NoBody}, nil
+			}
+			return okResponse(envelope(`{"Successful":"Y","ResponseCode":"9999","TransId":7,"TotalTax":"0","GroupList":[]}`)), nil
+		})))
+	cli.Breaker = &CircuitBreaker{FailureThreshold: 1, OpenDuration: time.Minute, now: func() time.Time { return now }}
+
+	req := getTestRequest()
+	req.STAN = "STAN-1"
+	cli.Send(req)
+
+	req = getTestRequest()
+	req.STAN = "STAN-2"
+	if _, err := cli.Send(req); !errors.Is(err, ErrCircuitOpen) || calls != 1 {
+		t.Fatalf("Expected the open circuit to refuse the call but got %v after %v calls", err, calls)
+	}
+
+	now = now.Add(time.Minute)
+	status = 200
+	if res, err := cli.Send(req); err != nil || res.Replayed || calls != 2 {
+		t.Fatalf("Expected the request refused by the breaker to be sent again but got %v after %v calls", err, calls)
+	}
+}
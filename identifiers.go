@@ -0,0 +1,106 @@
+package suretax
+
+import "strings"
+
+// Controls how InvoiceNumber and CustomerNumber values that are not alphanumeric are handled.
+type IdentifierMode int
+
+const (
+	// Identifiers are sent as provided (default).
+	IdentifierPassThrough IdentifierMode = iota
+
+	// Requests containing a non-alphanumeric identifier are rejected with a *ValidationError.
+	IdentifierReject
+
+	// Non-alphanumeric characters are stripped from identifiers.
+	IdentifierSanitize
+)
+
+// Describes an identifier rewritten by NormalizeIdentifiers.
+type IdentifierChange struct {
+	// Line number of the changed item
+	LineNumber string
+
+	// InvoiceNumber or CustomerNumber
+	Field string
+
+	// Value before sanitization
+	Original string
+
+	// Value sent to SureTax
+	Sanitized string
+}
+
+// Normalizes InvoiceNumber and CustomerNumber of every request item according to mode.
+// Per the SureTax spec both fields must be alphanumeric. Returns the list of changed values
+// when mode is IdentifierSanitize, or a *ValidationError for the first offending value
+// when mode is IdentifierReject.
+func NormalizeIdentifiers(req *Request, mode IdentifierMode) ([]IdentifierChange, error) {
+
+	if mode == IdentifierPassThrough {
+		return nil, nil
+	}
+
+	var changes []IdentifierChange
+
+	for i := range req.ItemList {
+		item := &req.ItemList[i]
+
+		fields := []struct {
+			name  string
+			value *string
+		}{
+			{"InvoiceNumber", &item.InvoiceNumber},
+			{"CustomerNumber", &item.CustomerNumber},
+		}
+
+		for _, f := range fields {
+			if isAlphanumeric(*f.value) {
+				continue
+			}
+
+			if mode == IdentifierReject {
+				return nil, &ValidationError{
+					LineNumber: item.LineNumber,
+					Field:      f.name,
+					Message:    "must be alphanumeric",
+				}
+			}
+
+			sanitized := stripNonAlphanumeric(*f.value)
+			changes = append(changes, IdentifierChange{
+				LineNumber: item.LineNumber,
+				Field:      f.name,
+				Original:   *f.value,
+				Sanitized:  sanitized,
+			})
+			*f.value = sanitized
+		}
+	}
+
+	return changes, nil
+}
+
+func isAlphanumericByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+func isAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isAlphanumericByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func stripNonAlphanumeric(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if isAlphanumericByte(s[i]) {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package suretax
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_NormalizeIdentifiers_sanitize(t *testing.T) {
+
+	req := &Request{ItemList: []RequestItem{{LineNumber: "1", InvoiceNumber: "INV-002", CustomerNumber: "001"}}}
+
+	changes, err := NormalizeIdentifiers(req, IdentifierSanitize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("Expected changes length %v but got %v", 1, len(changes))
+	}
+
+	if changes[0].Field != "InvoiceNumber" || changes[0].Original != "INV-002" {
+		t.Fatalf("Unexpected change %+v", changes[0])
+	}
+
+	if req.ItemList[0].InvoiceNumber != "INV002" {
+		t.Fatalf("Expected InvoiceNumber %v but got %v", "INV002", req.ItemList[0].InvoiceNumber)
+	}
+}
+
+func Test_NormalizeIdentifiers_reject(t *testing.T) {
+
+	req := &Request{ItemList: []RequestItem{{LineNumber: "1", CustomerNumber: "C 001"}}}
+
+	_, err := NormalizeIdentifiers(req, IdentifierReject)
+
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Expected *ValidationError but got %v", err)
+	}
+
+	if verr.Field != "CustomerNumber" {
+		t.Fatalf("Expected Field %v but got %v", "CustomerNumber", verr.Field)
+	}
+
+	if req.ItemList[0].CustomerNumber != "C 001" {
+		t.Fatal("Rejected identifiers must not be modified")
+	}
+}
+
+func Test_Send_identifierChanges(t *testing.T) {
+
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		resp := getTestResponse()
+		resp.StatusCode = http.StatusOK
+		return resp, nil
+	}))
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{IdentifierMode: IdentifierSanitize}
+	req := getTestRequest()
+	req.ItemList[0].InvoiceNumber = "INV-002"
+
+	res, err := cli.Send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.IdentifierChanges) != 1 || res.IdentifierChanges[0].Original != "INV-002" || res.IdentifierChanges[0].Sanitized != "INV002" {
+		t.Fatalf("Expected the sanitized InvoiceNumber to be reported but got %+v", res.IdentifierChanges)
+	}
+}
@@ -0,0 +1,54 @@
+package suretax
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// Crockford's base32 alphabet used by ULIDs.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Returns a new ULID: a 26 character, lexicographically sortable identifier made of
+// a 48 bit millisecond timestamp followed by 80 random bits.
+func newUlid() string {
+	var b [16]byte
+
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic("suretax: unable to read random bytes: " + err.Error())
+	}
+
+	return encodeCrockford(b[:], 26)
+}
+
+// Encodes src as big-endian Crockford base32, keeping the n least significant characters.
+func encodeCrockford(src []byte, n int) string {
+	out := make([]byte, n)
+
+	// process bits from the least significant end
+	var acc uint64
+	bits := 0
+	pos := n - 1
+	for i := len(src) - 1; i >= 0 && pos >= 0; i-- {
+		acc |= uint64(src[i]) << uint(bits)
+		bits += 8
+		for bits >= 5 && pos >= 0 {
+			out[pos] = crockfordAlphabet[acc&0x1f]
+			acc >>= 5
+			bits -= 5
+			pos--
+		}
+	}
+	for pos >= 0 {
+		out[pos] = crockfordAlphabet[acc&0x1f]
+		acc >>= 5
+		pos--
+	}
+
+	return string(out)
+}
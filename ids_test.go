@@ -0,0 +1,24 @@
+package suretax
+
+import "testing"
+
+func Test_newUlid(t *testing.T) {
+
+	a := newUlid()
+	b := newUlid()
+
+	if len(a) != 26 {
+		t.Fatalf("Expected ULID length %v but got %v", 26, len(a))
+	}
+
+	if a == b {
+		t.Fatalf("Expected unique ULIDs but got %v twice", a)
+	}
+}
+
+func Test_encodeCrockford(t *testing.T) {
+
+	if s := encodeCrockford([]byte{0x01, 0xff}, 4); s != "00FZ" {
+		t.Fatalf("Expected %v but got %v", "00FZ", s)
+	}
+}
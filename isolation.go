@@ -0,0 +1,117 @@
+package suretax
+
+import (
+	"errors"
+	"strconv"
+)
+
+// An item SureTax rejected even when sent on its own.
+type QuarantinedItem struct {
+	Item RequestItem
+
+	// Error returned for the single-item request, usually a *ResponseError.
+	Err error
+}
+
+// Outcome of SendIsolating.
+type IsolationResult struct {
+	// Merged response for every accepted item.
+	Response *Response
+
+	// Responses of the individual requests SureTax accepted. Each has its own TransId.
+	Responses []*Response
+
+	// Items isolated as the cause of a declined request.
+	Quarantined []QuarantinedItem
+}
+
+// Sends req and, if SureTax declines the whole request because of invalid item data, bisects the item list
+// and resubmits the halves until the offending items are isolated. Taxes are returned for the accepted items
+// and the rejected ones are reported in Quarantined.
+// Every accepted sub-request is a separate SureTax transaction with its own TransId and recalculated TotalRevenue.
+// Sub-requests are sent with an empty STAN unless STAN generation is enabled.
+// Only response codes caused by an item are bisected, see itemAttributable. Other errors, e.g. declines
+// for the header fields or credentials and transport failures, abort the whole operation.
+func (c *SuretaxClient) SendIsolating(req *Request) (*IsolationResult, error) {
+
+	if err := AssignLineNumbers(req); err != nil {
+		return nil, err
+	}
+
+	result := &IsolationResult{}
+
+	if err := c.sendIsolating(req, result); err != nil {
+		return nil, err
+	}
+
+	merged, err := mergeResponses(result.Responses)
+	if err != nil {
+		return nil, err
+	}
+	result.Response = merged
+
+	return result, nil
+}
+
+func (c *SuretaxClient) sendIsolating(req *Request, result *IsolationResult) error {
+
+	res, err := c.Send(req)
+	if err == nil {
+		result.Responses = append(result.Responses, res)
+		return nil
+	}
+
+	var rerr *ResponseError
+	if !errors.As(err, &rerr) || !IsValidationError(err) || !itemAttributable(rerr.ResponseCode) {
+		return err
+	}
+
+	if len(req.ItemList) == 1 {
+		result.Quarantined = append(result.Quarantined, QuarantinedItem{Item: req.ItemList[0], Err: err})
+		return nil
+	}
+
+	if len(req.ItemList) == 0 {
+		return err
+	}
+
+	mid := len(req.ItemList) / 2
+	for _, items := range [][]RequestItem{req.ItemList[:mid], req.ItemList[mid:]} {
+		sub, err := subRequest(req, items)
+		if err != nil {
+			return err
+		}
+		if err := c.sendIsolating(sub, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reports whether a declined request's response code is caused by one of its items, so bisecting
+// the item list can isolate it: 1120 "Invalid Trans Type Code" and the item codes 9100-9400 used in
+// ItemMessages. Other codes, e.g. an invalid ClientNumber, ValidationKey or DataYear, would decline
+// every sub-request and are caused by the header.
+func itemAttributable(code string) bool {
+	if code == ResponseCodeInvalidTransType {
+		return true
+	}
+	n, err := strconv.Atoi(code)
+	return err == nil && n >= 9100 && n <= 9400
+}
+
+// Returns a copy of the request header with the given items and a matching TotalRevenue.
+func subRequest(req *Request, items []RequestItem) (*Request, error) {
+	sub := *req
+	sub.ItemList = items
+	sub.STAN = ""
+
+	total, err := sumRevenue(items)
+	if err != nil {
+		return nil, err
+	}
+	sub.TotalRevenue = total
+
+	return &sub, nil
+}
@@ -0,0 +1,89 @@
+package suretax
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func Test_SendIsolating(t *testing.T) {
+
+	calls := 0
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		req := decodeTestRequest(t, r)
+
+		for _, item := range req.ItemList {
+			if item.TransTypeCode == "BAD" {
+				return okResponse(envelope(`{"ResponseCode":"1120","HeaderMessage":"Failure - Invalid Trans Type Code","Successful":"N"}`)), nil
+			}
+		}
+
+		groups := ""
+		for i, item := range req.ItemList {
+			if i > 0 {
+				groups += ","
+			}
+			groups += fmt.Sprintf(`{"LineNumber":%q,"TaxList":[{"TaxAmount":"1.50"}]}`, item.LineNumber)
+		}
+		return okResponse(envelope(fmt.Sprintf(`{"ResponseCode":"9999","Successful":"Y","TotalTax":"%.2f","TransId":%d,"GroupList":[%s]}`,
+			1.5*float64(len(req.ItemList)), calls, groups))), nil
+	}))
+	defer SetHttpClient(nil)
+
+	req := getTestRequest()
+	item := req.ItemList[0]
+	req.ItemList = nil
+	for i := 0; i < 4; i++ {
+		item.LineNumber = ""
+		item.TransTypeCode = "050104"
+		if i == 2 {
+			item.TransTypeCode = "BAD"
+		}
+		req.ItemList = append(req.ItemList, item)
+	}
+
+	cli := &SuretaxClient{}
+
+	result, err := cli.SendIsolating(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Quarantined) != 1 || result.Quarantined[0].Item.LineNumber != "3" {
+		t.Fatalf("Expected line 3 to be quarantined but got %+v", result.Quarantined)
+	}
+
+	if len(result.Response.GroupList) != 3 {
+		t.Fatalf("Expected GroupList length %v but got %v", 3, len(result.Response.GroupList))
+	}
+
+	if result.Response.TotalTax != "4.50" {
+		t.Fatalf("Expected TotalTax %v but got %v", "4.50", result.Response.TotalTax)
+	}
+
+	if len(result.Responses) != 2 {
+		t.Fatalf("Expected %v accepted requests but got %v", 2, len(result.Responses))
+	}
+}
+
+func Test_SendIsolating_requestLevel(t *testing.T) {
+
+	calls := 0
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return okResponse(envelope(`{"ResponseCode":"1101","HeaderMessage":"Failure - Invalid client number","Successful":"N"}`)), nil
+	}))
+	defer SetHttpClient(nil)
+
+	req := getTestRequest()
+	req.ItemList = append(req.ItemList, req.ItemList[0], req.ItemList[0], req.ItemList[0])
+	for i := range req.ItemList {
+		req.ItemList[i].LineNumber = ""
+	}
+
+	cli := &SuretaxClient{}
+	if _, err := cli.SendIsolating(req); !IsValidationError(err) || calls != 1 {
+		t.Fatalf("Expected a request-level decline to fail without bisecting but got %v after %v calls", err, calls)
+	}
+}
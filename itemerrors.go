@@ -0,0 +1,222 @@
+package suretax
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Broad cause of an item-level error.
+type ItemErrorCategory int
+
+const (
+	ItemErrorUnknown ItemErrorCategory = iota
+
+	// A required field was empty, e.g. "Bill To Number is Required".
+	ItemErrorMissingField
+
+	// A field value had an invalid format or value.
+	ItemErrorInvalidFormat
+
+	// Field values are individually valid but can't be used together,
+	// e.g. a trans type code not valid for the regulatory code.
+	ItemErrorUnsupportedCombination
+)
+
+func (c ItemErrorCategory) String() string {
+	switch c {
+	case ItemErrorMissingField:
+		return "missing field"
+	case ItemErrorInvalidFormat:
+		return "invalid format"
+	case ItemErrorUnsupportedCombination:
+		return "unsupported combination"
+	}
+	return "unknown"
+}
+
+// An item SureTax could not process, reported with a response code in the 9100-9400 range.
+type ItemError struct {
+	// Value corresponding to the line number in the web request
+	LineNumber string
+
+	// Value in the range 9100-9400.
+	ResponseCode string
+
+	// The error message corresponding to the ResponseCode.
+	Message string
+
+	Category ItemErrorCategory
+
+	// Path of the offending RequestItem field (e.g. "BillToNumber" or "Address.PostalCode")
+	// when it can be derived from the message. Empty otherwise.
+	Field string
+
+	// The originating request item. Nil if the line number doesn't match any item.
+	Item *RequestItem
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("SureTax item %s error %s: %s", e.LineNumber, e.ResponseCode, e.Message)
+}
+
+// Converts ItemMessages into typed ItemErrors correlated with the items of req,
+// the request this response was returned for. req may be nil.
+func (r *Response) ItemErrors(req *Request) []*ItemError {
+	if len(r.ItemMessages) == 0 {
+		return nil
+	}
+
+	errs := make([]*ItemError, len(r.ItemMessages))
+	for i, m := range r.ItemMessages {
+		errs[i] = newItemError(m, req)
+	}
+	return errs
+}
+
+// A request item SureTax could not process, together with the message it was reported with.
+type FailedItem struct {
+	// The originating request item. Nil if the line number doesn't match any item.
+	Item *RequestItem
+
+	LineNumber   string
+	ResponseCode string
+	Message      string
+}
+
+// Joins ItemMessages to the items of req, the request this response was returned for, by line number.
+// Returns one FailedItem per message, in the order SureTax reported them.
+func (r *Response) FailedItems(req *Request) []FailedItem {
+	if len(r.ItemMessages) == 0 {
+		return nil
+	}
+
+	failed := make([]FailedItem, len(r.ItemMessages))
+	for i, m := range r.ItemMessages {
+		failed[i] = FailedItem{LineNumber: m.LineNumber, ResponseCode: m.ResponseCode, Message: m.Message}
+		if req != nil {
+			failed[i].Item = findItem(req, m.LineNumber)
+		}
+	}
+	return failed
+}
+
+func newItemError(m ItemMessage, req *Request) *ItemError {
+	e := &ItemError{
+		LineNumber:   m.LineNumber,
+		ResponseCode: m.ResponseCode,
+		Message:      m.Message,
+		Category:     itemErrorCategory(m.Message),
+		Field:        itemErrorField(m.Message),
+	}
+
+	if req != nil {
+		e.Item = findItem(req, m.LineNumber)
+	}
+
+	return e
+}
+
+// Returns the item with the given line number. Line numbers are also compared ignoring
+// leading zeros, since SureTax may echo "1" for an item sent as "01".
+func findItem(req *Request, lineNumber string) *RequestItem {
+	for i := range req.ItemList {
+		if req.ItemList[i].LineNumber == lineNumber {
+			return &req.ItemList[i]
+		}
+	}
+
+	key := lineKey(lineNumber)
+	for i := range req.ItemList {
+		if key != "" && lineKey(req.ItemList[i].LineNumber) == key {
+			return &req.ItemList[i]
+		}
+	}
+
+	return nil
+}
+
+func itemErrorCategory(message string) ItemErrorCategory {
+	m := strings.ToLower(message)
+
+	switch {
+	case strings.Contains(m, "required"), strings.Contains(m, "missing"):
+		return ItemErrorMissingField
+	case strings.Contains(m, "combination"), strings.Contains(m, "not supported"),
+		strings.Contains(m, "not valid for"), strings.Contains(m, "not allowed"):
+		return ItemErrorUnsupportedCombination
+	case strings.Contains(m, "invalid"), strings.Contains(m, "format"), strings.Contains(m, "must be"):
+		return ItemErrorInvalidFormat
+	}
+
+	return ItemErrorUnknown
+}
+
+// Wording used in messages for fields whose name doesn't appear verbatim.
+var itemFieldAliases = map[string]string{
+	"zipcode":         "Address.PostalCode",
+	"zip":             "Address.PostalCode",
+	"plus4":           "Address.Plus4",
+	"zip4":            "Address.Plus4",
+	"transactiontype": "TransTypeCode",
+	"transtype":       "TransTypeCode",
+	"salestype":       "SalesTypeCode",
+	"regulatory":      "RegulatoryCode",
+	"providertype":    "RegulatoryCode",
+	"situs":           "TaxSitusRule",
+	"unittype":        "UnitType",
+	"transactiondate": "TransDate",
+	"geocode":         "Address.Geocode",
+	"exemption":       "TaxExemptionCodeList",
+}
+
+// Lower-cased RequestItem field names (and nested Address fields) mapped to their paths.
+var itemFieldNames = func() map[string]string {
+	names := make(map[string]string)
+
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				walk(ft, prefix+f.Name+".")
+				continue
+			}
+			key := strings.ToLower(f.Name)
+			if _, ok := names[key]; !ok {
+				names[key] = prefix + f.Name
+			}
+		}
+	}
+	walk(reflect.TypeOf(RequestItem{}), "")
+
+	for k, v := range itemFieldAliases {
+		names[k] = v
+	}
+
+	return names
+}()
+
+// Returns the path of the field the message refers to, preferring the longest match.
+func itemErrorField(message string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(message) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	squashed := b.String()
+
+	field, best := "", 0
+	for name, path := range itemFieldNames {
+		if len(name) > best && len(name) > 3 && strings.Contains(squashed, name) {
+			field, best = path, len(name)
+		}
+	}
+
+	return field
+}
@@ -0,0 +1,64 @@
+package suretax
+
+import "testing"
+
+func Test_ItemErrors(t *testing.T) {
+
+	req := getTestRequest()
+
+	res := &Response{ItemMessages: []ItemMessage{
+		{LineNumber: "1", Message: "Bill To Number is Required", ResponseCode: "9131"},
+		{LineNumber: "01", Message: "Invalid Zip Code", ResponseCode: "9151"},
+		{LineNumber: "7", Message: "Something went wrong", ResponseCode: "9400"},
+	}}
+
+	errs := res.ItemErrors(req)
+
+	if len(errs) != 3 {
+		t.Fatalf("Expected %v errors but got %v", 3, len(errs))
+	}
+
+	if errs[0].Category != ItemErrorMissingField || errs[0].Field != "BillToNumber" {
+		t.Fatalf("Unexpected error %+v", errs[0])
+	}
+
+	if errs[0].Item != &req.ItemList[0] {
+		t.Fatal("Expected item to be correlated ignoring leading zeros")
+	}
+
+	if errs[1].Category != ItemErrorInvalidFormat || errs[1].Field != "Address.PostalCode" {
+		t.Fatalf("Unexpected error %+v", errs[1])
+	}
+
+	if errs[2].Category != ItemErrorUnknown || errs[2].Field != "" || errs[2].Item != nil {
+		t.Fatalf("Unexpected error %+v", errs[2])
+	}
+
+	if !IsItemError(errs[2]) {
+		t.Fatal("Expected IsItemError to match *ItemError")
+	}
+}
+
+func Test_FailedItems(t *testing.T) {
+
+	req := getTestRequest()
+	res := &Response{ItemMessages: []ItemMessage{
+		{LineNumber: "1", Message: "Bill To Number is Required", ResponseCode: "9131"},
+		{LineNumber: "7", Message: "Something went wrong", ResponseCode: "9400"},
+	}}
+
+	failed := res.FailedItems(req)
+	if len(failed) != 2 {
+		t.Fatalf("Expected %v failed items but got %v", 2, len(failed))
+	}
+	if failed[0].Item != &req.ItemList[0] || failed[0].ResponseCode != "9131" || failed[0].Message != "Bill To Number is Required" {
+		t.Fatalf("Expected line 1 to be joined to item 01 but got %+v", failed[0])
+	}
+	if failed[1].Item != nil || failed[1].LineNumber != "7" {
+		t.Fatalf("Expected an unknown line to have no item but got %+v", failed[1])
+	}
+
+	if failed := (&Response{}).FailedItems(req); failed != nil {
+		t.Fatalf("Expected no failed items but got %v", failed)
+	}
+}
@@ -0,0 +1,174 @@
+package suretax
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+type lazyGroups struct {
+	raw     string
+	workers int
+
+	once sync.Once
+	err  error
+}
+
+// Decodes the header fields of inner and keeps the GroupList JSON for decoding on demand.
+// Returns false if inner has no GroupList or can't be scanned, leaving res untouched.
+func decodeHeader(inner string, res *Response, workers int) (bool, error) {
+	start, end, ok := topLevelValue(inner, "GroupList")
+	if !ok {
+		return false, nil
+	}
+
+	header := inner[:start] + "null" + inner[end:]
+	if err := decodeResponseJSON(header, res, decodeOptions{}); err != nil {
+		return true, err
+	}
+
+	if raw := inner[start:end]; raw != "null" {
+		res.lazy = &lazyGroups{raw: raw, workers: workers}
+	}
+	return true, nil
+}
+
+// Returns the bounds of the value of key in the JSON object s, matching keys case-insensitively.
+func topLevelValue(s, key string) (int, int, bool) {
+	i := skipSpace(s, 0)
+	if i >= len(s) || s[i] != '{' {
+		return 0, 0, false
+	}
+
+	for {
+		keyStart := skipSpace(s, i+1)
+		keyEnd := valueEnd(s, keyStart)
+		if keyEnd < 0 || s[keyStart] != '"' {
+			return 0, 0, false
+		}
+
+		i = skipSpace(s, keyEnd)
+		if i >= len(s) || s[i] != ':' {
+			return 0, 0, false
+		}
+
+		start := skipSpace(s, i+1)
+		end := valueEnd(s, start)
+		if end < 0 {
+			return 0, 0, false
+		}
+		if strings.EqualFold(s[keyStart+1:keyEnd-1], key) {
+			return start, end, true
+		}
+
+		i = skipSpace(s, end)
+		if i >= len(s) || s[i] != ',' {
+			return 0, 0, false
+		}
+	}
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\r' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// Returns the index just past the JSON value starting at s[i], or -1 if it's cut short.
+// The value is not validated.
+func valueEnd(s string, i int) int {
+	if i >= len(s) {
+		return -1
+	}
+
+	depth := 0
+	inString, escaped := false, false
+
+	for ; i < len(s); i++ {
+		b := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			continue
+		}
+
+		switch b {
+		case '"':
+			inString = true
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+			if depth < 0 {
+				return i
+			}
+		case ',', ' ', '\t', '\r', '\n':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	if depth == 0 && !inString {
+		return i
+	}
+	return -1
+}
+
+// Returns the GroupList, decoding it first if the response was received with SuretaxClient.LazyGroups set.
+// Safe for concurrent use; the decoded groups are kept in GroupList.
+func (r *Response) Groups() ([]Group, error) {
+	if l := r.lazy; l != nil {
+		l.once.Do(func() {
+			dec := json.NewDecoder(strings.NewReader(l.raw))
+			l.err = decodeGroups(dec, l.raw, r, l.workers)
+		})
+		if l.err != nil {
+			return nil, l.err
+		}
+	}
+	return r.GroupList, nil
+}
+
+// Calls fn with every group in order, stopping at the first error returned by fn.
+// Groups of a lazily decoded response are decoded one at a time and not kept,
+// so memory stays flat however large the response is.
+func (r *Response) EachGroup(fn func(g *Group) error) error {
+	if r.lazy == nil {
+		for i := range r.GroupList {
+			if err := fn(&r.GroupList[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(r.lazy.raw))
+	if _, err := openArray(dec, "GroupList"); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		g := Group{}
+		if err := dec.Decode(&g); err != nil {
+			return err
+		}
+		if err := fn(&g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
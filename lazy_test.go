@@ -0,0 +1,103 @@
+package suretax
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_Send_LazyGroups(t *testing.T) {
+
+	inner := decodeTestInner(t)
+	SetHttpClient(&fakeHttpClient{bodies: []string{envelope(inner)}})
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{LazyGroups: true}
+	res, err := cli.Send(getTestRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.ResponseCode != "9999" || res.TransId != 616039832 || res.TotalTax != "28.65" || len(res.ItemMessages) != 1 {
+		t.Fatalf("Expected header fields to be decoded but got %+v", res)
+	}
+	if res.GroupList != nil {
+		t.Fatalf("Expected GroupList to be left undecoded but got %v", res.GroupList)
+	}
+
+	taxes := 0
+	err = res.EachGroup(func(g *Group) error {
+		taxes += len(g.TaxList)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taxes != 4 || res.GroupList != nil {
+		t.Fatalf("Expected EachGroup to visit 4 taxes without keeping them but got %d", taxes)
+	}
+
+	expected := &Response{}
+	if err := json.Unmarshal([]byte(inner), expected); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := res.Groups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(groups, expected.GroupList) || !reflect.DeepEqual(res.GroupList, expected.GroupList) {
+		t.Fatalf("Expected groups %+v but got %+v", expected.GroupList, groups)
+	}
+}
+
+func Test_Response_Groups_Lazy(t *testing.T) {
+
+	for _, in := range []string{`{"GroupList":null}`, `{}`} {
+		res := &Response{}
+		if err := decodeResponseJSON(in, res, decodeOptions{lazy: true}); err != nil {
+			t.Fatal(err)
+		}
+		if groups, err := res.Groups(); err != nil || groups != nil {
+			t.Fatalf("Expected no groups for %s but got %v, %v", in, groups, err)
+		}
+	}
+
+	res := &Response{}
+	if err := decodeResponseJSON(`{"GroupList":[{"TaxList":"x"}]}`, res, decodeOptions{lazy: true}); err != nil {
+		t.Fatalf("Expected the header to decode but got %v", err)
+	}
+	if _, err := res.Groups(); err == nil {
+		t.Fatal("Expected an error decoding invalid groups")
+	}
+	if err := res.EachGroup(func(g *Group) error { return nil }); err == nil {
+		t.Fatal("Expected an error iterating invalid groups")
+	}
+}
+
+func Benchmark_decodeResponseJSON_Lazy_10k(b *testing.B) {
+	inner := getLargeTestInner(b, 10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := decodeResponseJSON(inner, &Response{}, decodeOptions{lazy: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Test_topLevelValue(t *testing.T) {
+
+	s := ` { "a" : "}\"," , "b":[1,{"GroupList":2}] ,"grouplist" : [ {"x":"]"} ] , "c":null}`
+	start, end, ok := topLevelValue(s, "GroupList")
+	if !ok || s[start:end] != `[ {"x":"]"} ]` {
+		t.Fatalf("Expected the top-level GroupList value but got %q, %v", s[start:end], ok)
+	}
+
+	for _, in := range []string{`{}`, `{"a":1}`, `[]`, `{"GroupList":[1,2`, ``} {
+		if _, _, ok := topLevelValue(in, "GroupList"); ok {
+			t.Fatalf("Expected no value for %s", in)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package suretax
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Lifecycle state of a transaction in the Ledger.
+type TransactionState string
+
+const (
+	// Calculated with ReturnFileCode Q. Nothing is saved by SureTax.
+	StateQuoted TransactionState = "quoted"
+
+	// Calculated with ReturnFileCode 0 and recorded for remittance.
+	StatePosted TransactionState = "posted"
+
+	// Posted more than once under the same ClientTracking.
+	StateAdjusted TransactionState = "adjusted"
+
+	// Every TransId posted under the entry was cancelled.
+	StateCancelled TransactionState = "cancelled"
+)
+
+// A single state transition recorded in a LedgerEntry.
+type StateChange struct {
+	From    TransactionState
+	To      TransactionState
+	TransId int
+	Time    time.Time
+}
+
+// Current state and history of a transaction, keyed by ClientTracking.
+// Transactions sent without ClientTracking are keyed by their TransId.
+type LedgerEntry struct {
+	Key string
+
+	ClientTracking string
+	ClientNumber   string
+	State          TransactionState
+
+	// Most recent TransId, and all TransIds posted under this entry.
+	TransId  int
+	TransIds []int
+
+	// TransIds cancelled so far. An adjusted entry stays adjusted until all of its TransIds are cancelled.
+	CancelledTransIds []int
+
+	TotalRevenue string
+	TotalTax     string
+
+	Created time.Time
+	Updated time.Time
+
+	History []StateChange
+}
+
+func (e *LedgerEntry) clone() *LedgerEntry {
+	c := *e
+	c.TransIds = append([]int(nil), e.TransIds...)
+	c.CancelledTransIds = append([]int(nil), e.CancelledTransIds...)
+	c.History = append([]StateChange(nil), e.History...)
+	return &c
+}
+
+// Selects ledger entries. Zero fields match everything.
+type LedgerFilter struct {
+	States       []TransactionState
+	ClientNumber string
+
+	// Entries updated at or after Since and before Until.
+	Since time.Time
+	Until time.Time
+}
+
+func (f LedgerFilter) match(e *LedgerEntry) bool {
+	if len(f.States) > 0 {
+		found := false
+		for _, s := range f.States {
+			if s == e.State {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ClientNumber != "" && f.ClientNumber != e.ClientNumber {
+		return false
+	}
+	if !f.Since.IsZero() && e.Updated.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Updated.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Persistence for ledger entries.
+type LedgerStore interface {
+	Get(key string) (*LedgerEntry, bool, error)
+	GetByTransId(transId int) (*LedgerEntry, bool, error)
+	Put(entry *LedgerEntry) error
+	List(filter LedgerFilter) ([]*LedgerEntry, error)
+}
+
+// In-memory LedgerStore. Safe for concurrent use.
+type MemoryLedgerStore struct {
+	mu      sync.RWMutex
+	entries map[string]*LedgerEntry
+	byId    map[int]string
+}
+
+func NewMemoryLedgerStore() *MemoryLedgerStore {
+	return &MemoryLedgerStore{entries: make(map[string]*LedgerEntry), byId: make(map[int]string)}
+}
+
+func (s *MemoryLedgerStore) Get(key string) (*LedgerEntry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	return e.clone(), true, nil
+}
+
+func (s *MemoryLedgerStore) GetByTransId(transId int) (*LedgerEntry, bool, error) {
+	s.mu.RLock()
+	key, ok := s.byId[transId]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, false, nil
+	}
+	return s.Get(key)
+}
+
+func (s *MemoryLedgerStore) Put(entry *LedgerEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.Key] = entry.clone()
+	s.byId[entry.TransId] = entry.Key
+	for _, id := range entry.TransIds {
+		s.byId[id] = entry.Key
+	}
+	return nil
+}
+
+func (s *MemoryLedgerStore) List(filter LedgerFilter) ([]*LedgerEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var list []*LedgerEntry
+	for _, e := range s.entries {
+		if filter.match(e) {
+			list = append(list, e.clone())
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Created.Before(list[j].Created) })
+	return list, nil
+}
+
+// Valid transitions of the transaction state machine.
+var allowedTransitions = map[TransactionState][]TransactionState{
+	"":             {StateQuoted, StatePosted},
+	StateQuoted:    {StateQuoted, StatePosted},
+	StatePosted:    {StateAdjusted, StateCancelled},
+	StateAdjusted:  {StateAdjusted, StateCancelled},
+	StateCancelled: nil,
+}
+
+func transitionAllowed(from, to TransactionState) bool {
+	for _, s := range allowedTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Returned when an operation would move a transaction into a state not reachable from its current one,
+// e.g. posting a cancelled transaction or cancelling a quote.
+type TransitionError struct {
+	Key  string
+	From TransactionState
+	To   TransactionState
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("Transaction %s can't move from %s to %s", e.Key, e.From, e.To)
+}
+
+// Emitted to ledger hooks after a state change has been stored.
+type LedgerEvent struct {
+	Change StateChange
+	Entry  *LedgerEntry
+}
+
+// Tracks the lifecycle of transactions sent through a client: quoted → posted → adjusted → cancelled.
+// Transitions are enforced by CheckSend and CheckCancel, which the client calls before contacting SureTax.
+type Ledger struct {
+	store LedgerStore
+
+	mu    sync.Mutex
+	now   func() time.Time
+	hooks []func(LedgerEvent)
+}
+
+// Registers fn to be called after every state change. Hooks run synchronously, in registration order.
+func (l *Ledger) OnTransition(fn func(LedgerEvent)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, fn)
+}
+
+// Returns an error if sending req would be an illegal transition, e.g. posting a cancelled transaction.
+func (l *Ledger) CheckSend(req *Request) error {
+	if req.ClientTracking == "" || req.ReturnFileCode == "Q" {
+		return nil
+	}
+
+	e, found, err := l.store.Get(req.ClientTracking)
+	if err != nil || !found {
+		return err
+	}
+
+	to := StatePosted
+	if e.State == StatePosted || e.State == StateAdjusted {
+		to = StateAdjusted
+	}
+
+	if !transitionAllowed(e.State, to) {
+		return &TransitionError{Key: e.Key, From: e.State, To: to}
+	}
+	return nil
+}
+
+// Returns an error if transId can't be cancelled: it belongs to a quote or is already cancelled.
+func (l *Ledger) CheckCancel(transId int) error {
+	e, found, err := l.store.GetByTransId(transId)
+	if err != nil || !found {
+		return err
+	}
+
+	from := e.State
+	switch {
+	case !containsTransId(e.TransIds, transId):
+		// a quote of a transaction posted later under another TransId
+		from = StateQuoted
+	case containsTransId(e.CancelledTransIds, transId):
+		from = StateCancelled
+	}
+
+	if !transitionAllowed(from, StateCancelled) {
+		return &TransitionError{Key: e.Key, From: from, To: StateCancelled}
+	}
+	return nil
+}
+
+func containsTransId(ids []int, id int) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Ledger) emit(events []LedgerEvent) {
+	l.mu.Lock()
+	hooks := l.hooks
+	l.mu.Unlock()
+
+	for _, ev := range events {
+		for _, h := range hooks {
+			h(ev)
+		}
+	}
+}
+
+func NewLedger(store LedgerStore) *Ledger {
+	return &Ledger{store: store, now: time.Now}
+}
+
+func ledgerKey(clientTracking string, transId int) string {
+	if clientTracking != "" {
+		return clientTracking
+	}
+	return "#" + strconv.Itoa(transId)
+}
+
+// Records a successful Send response for req.
+func (l *Ledger) RecordSend(req *Request, res *Response) error {
+	events, err := l.recordSend(req, res)
+	if err != nil {
+		return err
+	}
+	l.emit(events)
+	return nil
+}
+
+func (l *Ledger) recordSend(req *Request, res *Response) ([]LedgerEvent, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := ledgerKey(req.ClientTracking, res.TransId)
+
+	e, found, err := l.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := l.now()
+	if !found {
+		e = &LedgerEntry{Key: key, ClientTracking: req.ClientTracking, ClientNumber: req.ClientNumber, Created: now}
+	}
+
+	quote := req.ReturnFileCode == "Q"
+
+	var next TransactionState
+	switch {
+	case quote && (e.State == "" || e.State == StateQuoted):
+		next = StateQuoted
+	case quote:
+		// quotes don't change posted or cancelled transactions
+		return nil, nil
+	case e.State == StatePosted || e.State == StateAdjusted:
+		next = StateAdjusted
+	default:
+		next = StatePosted
+	}
+
+	e.TransId = res.TransId
+	if !quote {
+		e.TransIds = append(e.TransIds, res.TransId)
+	}
+	e.TotalRevenue = req.TotalRevenue
+	e.TotalTax = res.TotalTax
+	change, changed := l.transition(e, next, res.TransId, now)
+
+	if err := l.store.Put(e); err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	return []LedgerEvent{{Change: change, Entry: e.clone()}}, nil
+}
+
+// Records a cancel response. The entry moves to StateCancelled once all of its TransIds are cancelled.
+// Transactions unknown to the ledger are ignored.
+func (l *Ledger) RecordCancel(res *CancelResponse) error {
+	o := res.Outcome()
+	if o != CancelOutcomeCancelled && o != CancelOutcomeAlreadyCancelled {
+		return nil
+	}
+
+	l.mu.Lock()
+
+	e, found, err := l.store.GetByTransId(res.TransId)
+	if err != nil || !found || !containsTransId(e.TransIds, res.TransId) {
+		l.mu.Unlock()
+		return err
+	}
+
+	if !containsTransId(e.CancelledTransIds, res.TransId) {
+		e.CancelledTransIds = append(e.CancelledTransIds, res.TransId)
+	}
+
+	var change StateChange
+	changed := false
+	if len(e.CancelledTransIds) == len(e.TransIds) {
+		change, changed = l.transition(e, StateCancelled, res.TransId, l.now())
+	} else {
+		e.Updated = l.now()
+	}
+
+	if err := l.store.Put(e); err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	l.mu.Unlock()
+
+	if changed {
+		l.emit([]LedgerEvent{{Change: change, Entry: e.clone()}})
+	}
+	return nil
+}
+
+func (l *Ledger) transition(e *LedgerEntry, to TransactionState, transId int, now time.Time) (StateChange, bool) {
+	e.Updated = now
+
+	if e.State == to && to != StateAdjusted {
+		return StateChange{}, false
+	}
+
+	change := StateChange{From: e.State, To: to, TransId: transId, Time: now}
+	e.History = append(e.History, change)
+	e.State = to
+	return change, true
+}
+
+// Returns the entry for clientTracking.
+func (l *Ledger) Get(clientTracking string) (*LedgerEntry, bool, error) {
+	return l.store.Get(clientTracking)
+}
+
+// Returns the entry a TransId was posted under.
+func (l *Ledger) ByTransId(transId int) (*LedgerEntry, bool, error) {
+	return l.store.GetByTransId(transId)
+}
+
+// Returns all entries currently in one of the given states.
+func (l *Ledger) InState(states ...TransactionState) ([]*LedgerEntry, error) {
+	return l.store.List(LedgerFilter{States: states})
+}
+
+// Returns all entries matching filter, oldest first.
+func (l *Ledger) List(filter LedgerFilter) ([]*LedgerEntry, error) {
+	return l.store.List(filter)
+}
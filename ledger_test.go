@@ -0,0 +1,91 @@
+package suretax
+
+import "testing"
+
+func Test_Ledger(t *testing.T) {
+
+	l := NewLedger(NewMemoryLedgerStore())
+
+	req := getTestRequest()
+
+	req.ReturnFileCode = "Q"
+	if err := l.RecordSend(req, &Response{TransId: 1, TotalTax: "28.65"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req.ReturnFileCode = "0"
+	l.RecordSend(req, &Response{TransId: 2, TotalTax: "28.65"})
+	l.RecordSend(req, &Response{TransId: 3, TotalTax: "30.00"})
+
+	e, found, err := l.Get("Certi")
+	if err != nil || !found {
+		t.Fatal("Expected ledger entry")
+	}
+
+	if e.State != StateAdjusted || len(e.TransIds) != 2 || e.TotalTax != "30.00" {
+		t.Fatalf("Unexpected entry %+v", e)
+	}
+
+	l.RecordCancel(&CancelResponse{ResponseCode: "9999", TransId: 2})
+
+	e, _, _ = l.ByTransId(3)
+	if e.State != StateAdjusted || len(e.CancelledTransIds) != 1 {
+		t.Fatalf("Expected the entry to stay adjusted while TransId 3 is posted but got %+v", e)
+	}
+	if _, ok := l.CheckCancel(2).(*TransitionError); !ok {
+		t.Fatal("Expected cancelling TransId 2 again to be refused")
+	}
+	if err := l.CheckCancel(3); err != nil {
+		t.Fatalf("Expected the remaining TransId to be cancellable but got %v", err)
+	}
+	l.RecordCancel(&CancelResponse{ResponseCode: "9999", TransId: 3})
+
+	e, _, _ = l.ByTransId(3)
+	if e.State != StateCancelled {
+		t.Fatalf("Expected state %v but got %v", StateCancelled, e.State)
+	}
+
+	if len(e.History) != 4 {
+		t.Fatalf("Expected %v state changes but got %v", 4, len(e.History))
+	}
+
+	cancelled, _ := l.InState(StateCancelled)
+	if len(cancelled) != 1 {
+		t.Fatalf("Expected %v cancelled entries but got %v", 1, len(cancelled))
+	}
+}
+
+func Test_Ledger_guards(t *testing.T) {
+
+	l := NewLedger(NewMemoryLedgerStore())
+
+	var events []LedgerEvent
+	l.OnTransition(func(ev LedgerEvent) { events = append(events, ev) })
+
+	req := getTestRequest()
+	req.ReturnFileCode = "Q"
+	l.RecordSend(req, &Response{TransId: 1})
+
+	if _, ok := l.CheckCancel(1).(*TransitionError); !ok {
+		t.Fatal("Expected cancelling a quote to be refused")
+	}
+
+	req.ReturnFileCode = "0"
+	if err := l.CheckSend(req); err != nil {
+		t.Fatal(err)
+	}
+	l.RecordSend(req, &Response{TransId: 2})
+
+	if err := l.CheckCancel(2); err != nil {
+		t.Fatal(err)
+	}
+	l.RecordCancel(&CancelResponse{ResponseCode: "9999", TransId: 2})
+
+	if _, ok := l.CheckSend(req).(*TransitionError); !ok {
+		t.Fatal("Expected posting a cancelled transaction to be refused")
+	}
+
+	if len(events) != 3 || events[2].Change.To != StateCancelled || events[2].Entry.State != StateCancelled {
+		t.Fatalf("Unexpected events %+v", events)
+	}
+}
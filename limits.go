@@ -0,0 +1,117 @@
+package suretax
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Limits applied when reading and parsing responses, protecting the client against corrupted
+// proxies or hostile payloads. Zero fields are not enforced.
+type ParseLimits struct {
+	// Maximum size of the HTTP response body in bytes.
+	MaxBodySize int64
+
+	// Maximum nesting of objects and arrays in the inner response JSON.
+	MaxDepth int
+
+	// Maximum length of any string value in the inner response JSON.
+	MaxStringLength int
+
+	// Maximum number of elements of any array (GroupList, TaxList, ItemMessages).
+	MaxArrayLength int
+}
+
+// Generous limits suitable for most deployments.
+var DefaultParseLimits = ParseLimits{
+	MaxBodySize:     512 << 20,
+	MaxDepth:        16,
+	MaxStringLength: 64 << 10,
+	MaxArrayLength:  1 << 20,
+}
+
+// Returned when a response exceeds one of the configured ParseLimits.
+type LimitError struct {
+	// Name of the exceeded ParseLimits field.
+	Limit string
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("SureTax response exceeds %s of %d", e.Limit, e.Max)
+}
+
+func (l ParseLimits) depthOrLengthLimited() bool {
+	return l.MaxDepth > 0 || l.MaxStringLength > 0 || l.MaxArrayLength > 0
+}
+
+// Reads the response body, enforcing MaxBodySize.
+func (l ParseLimits) readBody(r io.Reader) ([]byte, error) {
+	if l.MaxBodySize <= 0 {
+		return ioutil.ReadAll(r)
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r, l.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > l.MaxBodySize {
+		return nil, &LimitError{Limit: "MaxBodySize", Max: l.MaxBodySize}
+	}
+	return b, nil
+}
+
+// Scans data checking nesting depth, string lengths and array lengths.
+func (l ParseLimits) check(data string) error {
+	if !l.depthOrLengthLimited() {
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(data))
+
+	// element counts of the enclosing arrays, -1 for objects
+	var stack []int
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// reported by the decoder that follows
+			return nil
+		}
+
+		if len(stack) > 0 && stack[len(stack)-1] >= 0 {
+			if d, ok := tok.(json.Delim); !ok || (d != ']' && d != '}') {
+				stack[len(stack)-1]++
+				if l.MaxArrayLength > 0 && stack[len(stack)-1] > l.MaxArrayLength {
+					return &LimitError{Limit: "MaxArrayLength", Max: int64(l.MaxArrayLength)}
+				}
+			}
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				n := -1
+				if t == '[' {
+					n = 0
+				}
+				stack = append(stack, n)
+				if l.MaxDepth > 0 && len(stack) > l.MaxDepth {
+					return &LimitError{Limit: "MaxDepth", Max: int64(l.MaxDepth)}
+				}
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if l.MaxStringLength > 0 && len(t) > l.MaxStringLength {
+				return &LimitError{Limit: "MaxStringLength", Max: int64(l.MaxStringLength)}
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package suretax
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_ParseLimits(t *testing.T) {
+
+	cases := []struct {
+		limits ParseLimits
+		limit  string
+	}{
+		{ParseLimits{MaxBodySize: 100}, "MaxBodySize"},
+		{ParseLimits{MaxDepth: 3}, "MaxDepth"},
+		{ParseLimits{MaxStringLength: 20}, "MaxStringLength"},
+		{ParseLimits{MaxArrayLength: 3}, "MaxArrayLength"},
+	}
+
+	for _, c := range cases {
+		cli := &SuretaxClient{Limits: c.limits}
+
+		_, err := cli.parseResponse(getTestResponse())
+
+		var lerr *LimitError
+		if !errors.As(err, &lerr) || lerr.Limit != c.limit {
+			t.Fatalf("Expected %v LimitError but got %v", c.limit, err)
+		}
+	}
+
+	cli := &SuretaxClient{Limits: DefaultParseLimits}
+	if _, err := cli.parseResponse(getTestResponse()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,61 @@
+package suretax
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Returns the key line numbers are matched by: lineNumber without leading zeros, "0" if it has
+// only zeros. SureTax may echo "01" as "1", so "01" and "1" are the same line.
+func lineKey(lineNumber string) string {
+	if trimmed := strings.TrimLeft(lineNumber, "0"); trimmed != "" {
+		return trimmed
+	}
+	if lineNumber != "" {
+		return "0"
+	}
+	return lineNumber
+}
+
+// Assigns sequential line numbers to items with an empty LineNumber, mirroring the numbering
+// SureTax applies server-side, and records them on the request items.
+// Returns a *ValidationError if two items share the same LineNumber, leading zeros ignored
+// (see lineKey), since responses are correlated to items by line number only.
+func AssignLineNumbers(req *Request) error {
+
+	used := make(map[string]bool, len(req.ItemList))
+
+	for _, item := range req.ItemList {
+		if item.LineNumber == "" {
+			continue
+		}
+		key := lineKey(item.LineNumber)
+		if used[key] {
+			return &ValidationError{
+				LineNumber: item.LineNumber,
+				Field:      "LineNumber",
+				Message:    "duplicate line number within request",
+			}
+		}
+		used[key] = true
+	}
+
+	next := 1
+	for i := range req.ItemList {
+		if req.ItemList[i].LineNumber != "" {
+			continue
+		}
+
+		ln := strconv.Itoa(next)
+		for used[ln] {
+			next++
+			ln = strconv.Itoa(next)
+		}
+
+		req.ItemList[i].LineNumber = ln
+		used[ln] = true
+		next++
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package suretax
+
+import "testing"
+
+func Test_AssignLineNumbers(t *testing.T) {
+
+	req := &Request{ItemList: []RequestItem{{}, {LineNumber: "1"}, {}}}
+
+	if err := AssignLineNumbers(req); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"2", "1", "3"}
+	for i, item := range req.ItemList {
+		if item.LineNumber != expected[i] {
+			t.Fatalf("Expected LineNumber %v but got %v", expected[i], item.LineNumber)
+		}
+	}
+}
+
+func Test_AssignLineNumbers_duplicate(t *testing.T) {
+
+	req := &Request{ItemList: []RequestItem{{LineNumber: "01"}, {LineNumber: "01"}}}
+
+	err := AssignLineNumbers(req)
+
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Expected *ValidationError but got %v", err)
+	}
+
+	if verr.LineNumber != "01" {
+		t.Fatalf("Expected LineNumber %v but got %v", "01", verr.LineNumber)
+	}
+}
+
+func Test_AssignLineNumbers_leadingZeros(t *testing.T) {
+
+	req := &Request{ItemList: []RequestItem{{LineNumber: "01"}, {}}}
+	if err := AssignLineNumbers(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.ItemList[1].LineNumber != "2" {
+		t.Fatalf("Expected LineNumber 2 next to 01 but got %v", req.ItemList[1].LineNumber)
+	}
+
+	req = &Request{ItemList: []RequestItem{{LineNumber: "1"}, {LineNumber: "01"}}}
+	if _, ok := AssignLineNumbers(req).(*ValidationError); !ok {
+		t.Fatal("Expected 1 and 01 to be refused as the same line")
+	}
+
+	req = &Request{ItemList: []RequestItem{{LineNumber: "0"}, {LineNumber: "00"}}}
+	if _, ok := AssignLineNumbers(req).(*ValidationError); !ok {
+		t.Fatal("Expected 0 and 00 to be refused as the same line")
+	}
+	if item := findItem(&Request{ItemList: []RequestItem{{LineNumber: "0"}}}, "00"); item == nil {
+		t.Fatal("Expected 00 to match line 0")
+	}
+}
@@ -0,0 +1,200 @@
+package suretax
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Severity of a LintFinding.
+type LintSeverity int
+
+const (
+	// SureTax would decline the request or item.
+	LintError LintSeverity = iota
+
+	// The request would be sent, but probably not as intended.
+	LintWarning
+)
+
+func (s LintSeverity) String() string {
+	if s == LintWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// A problem found by LintRequest.
+type LintFinding struct {
+	Severity LintSeverity
+
+	// Path of the field in the request, e.g. "ItemList[2].Address.PostalCode".
+	Path string
+
+	Message string
+}
+
+func (f LintFinding) String() string {
+	return f.Path + ": " + f.Severity.String() + ": " + f.Message
+}
+
+// Formats accepted by SureTax for TransDate.
+var transDateLayouts = []string{string(DateFormatUS), "01-02-2006", string(DateFormatISO)}
+
+// Runs every client-side check on req without modifying it: the header fields, the required item fields,
+// identifiers, line numbers, units and situs. Returns the findings in request order.
+// Utility UnitType codes are reported as invalid, see SuretaxClient.Lint.
+func LintRequest(req *Request) []LintFinding {
+	return lintRequest(req, nil)
+}
+
+// Same as LintRequest, accepting the utility codes configured in UtilityUnits.
+func (c *SuretaxClient) Lint(req *Request) []LintFinding {
+	return lintRequest(req, c.UtilityUnits)
+}
+
+func lintRequest(req *Request, units UtilityUnits) []LintFinding {
+	var l linter
+
+	if req.ClientNumber == "" {
+		l.warn("ClientNumber", "is empty and must be set before sending")
+	}
+	if req.ValidationKey != "" {
+		l.warn("ValidationKey", "is stored in the request, keep credentials out of data files")
+	}
+	if y, err := strconv.Atoi(req.DataYear); err != nil || len(req.DataYear) != 4 {
+		l.fail("DataYear", "must be a 4 digit year, got %q", req.DataYear)
+	} else if y < 2000 {
+		l.warn("DataYear", "%d is before the tax data SureTax keeps", y)
+	}
+	if m, err := strconv.Atoi(req.DataMonth); err != nil || m < 1 || m > 12 {
+		l.fail("DataMonth", "must be a month from 1 to 12, got %q", req.DataMonth)
+	}
+	if req.ReturnFileCode != "0" && req.ReturnFileCode != "Q" {
+		l.fail("ReturnFileCode", "must be 0 or Q, got %q", req.ReturnFileCode)
+	}
+
+	l.findings = append(l.findings, lintItems(req.ItemList, units)...)
+
+	if req.TotalRevenue != "" {
+		total, err := parseAmount(req.TotalRevenue)
+		revenues := make([]string, len(req.ItemList))
+		for i, item := range req.ItemList {
+			revenues[i] = item.Revenue
+		}
+		sum, sumErr := sumAmounts(revenues)
+		switch {
+		case err != nil:
+			l.fail("TotalRevenue", "%v", err)
+		case sumErr == nil:
+			if s, _ := parseAmount(sum); s.Cmp(total) != 0 {
+				l.warn("TotalRevenue", "is %s but the items add up to %s", req.TotalRevenue, sum)
+			}
+		}
+	}
+
+	return l.findings
+}
+
+// Runs the item checks of LintRequest on items, e.g. the rows of a CSV feed.
+func LintItems(items []RequestItem) []LintFinding {
+	return lintItems(items, nil)
+}
+
+func lintItems(items []RequestItem, units UtilityUnits) []LintFinding {
+	var l linter
+	if len(items) == 0 {
+		l.fail("ItemList", "is empty")
+		return l.findings
+	}
+
+	index := make(map[string]int, len(items))
+	for i := range items {
+		item := &items[i]
+		l.prefix = fmt.Sprintf("ItemList[%d].", i)
+
+		if item.LineNumber != "" {
+			if first, ok := index[item.LineNumber]; ok {
+				l.fail("LineNumber", "%q is also used by ItemList[%d]", item.LineNumber, first)
+			} else {
+				index[item.LineNumber] = i
+			}
+		}
+		if len(item.LineNumber) > 40 {
+			l.fail("LineNumber", "is longer than 40 characters")
+		}
+
+		for _, f := range []struct{ name, value string }{{"InvoiceNumber", item.InvoiceNumber}, {"CustomerNumber", item.CustomerNumber}} {
+			if len(f.value) > 40 {
+				l.fail(f.name, "is longer than 40 characters")
+			}
+			if !isAlphanumeric(f.value) {
+				l.warn(f.name, "%q is not alphanumeric and is changed or refused depending on IdentifierMode", f.value)
+			}
+		}
+
+		if item.TransDate == "" {
+			l.fail("TransDate", "is required")
+		} else if !validTransDate(item.TransDate) {
+			l.fail("TransDate", "must be MM/DD/YYYY, MM-DD-YYYY or YYYY-MM-DDTHH:MM:SS, got %q", item.TransDate)
+		}
+
+		if item.Revenue == "" {
+			l.fail("Revenue", "is required")
+		} else if _, err := parseAmount(item.Revenue); err != nil {
+			l.fail("Revenue", "%v", err)
+		}
+
+		if item.TaxIncludedCode != "" && item.TaxIncludedCode != "0" && item.TaxIncludedCode != "1" {
+			l.fail("TaxIncludedCode", "must be 0 or 1, got %q", item.TaxIncludedCode)
+		}
+		for _, f := range []struct{ name, value string }{{"TaxSitusRule", string(item.TaxSitusRule)}, {"TransTypeCode", item.TransTypeCode}} {
+			if f.value == "" {
+				l.fail(f.name, "is required")
+			}
+		}
+		if item.SalesTypeCode != "" && !item.SalesTypeCode.Valid() {
+			l.fail("SalesTypeCode", "must be R, B, I or L, got %q", item.SalesTypeCode)
+		}
+		if item.RegulatoryCode != "" && !item.RegulatoryCode.Valid() {
+			l.warn("RegulatoryCode", "%q is not a known provider type", item.RegulatoryCode)
+		}
+
+		for _, err := range item.ValidateSitus() {
+			l.fail(err.Field, "%s", err.Message)
+		}
+	}
+
+	l.prefix = ""
+	for _, err := range units.ValidateUnits(&Request{ItemList: items}) {
+		l.prefix = ""
+		if i, ok := index[err.LineNumber]; ok {
+			l.prefix = fmt.Sprintf("ItemList[%d].", i)
+		}
+		l.fail(err.Field, "%s", err.Message)
+	}
+
+	return l.findings
+}
+
+func validTransDate(s string) bool {
+	for _, layout := range transDateLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+type linter struct {
+	prefix   string
+	findings []LintFinding
+}
+
+func (l *linter) fail(field, format string, a ...interface{}) {
+	l.findings = append(l.findings, LintFinding{LintError, l.prefix + field, fmt.Sprintf(format, a...)})
+}
+
+func (l *linter) warn(field, format string, a ...interface{}) {
+	l.findings = append(l.findings, LintFinding{LintWarning, l.prefix + field, fmt.Sprintf(format, a...)})
+}
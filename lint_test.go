@@ -0,0 +1,63 @@
+package suretax
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_LintRequest(t *testing.T) {
+
+	req := getTestRequest()
+	req.ValidationKey = ""
+	req.DataYear, req.DataMonth = "2026", "10"
+	for i := range req.ItemList {
+		req.ItemList[i].TransDate = "10/14/2026"
+		req.ItemList[i].TaxSitusRule = "04"
+		req.ItemList[i].TransTypeCode = "010101"
+		req.ItemList[i].Address.PostalCode = "60601"
+		req.ItemList[i].InvoiceNumber = "INV002"
+	}
+	if findings := LintRequest(req); len(findings) != 0 {
+		t.Fatalf("Expected no findings but got %v", findings)
+	}
+
+	req.TotalRevenue = "200"
+	req.ReturnFileCode = "X"
+	req.ItemList = append(req.ItemList, req.ItemList[0])
+	req.ItemList[1].Revenue = "1..0"
+	req.ItemList[1].TransDate = "2026/10/14"
+	req.ItemList[1].Address.PostalCode = "60601-1234"
+	req.ItemList[1].CustomerNumber = "CUST-1"
+	req.ItemList[1].SalesTypeCode = "X"
+	req.ItemList[1].RegulatoryCode = "42"
+
+	expected := []string{
+		"ReturnFileCode: error",
+		"ItemList[1].LineNumber: error",
+		"ItemList[1].CustomerNumber: warning",
+		"ItemList[1].TransDate: error",
+		"ItemList[1].Revenue: error",
+		"ItemList[1].SalesTypeCode: error",
+		"ItemList[1].RegulatoryCode: warning",
+		"ItemList[1].Address.PostalCode: error",
+	}
+	findings := LintRequest(req)
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings but got %v", len(expected), findings)
+	}
+	for i, f := range findings {
+		if !strings.HasPrefix(f.String(), expected[i]) {
+			t.Fatalf("Expected finding %d to start with %q but got %q", i, expected[i], f)
+		}
+	}
+
+	req.ItemList = req.ItemList[:1]
+	req.ReturnFileCode = "Q"
+	if findings := LintRequest(req); len(findings) != 1 || !strings.HasPrefix(findings[0].String(), "TotalRevenue: warning") {
+		t.Fatalf("Expected TotalRevenue not matching the items to be reported but got %v", findings)
+	}
+
+	if findings := LintItems(nil); len(findings) != 1 || findings[0].Path != "ItemList" {
+		t.Fatalf("Expected an empty ItemList to be reported but got %v", findings)
+	}
+}
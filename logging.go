@@ -1,34 +1,252 @@
 package suretax
 
-import "log"
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
 
 type Log func(...interface{})
 
+// Logging verbosity, from least to most verbose.
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+
+	// Full request and response payloads.
+	LevelTrace
+)
+
+// Structured logger receiving the package's messages with key-value fields, e.g.
+//
+//	Info("SureTax response", "TransId", 616039832, "ResponseCode", "9999", "ClientTracking", "Certi")
+//
+// *slog.Logger implements it, so suretax.SetLogger(slog.Default(), suretax.LevelInfo) sends the logs to log/slog.
+// Fields named ClientNumber or ValidationKey are redacted before they reach the logger.
+type Logger interface {
+	Debug(msg string, kv ...interface{})
+	Info(msg string, kv ...interface{})
+	Warn(msg string, kv ...interface{})
+	Error(msg string, kv ...interface{})
+}
+
+var _ Logger = (*slog.Logger)(nil)
+
 type internalLogger struct {
-	logDebug Log
 	logError Log
+	logWarn  Log
+	logInfo  Log
+	logDebug Log
+	logTrace Log
+
+	// set with SetLogger, replaces the Log funcs
+	structured Logger
+	maxLevel   Level
+}
+
+func (l internalLogger) level(level Level) Log {
+	switch level {
+	case LevelError:
+		return l.logError
+	case LevelWarn:
+		return l.logWarn
+	case LevelInfo:
+		return l.logInfo
+	case LevelDebug:
+		return l.logDebug
+	}
+	return l.logTrace
+}
+
+func (l internalLogger) enabled(level Level) bool {
+	if l.structured != nil {
+		return level <= l.maxLevel
+	}
+	return l.level(level) != nil
+}
+
+func (l internalLogger) write(level Level, msg string, kv []interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+	kv = redactFields(kv)
+
+	if l.structured == nil {
+		l.level(level)(formatFields(msg, kv))
+		return
+	}
+	switch level {
+	case LevelError:
+		l.structured.Error(msg, kv...)
+	case LevelWarn:
+		l.structured.Warn(msg, kv...)
+	case LevelInfo:
+		l.structured.Info(msg, kv...)
+	default:
+		l.structured.Debug(msg, kv...)
+	}
+}
+
+func (l internalLogger) Trace(msg string, kv ...interface{}) {
+	l.write(LevelTrace, msg, kv)
+}
+
+func (l internalLogger) Debug(msg string, kv ...interface{}) {
+	l.write(LevelDebug, msg, kv)
+}
+
+func (l internalLogger) Info(msg string, kv ...interface{}) {
+	l.write(LevelInfo, msg, kv)
+}
+
+func (l internalLogger) Warn(msg string, kv ...interface{}) {
+	l.write(LevelWarn, msg, kv)
+}
+
+func (l internalLogger) Error(msg string, kv ...interface{}) {
+	l.write(LevelError, msg, kv)
 }
 
-func (l internalLogger) Debug(v ...interface{}) {
-	if l.logDebug != nil {
-		l.logDebug(v)
+// Returns kv with the values of credential fields replaced with Redacted.
+func redactFields(kv []interface{}) []interface{} {
+	var redacted []interface{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		if key, ok := kv[i].(string); ok && secretFields[strings.ToLower(key)] {
+			if redacted == nil {
+				redacted = append([]interface{}(nil), kv...)
+			}
+			redacted[i+1] = Redacted
+		}
+	}
+	if redacted == nil {
+		return kv
 	}
+	return redacted
 }
 
-func (l internalLogger) Error(v ...interface{}) {
-	if l.logError != nil {
-		l.logError(v)
+// Lays out a message for a Log func: msg followed by "Key:" value pairs.
+func formatFields(msg string, kv []interface{}) []interface{} {
+	v := make([]interface{}, 0, 1+len(kv))
+	v = append(v, msg)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			v = append(v, kv[i])
+			break
+		}
+		v = append(v, fmt.Sprint(kv[i], ":"), kv[i+1])
 	}
+	return v
 }
 
-var logger internalLogger = internalLogger{log.Print, log.Print}
+// Payload dumps are only written once a trace logger is set.
+var logger internalLogger = internalLogger{logError: log.Print, logWarn: log.Print, logInfo: log.Print, logDebug: log.Print}
+
+// Sends the package's messages up to the given level to l, as structured key-value fields.
+// Trace messages, the request and response payloads, are sent to Debug with a Payload field.
+// Replaces the Log funcs of SetLogLevel and the Set*Logger functions; pass nil to go back to them.
+func SetLogger(l Logger, level Level) {
+	logger.structured = l
+	logger.maxLevel = level
+}
+
+// Sets the package's trace logger, which receives full request and response payloads,
+// with credentials removed by ScrubPayload.
+// Pass nil to disable trace logging (default).
+func SetTraceLogger(log Log) {
+	logger.logTrace = log
+}
 
 // Sets the package's debug logger. Pass nil to disable debug logging.
 func SetDebugLogger(log Log) {
 	logger.logDebug = log
 }
 
+// Sets the package's info logger. Pass nil to disable info logging.
+func SetInfoLogger(log Log) {
+	logger.logInfo = log
+}
+
+// Sets the package's warning logger. Pass nil to disable warning logging.
+func SetWarnLogger(log Log) {
+	logger.logWarn = log
+}
+
 // Sets the package's error logger. Pass nil to disable error logging.
 func SetErrorLogger(log Log) {
 	logger.logError = log
-}
\ No newline at end of file
+}
+
+// Sends messages of the given level and all less verbose levels to log, and disables the more verbose ones.
+func SetLogLevel(level Level, log Log) {
+	levels := []*Log{&logger.logError, &logger.logWarn, &logger.logInfo, &logger.logDebug, &logger.logTrace}
+	for i, l := range levels {
+		if Level(i) <= level {
+			*l = log
+		} else {
+			*l = nil
+		}
+	}
+}
+
+var (
+	logSampleRate  int64 = 1
+	logSampleCount int64
+)
+
+// Limits per-request logs (info and more verbose) to 1 in n successful requests.
+// Failed requests and requests with item errors are always logged in full. Pass 1 or less to log every request (default).
+func SetLogSampling(n int) {
+	if n < 1 {
+		n = 1
+	}
+	atomic.StoreInt64(&logSampleRate, int64(n))
+}
+
+type logEntry struct {
+	level Level
+	msg   string
+	kv    []interface{}
+}
+
+// Collects the logs of a single request. Sampled requests are logged immediately,
+// the others are held until the outcome is known and only written on failure.
+type callLog struct {
+	sampled bool
+	entries []logEntry
+}
+
+func newCallLog() *callLog {
+	rate := atomic.LoadInt64(&logSampleRate)
+	n := atomic.AddInt64(&logSampleCount, 1)
+	return &callLog{sampled: rate <= 1 || n%rate == 1}
+}
+
+func (l *callLog) enabled(level Level) bool {
+	return logger.enabled(level)
+}
+
+func (l *callLog) log(level Level, msg string, kv ...interface{}) {
+	if level <= LevelWarn || l.sampled {
+		logger.write(level, msg, kv)
+		return
+	}
+	if logger.enabled(level) {
+		l.entries = append(l.entries, logEntry{level, msg, kv})
+	}
+}
+
+// Writes the held logs if the request failed and discards them otherwise.
+func (l *callLog) done(failed bool) {
+	if failed {
+		for _, e := range l.entries {
+			logger.write(e.level, e.msg, e.kv)
+		}
+	}
+	l.entries = nil
+}
@@ -0,0 +1,106 @@
+package suretax
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func Test_SetLogLevel(t *testing.T) {
+
+	defer SetLogLevel(LevelError, nil)
+
+	var n int
+	count := func(...interface{}) { n++ }
+
+	SetLogLevel(LevelInfo, count)
+
+	logger.Error("error")
+	logger.Warn("warn")
+	logger.Info("info")
+	logger.Debug("debug")
+	logger.Trace("trace")
+
+	if n != 3 {
+		t.Fatalf("Expected %v messages but got %v", 3, n)
+	}
+}
+
+func Test_SetLogSampling(t *testing.T) {
+
+	defer SetLogLevel(LevelError, nil)
+	defer SetLogSampling(1)
+
+	var n int
+	SetLogLevel(LevelTrace, func(...interface{}) { n++ })
+	SetLogSampling(10)
+
+	for i := 0; i < 20; i++ {
+		cl := newCallLog()
+		cl.log(LevelTrace, "payload")
+		cl.done(false)
+	}
+
+	if n != 2 {
+		t.Fatalf("Expected %v sampled messages but got %v", 2, n)
+	}
+
+	n = 0
+	for i := 0; i < 5; i++ {
+		cl := newCallLog()
+		cl.log(LevelTrace, "payload")
+		cl.done(true)
+	}
+
+	if n != 5 {
+		t.Fatalf("Expected every failed request to be logged but got %v of %v", n, 5)
+	}
+}
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) record(level, msg string, kv []interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(level, " ", msg, kv))
+}
+
+func (l *recordingLogger) Debug(msg string, kv ...interface{}) { l.record("DEBUG", msg, kv) }
+func (l *recordingLogger) Info(msg string, kv ...interface{})  { l.record("INFO", msg, kv) }
+func (l *recordingLogger) Warn(msg string, kv ...interface{})  { l.record("WARN", msg, kv) }
+func (l *recordingLogger) Error(msg string, kv ...interface{}) { l.record("ERROR", msg, kv) }
+
+func Test_SetLogger(t *testing.T) {
+
+	defer SetLogger(nil, LevelError)
+
+	rec := &recordingLogger{}
+	SetLogger(rec, LevelDebug)
+
+	logger.Info("Sent", "ClientNumber", "000000001", "TransId", 42)
+	logger.Debug("Taxes")
+	logger.Trace("Request Data", "Payload", "{}")
+
+	expected := []string{"INFO Sent[ClientNumber [REDACTED] TransId 42]", "DEBUG Taxes[]"}
+	if fmt.Sprint(rec.lines) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %q but got %q", expected, rec.lines)
+	}
+
+	var out bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&out, nil)), LevelInfo)
+
+	logger.Warn("Fallback failed", "validationKey", "secret", "ClientTracking", "Certi")
+	if line := out.String(); !strings.Contains(line, `msg="Fallback failed" validationKey=[REDACTED] ClientTracking=Certi`) {
+		t.Fatalf("Expected the structured slog record but got %v", line)
+	}
+}
+
+func Test_formatFields(t *testing.T) {
+
+	v := formatFields("SureTax response", []interface{}{"TransId", 42, "ResponseCode", "9999", "dangling"})
+	if s := fmt.Sprint(v); s != "[SureTax response TransId: 42 ResponseCode: 9999 dangling]" {
+		t.Fatalf("Expected the fields after the message but got %q", s)
+	}
+}
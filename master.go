@@ -0,0 +1,25 @@
+package suretax
+
+// Returns the TransId of the submission the response belongs to: MasterTransId,
+// or TransId when SureTax didn't report one.
+func (r *Response) Master() int {
+	if r.MasterTransId != 0 {
+		return r.MasterTransId
+	}
+	return r.TransId
+}
+
+// Groups the TransIds of responses by their master TransId, e.g. to find the parts of a
+// multi-part submission before cancelling it. Parts are kept in the order of responses;
+// responses without a TransId, such as estimates, are skipped.
+func TransIdsByMaster(responses []*Response) map[int][]int {
+	parts := make(map[int][]int)
+	for _, res := range responses {
+		if res == nil || res.TransId == 0 {
+			continue
+		}
+		master := res.Master()
+		parts[master] = append(parts[master], res.TransId)
+	}
+	return parts
+}
@@ -0,0 +1,30 @@
+package suretax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_TransIdsByMaster(t *testing.T) {
+
+	res, err := testCli.decodeResponse([]byte(envelope(`{"ResponseCode":"9999","TransId":616039833,"MasterTransId":616039832}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MasterTransId != 616039832 || res.Master() != 616039832 {
+		t.Fatalf("Expected MasterTransId 616039832 but got %+v", res)
+	}
+
+	responses := []*Response{
+		{TransId: 616039832, MasterTransId: 616039832},
+		res,
+		{TransId: 7},
+		{Estimated: true},
+		nil,
+	}
+	parts := TransIdsByMaster(responses)
+	expected := map[int][]int{616039832: {616039832, 616039833}, 7: {7}}
+	if !reflect.DeepEqual(parts, expected) {
+		t.Fatalf("Expected %v but got %v", expected, parts)
+	}
+}
@@ -0,0 +1,56 @@
+package suretax
+
+// Combines the responses of several requests made for the same logical transaction
+// into a single response: group lists and item messages are concatenated and TotalTax is summed.
+// TransId, MasterTransId, ClientTracking and STAN are taken from the first response.
+func mergeResponses(parts []*Response) (*Response, error) {
+	if len(parts) == 0 {
+		return &Response{ResponseCode: "9999", HeaderMessage: "Success", Successful: "Y", TotalTax: "0"}, nil
+	}
+
+	first := parts[0]
+	merged := &Response{
+		ClientTracking: first.ClientTracking,
+		STAN:           first.STAN,
+		TransId:        first.TransId,
+		MasterTransId:  first.MasterTransId,
+		Successful:     "Y",
+	}
+
+	taxes := make([]string, 0, len(parts))
+	groups, messages := 0, 0
+	for _, p := range parts {
+		if _, err := p.Groups(); err != nil {
+			return nil, err
+		}
+		groups += len(p.GroupList)
+		messages += len(p.ItemMessages)
+		taxes = append(taxes, p.TotalTax)
+	}
+
+	merged.GroupList = make([]Group, 0, groups)
+	if messages > 0 {
+		merged.ItemMessages = make([]ItemMessage, 0, messages)
+	}
+	for _, p := range parts {
+		merged.GroupList = append(merged.GroupList, p.GroupList...)
+		merged.ItemMessages = append(merged.ItemMessages, p.ItemMessages...)
+		merged.IdentifierChanges = append(merged.IdentifierChanges, p.IdentifierChanges...)
+	}
+
+	total, err := sumAmounts(taxes)
+	if err != nil {
+		return nil, err
+	}
+	merged.TotalTax = total
+
+	if len(merged.ItemMessages) > 0 {
+		merged.ResponseCode = "9001"
+		merged.HeaderMessage = "Success with Item errors"
+	} else {
+		merged.ResponseCode = "9999"
+		merged.HeaderMessage = "Success"
+	}
+
+	return merged, nil
+}
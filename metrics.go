@@ -0,0 +1,48 @@
+package suretax
+
+import "time"
+
+// Outcome of one Send or Cancel call, passed to Metrics.
+type CallMetrics struct {
+	// UsageEndpointSend or UsageEndpointCancel
+	Endpoint string
+
+	// ResponseCode of the SureTax response. Empty when no response was decoded,
+	// e.g. on client-side validation, network or HTTP errors.
+	ResponseCode string
+
+	// Error returned by the call.
+	Err error
+
+	Latency time.Duration
+
+	// Line items of the request, 0 for cancellations.
+	Items int
+}
+
+// Returns true unless the call failed or SureTax answered with a code other than 9999.
+func (m CallMetrics) Success() bool {
+	return m.Err == nil && m.ResponseCode == ResponseCodeSuccess
+}
+
+// Receives the outcome of every Send and Cancel of a client, set as SuretaxClient.Metrics.
+// ObserveCall is called from the goroutine making the call and must be safe for concurrent use.
+type Metrics interface {
+	ObserveCall(m CallMetrics)
+}
+
+func sendMetrics(req *Request, res *Response, err error, latency time.Duration) CallMetrics {
+	m := CallMetrics{Endpoint: UsageEndpointSend, Err: err, Latency: latency, Items: len(req.ItemList)}
+	if res != nil {
+		m.ResponseCode = res.ResponseCode
+	}
+	return m
+}
+
+func cancelMetrics(res *CancelResponse, err error, latency time.Duration) CallMetrics {
+	m := CallMetrics{Endpoint: UsageEndpointCancel, Err: err, Latency: latency}
+	if res != nil {
+		m.ResponseCode = res.ResponseCode
+	}
+	return m
+}
@@ -0,0 +1,55 @@
+package suretax
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+)
+
+type metricsRecorder struct {
+	mu    sync.Mutex
+	calls []CallMetrics
+}
+
+func (r *metricsRecorder) ObserveCall(m CallMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, m)
+}
+
+func Test_Metrics(t *testing.T) {
+
+	responses := []string{
+		`{"ResponseCode":"9999","Successful":"Y","TransId":1}`,
+		`{"ResponseCode":"1151","Successful":"N","HeaderMessage":"Failure - Invalid Validation Key"}`,
+	}
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/cancel" {
+			return &http.Response{StatusCode: 503, Status: "503 Service Unavailable", Body: http.NoBody}, nil
+		}
+		body := responses[0]
+		responses = responses[1:]
+		return okResponse(envelope(body)), nil
+	}))
+	defer SetHttpClient(nil)
+
+	rec := &metricsRecorder{}
+	cli := &SuretaxClient{Url: "https://cert/post", CancelUrl: "https://cert/cancel", Metrics: rec}
+
+	cli.Send(getTestRequest())
+	cli.Send(getTestRequest())
+	cli.Cancel(&CancelRequest{ClientNumber: "000000001", ValidationKey: "key", TransId: "1"})
+
+	if len(rec.calls) != 3 {
+		t.Fatalf("Expected 3 observed calls but got %v", len(rec.calls))
+	}
+	if m := rec.calls[0]; !m.Success() || m.Endpoint != UsageEndpointSend || m.Items != 1 || m.Latency <= 0 {
+		t.Fatalf("Expected a successful send but got %+v", m)
+	}
+	if m := rec.calls[1]; m.Success() || m.ResponseCode != "1151" || m.Err == nil {
+		t.Fatalf("Expected a declined send but got %+v", m)
+	}
+	if m := rec.calls[2]; m.Success() || m.Endpoint != UsageEndpointCancel || m.ResponseCode != "" || !IsTransient(m.Err) {
+		t.Fatalf("Expected a failed cancel but got %+v", m)
+	}
+}
@@ -0,0 +1,143 @@
+package suretax
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Parses a SureTax amount in $$$$$$$$$.CCCC format. Empty strings are zero.
+func parseAmount(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return new(big.Rat), nil
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("Invalid amount %q", s)
+	}
+	return r, nil
+}
+
+// Returns the number of digits after the decimal point of s.
+func amountDecimals(s string) int {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(strings.TrimSpace(s)) - i - 1
+	}
+	return 0
+}
+
+// Formats r with the given number of decimal places.
+func formatAmount(r *big.Rat, decimals int) string {
+	return r.FloatString(decimals)
+}
+
+// Sums amounts, formatting the result with as many decimal places as the most precise amount.
+func sumAmounts(amounts []string) (string, error) {
+	total := new(big.Rat)
+	decimals := 0
+
+	for _, a := range amounts {
+		r, err := parseAmount(a)
+		if err != nil {
+			return "", err
+		}
+		total.Add(total, r)
+
+		if d := amountDecimals(a); d > decimals {
+			decimals = d
+		}
+	}
+
+	return formatAmount(total, decimals), nil
+}
+
+// Returns the sum of item revenues, suitable for Request.TotalRevenue.
+func sumRevenue(items []RequestItem) (string, error) {
+	amounts := make([]string, len(items))
+	for i := range items {
+		amounts[i] = items[i].Revenue
+	}
+	return sumAmounts(amounts)
+}
+
+// Sets an empty TotalRevenue to the sum of the item revenues. Returns a *ValidationError if
+// TotalRevenue is set and disagrees with the sum, which SureTax would reject, or if an amount is invalid.
+func ReconcileTotalRevenue(req *Request) error {
+	for _, item := range req.ItemList {
+		if _, err := parseAmount(item.Revenue); err != nil {
+			return &ValidationError{LineNumber: item.LineNumber, Field: "Revenue", Message: err.Error()}
+		}
+	}
+	sum, _ := sumRevenue(req.ItemList)
+
+	if req.TotalRevenue == "" {
+		req.TotalRevenue = sum
+		return nil
+	}
+
+	total, err := parseAmount(req.TotalRevenue)
+	if err != nil {
+		return &ValidationError{Field: "TotalRevenue", Message: err.Error()}
+	}
+	if s, _ := parseAmount(sum); s.Cmp(total) != 0 {
+		return &ValidationError{Field: "TotalRevenue", Message: fmt.Sprintf("is %s but the items add up to %s", req.TotalRevenue, sum)}
+	}
+	return nil
+}
+
+// Decimal places of amounts set from a *big.Rat, the CCCC of SureTax's $$$$$$$$$.CCCC format.
+const amountPlaces = 4
+
+// Returns the decimal places of the parts revenue is split into: SureTax's 4, or more if revenue has more,
+// so splitting a whole amount doesn't round the parts to whole units.
+func splitPlaces(revenue string) int {
+	return max(amountDecimals(revenue), amountPlaces)
+}
+
+// Formats r in SureTax's $$$$$$$$$.CCCC format, rounded half away from zero to 4 decimal places.
+func FormatAmount(r *big.Rat) string {
+	return formatAmount(r, amountPlaces)
+}
+
+// Returns Revenue as an exact decimal. A missing Revenue is zero.
+func (item *RequestItem) RevenueDecimal() (*big.Rat, error) {
+	return parseAmount(item.Revenue)
+}
+
+// Sets Revenue to r, formatted with FormatAmount.
+func (item *RequestItem) SetRevenue(r *big.Rat) {
+	item.Revenue = FormatAmount(r)
+}
+
+// Returns TotalRevenue as an exact decimal. A missing TotalRevenue is zero.
+func (r *Request) TotalRevenueDecimal() (*big.Rat, error) {
+	return parseAmount(r.TotalRevenue)
+}
+
+// Sets TotalRevenue to total, formatted with FormatAmount.
+func (r *Request) SetTotalRevenue(total *big.Rat) {
+	r.TotalRevenue = FormatAmount(total)
+}
+
+// Returns Revenue as an exact decimal. A missing Revenue is zero.
+func (t *Tax) RevenueDecimal() (*big.Rat, error) {
+	return parseAmount(t.Revenue)
+}
+
+// Returns RevenueBase as an exact decimal. A missing RevenueBase is zero.
+func (t *Tax) RevenueBaseDecimal() (*big.Rat, error) {
+	return parseAmount(t.RevenueBase)
+}
+
+// Returns TaxAmount as an exact decimal, all five decimal places SureTax returns included.
+// A missing TaxAmount is zero.
+func (t *Tax) TaxAmountDecimal() (*big.Rat, error) {
+	return parseAmount(t.TaxAmount)
+}
+
+// Returns TaxOnTax as an exact decimal. A missing TaxOnTax is zero.
+func (t *Tax) TaxOnTaxDecimal() (*big.Rat, error) {
+	return parseAmount(t.TaxOnTax)
+}
@@ -0,0 +1,78 @@
+package suretax
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_sumAmounts(t *testing.T) {
+
+	total, err := sumAmounts([]string{"100", "-0.0050", "12.5", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != "112.4950" {
+		t.Fatalf("Expected total %v but got %v", "112.4950", total)
+	}
+
+	if _, err := sumAmounts([]string{"12,50"}); err == nil {
+		t.Fatal("Expected invalid amount to fail")
+	}
+}
+
+func Test_MoneyAccessors(t *testing.T) {
+
+	item := &RequestItem{}
+	item.SetRevenue(big.NewRat(1, 3))
+	if item.Revenue != "0.3333" {
+		t.Fatalf("Expected 0.3333 but got %v", item.Revenue)
+	}
+	if r, err := item.RevenueDecimal(); err != nil || r.Cmp(big.NewRat(3333, 10000)) != 0 {
+		t.Fatalf("Expected 0.3333 but got %v, %v", r, err)
+	}
+
+	req := &Request{}
+	req.SetTotalRevenue(big.NewRat(-5, 100000))
+	if req.TotalRevenue != "-0.0001" {
+		t.Fatalf("Expected -0.0001 but got %v", req.TotalRevenue)
+	}
+
+	tax := &Tax{TaxAmount: "1.23456", TaxOnTax: "x"}
+	if r, err := tax.TaxAmountDecimal(); err != nil || r.Cmp(big.NewRat(123456, 100000)) != 0 {
+		t.Fatalf("Expected the exact tax amount but got %v, %v", r, err)
+	}
+	if r, err := tax.RevenueBaseDecimal(); err != nil || r.Sign() != 0 {
+		t.Fatalf("Expected a missing RevenueBase to be zero but got %v, %v", r, err)
+	}
+	if _, err := tax.TaxOnTaxDecimal(); err == nil {
+		t.Fatal("Expected an invalid TaxOnTax to fail")
+	}
+}
+
+func Test_ReconcileTotalRevenue(t *testing.T) {
+
+	req := getTestRequest()
+	req.ItemList = append(req.ItemList, req.ItemList[0])
+	req.ItemList[1].LineNumber, req.ItemList[1].Revenue = "02", "0.50"
+
+	req.TotalRevenue = ""
+	if err := ReconcileTotalRevenue(req); err != nil || req.TotalRevenue != "100.50" {
+		t.Fatalf("Expected TotalRevenue 100.50 but got %v, %v", req.TotalRevenue, err)
+	}
+
+	req.TotalRevenue = "100.5000"
+	if err := ReconcileTotalRevenue(req); err != nil {
+		t.Fatalf("Expected an equal amount to pass but got %v", err)
+	}
+
+	fake := &fakeHttpClient{}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	req.TotalRevenue = "100"
+	cli := NewClient("", "", WithComputedTotalRevenue())
+	if _, err := cli.Send(req); !IsValidationError(err) || len(fake.requests) != 0 {
+		t.Fatalf("Expected a mismatching TotalRevenue to be refused but got %v", err)
+	}
+}
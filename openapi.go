@@ -0,0 +1,130 @@
+package suretax
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Returns the OpenAPI 3 document describing the Facade endpoints. The schemas are generated
+// from the Go structs, so they follow the package as it changes.
+func FacadeOpenAPI() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	ref := func(v interface{}) map[string]interface{} {
+		return openAPISchema(reflect.TypeOf(v), schemas)
+	}
+
+	errorResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": ref(FacadeError{})}},
+		}
+	}
+	post := func(summary string, req, res interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary": summary,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": ref(req)}},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Response of SureTax, including declined requests and item errors",
+						"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": ref(res)}},
+					},
+					"400": errorResponse("Invalid request"),
+					"401": errorResponse("Missing or invalid request signature"),
+					"502": errorResponse("SureTax unavailable"),
+				},
+			},
+		}
+	}
+
+	paths := map[string]interface{}{
+		FacadeSendPath:   post("Calculates the taxes of a request", Request{}, Response{}),
+		FacadeCancelPath: post("Cancels a transaction", CancelRequest{}, CancelResponse{}),
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "SureTax facade",
+			"version": "1",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Returns the schema of t, adding named structs to schemas and referencing them.
+func openAPISchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": openAPISchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": openAPISchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return openAPIObject(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			// placeholder ending recursion through self-referencing types
+			schemas[t.Name()] = nil
+			schemas[t.Name()] = openAPIObject(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+func openAPIObject(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	fields := newJsonFields(t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Tag.Get("json") == "" && structType(sf.Type).Kind() == reflect.Struct {
+			embedded := openAPIObject(structType(sf.Type), schemas)
+			for name, p := range embedded["properties"].(map[string]interface{}) {
+				props[name] = p
+			}
+			continue
+		}
+		if fields.names[i] == "" {
+			continue
+		}
+		p := openAPISchema(sf.Type, schemas)
+		if strings.Contains(sf.Tag.Get("json"), ",string") {
+			p = map[string]interface{}{"type": "string"}
+		}
+		props[fields.names[i]] = p
+	}
+	return map[string]interface{}{"type": "object", "properties": props}
+}
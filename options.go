@@ -0,0 +1,61 @@
+package suretax
+
+// Configures a SuretaxClient created with NewClient.
+type Option func(*SuretaxClient)
+
+// Returns a client posting to url and cancelUrl, configured by opts.
+//
+//	client := suretax.NewClient(url, cancelUrl, suretax.WithHttpClient(&http.Client{Transport: proxied}))
+func NewClient(url, cancelUrl string, opts ...Option) *SuretaxClient {
+	c := &SuretaxClient{Url: url, CancelUrl: cancelUrl}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Makes the client send its requests through h instead of its default http.Client.
+// Takes precedence over the package-wide SetHttpClient.
+func WithHttpClient(h HttpClient) Option {
+	return func(c *SuretaxClient) {
+		c.transport = h
+	}
+}
+
+// Same as WithHttpClient for an existing client. nil restores the default.
+// Must not be called while the client is in use.
+func (c *SuretaxClient) SetHttpClient(h HttpClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transport = h
+}
+
+// Sets the account of the client's requests, see Credentials.
+func WithCredentials(creds Credentials) Option {
+	return func(c *SuretaxClient) {
+		c.Credentials = creds
+	}
+}
+
+// Makes the client generate the STAN and ClientTracking of requests that leave them empty,
+// see SuretaxClient.GenerateStan and SuretaxClient.GenerateClientTracking.
+func WithGeneratedIds() Option {
+	return func(c *SuretaxClient) {
+		c.GenerateStan = true
+		c.GenerateClientTracking = true
+	}
+}
+
+// Makes the client fill in and check TotalRevenue, see SuretaxClient.ComputeTotalRevenue.
+func WithComputedTotalRevenue() Option {
+	return func(c *SuretaxClient) {
+		c.ComputeTotalRevenue = true
+	}
+}
+
+// Makes the client fetch its credentials from p on every call, see CredentialProvider.
+func WithCredentialProvider(p CredentialProvider) Option {
+	return func(c *SuretaxClient) {
+		c.CredentialProvider = p
+	}
+}
@@ -0,0 +1,70 @@
+package suretax
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_WithHttpClient(t *testing.T) {
+
+	var calls []string
+	transport := func(name string) HttpClient {
+		return httpClientFunc(func(r *http.Request) (*http.Response, error) {
+			calls = append(calls, name+" "+r.URL.Host)
+			return okResponse(envelope(decodeTestInner(t))), nil
+		})
+	}
+
+	cert := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport("direct")))
+	prod := NewClient("https://prod/post", "https://prod/cancel", WithHttpClient(transport("proxy")))
+
+	SetHttpClient(transport("global"))
+	defer SetHttpClient(nil)
+
+	if _, err := cert.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prod.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+
+	plain := &SuretaxClient{Url: "https://other/post"}
+	if _, err := plain.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+
+	prod.SetHttpClient(nil)
+	if _, err := prod.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"direct cert", "proxy prod", "global other", "global prod"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v but got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("Expected calls %v but got %v", expected, calls)
+		}
+	}
+}
+
+func Test_WithGeneratedIds(t *testing.T) {
+
+	cli := NewClient("", "", WithGeneratedIds(), WithHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(envelope(`{"Successful":"Y","ResponseCode":"9999","HeaderMessage":"Success","TransId":7}`)), nil
+	})))
+
+	req := getTestRequest()
+	req.ClientTracking = ""
+	res, err := cli.Send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.STAN) != 16 || len(req.ClientTracking) != 26 {
+		t.Fatalf("Expected a generated STAN and ClientTracking but got %q and %q", req.STAN, req.ClientTracking)
+	}
+	if res.STAN != req.STAN || res.ClientTracking != req.ClientTracking {
+		t.Fatalf("Expected the generated values in the response but got %q and %q", res.STAN, res.ClientTracking)
+	}
+}
@@ -0,0 +1,314 @@
+package suretax
+
+import (
+	"container/heap"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Order in which calls waiting for a PriorityLimiter are served.
+type Priority int
+
+const (
+	// e.g. nightly batch finalization
+	PriorityLow Priority = iota - 1
+
+	// Calls without a priority in their context, other than quotes.
+	PriorityNormal
+
+	// Interactive calls. Default of quotes (ReturnFileCode Q) and cancels.
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "Low"
+	case PriorityNormal:
+		return "Normal"
+	case PriorityHigh:
+		return "High"
+	}
+	return "Priority(" + strconv.Itoa(int(p)) + ")"
+}
+
+type priorityKey struct{}
+
+// Returns a context making the SuretaxClient calls made with it wait for the client Limiter with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// Returns the priority set with WithPriority, or the default for req.
+func callPriority(ctx context.Context, req *Request) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	if req != nil && req.ReturnFileCode == "Q" {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// Returns the priority set with WithPriority, or PriorityHigh: a cancel usually undoes a call made in error
+// and shouldn't wait behind a batch run.
+func cancelPriority(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityHigh
+}
+
+type tenantKey struct{}
+
+// Returns a context attributing the SuretaxClient calls made with it to tenant when scheduling
+// them on the client Limiter. Calls default to the BusinessUnit of their request.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func callTenant(ctx context.Context, req *Request) string {
+	if t, ok := ctx.Value(tenantKey{}).(string); ok {
+		return t
+	}
+	if req != nil {
+		return req.BusinessUnit
+	}
+	return ""
+}
+
+// Scheduling counters of one tenant of a PriorityLimiter.
+type TenantStats struct {
+	// Calls currently waiting and granted so far.
+	Waiting int
+	Granted int
+
+	// Total time granted calls waited.
+	WaitTime time.Duration
+}
+
+type tenantState struct {
+	weight float64
+
+	// virtual finish time of the tenant's last queued call
+	finish float64
+
+	stats TenantStats
+}
+
+type priorityWaiter struct {
+	priority Priority
+	tenant   *tenantState
+	finish   float64
+	seq      uint64
+	queued   time.Time
+	ready    chan struct{}
+	index    int
+}
+
+// Waiters ordered by priority, then virtual finish time, then arrival.
+type waiterHeap []*priorityWaiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	if h[i].finish != h[j].finish {
+		return h[i].finish < h[j].finish
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *waiterHeap) Push(x interface{}) {
+	w := x.(*priorityWaiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() interface{} {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*h = old[:len(old)-1]
+	return w
+}
+
+// Limits the calls in flight and the rate they are started at, granting waiting calls
+// in priority order so low priority batch traffic can't starve interactive calls.
+// Calls of the same priority are shared between tenants by weighted fair queuing, so one
+// tenant's backlog doesn't monopolize throughput. Safe for concurrent use.
+type PriorityLimiter struct {
+	concurrency int
+	interval    time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	next     time.Time
+	waiters  waiterHeap
+	seq      uint64
+	timer    *time.Timer
+
+	tenants map[string]*tenantState
+
+	// virtual finish time of the last granted call
+	virtual float64
+}
+
+// Returns a limiter allowing concurrency calls in flight and starting one call every interval.
+// A zero value disables the corresponding limit.
+func NewPriorityLimiter(concurrency int, interval time.Duration) *PriorityLimiter {
+	return &PriorityLimiter{concurrency: concurrency, interval: interval, tenants: make(map[string]*tenantState)}
+}
+
+// Sets the share of throughput of tenant relative to the others. Tenants default to weight 1.
+func (l *PriorityLimiter) SetTenantWeight(tenant string, weight float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if weight <= 0 {
+		weight = 1
+	}
+	l.tenant(tenant).weight = weight
+}
+
+func (l *PriorityLimiter) tenant(name string) *tenantState {
+	t, ok := l.tenants[name]
+	if !ok {
+		t = &tenantState{weight: 1}
+		l.tenants[name] = t
+	}
+	return t
+}
+
+// Returns the scheduling counters per tenant.
+func (l *PriorityLimiter) TenantStats() map[string]TenantStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[string]TenantStats, len(l.tenants))
+	for name, t := range l.tenants {
+		stats[name] = t.stats
+	}
+	return stats
+}
+
+// Blocks until a call with priority p may start. The returned function must be called once the call is done.
+func (l *PriorityLimiter) Acquire(ctx context.Context, p Priority) (func(), error) {
+	return l.AcquireTenant(ctx, p, "")
+}
+
+// Same as Acquire, for a call made on behalf of tenant.
+func (l *PriorityLimiter) AcquireTenant(ctx context.Context, p Priority, tenant string) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	l.seq++
+	t := l.tenant(tenant)
+	w := &priorityWaiter{priority: p, tenant: t, seq: l.seq, queued: time.Now(), ready: make(chan struct{})}
+	w.finish = max(l.virtual, t.finish) + 1/t.weight
+	t.finish = w.finish
+	t.stats.Waiting++
+	heap.Push(&l.waiters, w)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.releaser(), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.index < 0 {
+			// granted meanwhile
+			l.inFlight--
+			l.dispatch()
+		} else {
+			heap.Remove(&l.waiters, w.index)
+			w.tenant.stats.Waiting--
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (l *PriorityLimiter) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.inFlight--
+			l.dispatch()
+			l.mu.Unlock()
+		})
+	}
+}
+
+// Grants the waiters allowed by the limits. Called with mu held.
+func (l *PriorityLimiter) dispatch() {
+	for len(l.waiters) > 0 {
+		if l.concurrency > 0 && l.inFlight >= l.concurrency {
+			return
+		}
+		now := time.Now()
+		if l.interval > 0 && now.Before(l.next) {
+			if l.timer == nil {
+				l.timer = time.AfterFunc(l.next.Sub(now), func() {
+					l.mu.Lock()
+					l.timer = nil
+					l.dispatch()
+					l.mu.Unlock()
+				})
+			}
+			return
+		}
+
+		w := heap.Pop(&l.waiters).(*priorityWaiter)
+		l.inFlight++
+		l.next = now.Add(l.interval)
+		l.virtual = max(l.virtual, w.finish)
+		w.tenant.stats.Waiting--
+		w.tenant.stats.Granted++
+		w.tenant.stats.WaitTime += now.Sub(w.queued)
+		close(w.ready)
+	}
+}
+
+// Returns the number of calls waiting per priority.
+func (l *PriorityLimiter) Waiting() map[Priority]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	waiting := map[Priority]int{}
+	for _, w := range l.waiters {
+		waiting[w.priority]++
+	}
+	return waiting
+}
+
+// Waits for the client Limiter with priority p on behalf of tenant, and for the RateLimiter, if any.
+// The returned function releases the Limiter and may be called more than once.
+func (c *SuretaxClient) acquire(ctx context.Context, p Priority, tenant string) (func(), error) {
+	release := func() {}
+	if c.Limiter != nil {
+		var err error
+		if release, err = c.Limiter.AcquireTenant(ctx, p, tenant); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.throttle(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
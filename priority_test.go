@@ -0,0 +1,190 @@
+package suretax
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_PriorityLimiter_Order(t *testing.T) {
+
+	l := NewPriorityLimiter(1, 0)
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx, PriorityNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan Priority, 3)
+	acquire := func(p Priority) {
+		r, err := l.Acquire(ctx, p)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		order <- p
+		r()
+	}
+	waitFor := func(n int) {
+		for i := 0; i < 1000; i++ {
+			total := 0
+			for _, c := range l.Waiting() {
+				total += c
+			}
+			if total == n {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("Expected %v waiting calls", n)
+	}
+
+	go acquire(PriorityLow)
+	waitFor(1)
+	go acquire(PriorityNormal)
+	waitFor(2)
+	go acquire(PriorityHigh)
+	waitFor(3)
+
+	release()
+	release()
+
+	for _, expected := range []Priority{PriorityHigh, PriorityNormal, PriorityLow} {
+		if p := <-order; p != expected {
+			t.Fatalf("Expected %v but got %v", expected, p)
+		}
+	}
+}
+
+func Test_PriorityLimiter_Cancel(t *testing.T) {
+
+	l := NewPriorityLimiter(1, 0)
+	release, _ := l.Acquire(context.Background(), PriorityHigh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, PriorityLow); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded but got %v", err)
+	}
+	if len(l.Waiting()) != 0 {
+		t.Fatal("Expected the cancelled call to stop waiting")
+	}
+
+	release()
+	if r, err := l.Acquire(context.Background(), PriorityLow); err != nil {
+		t.Fatal(err)
+	} else {
+		r()
+	}
+}
+
+func Test_PriorityLimiter_Interval(t *testing.T) {
+
+	l := NewPriorityLimiter(0, 20*time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		r, err := l.Acquire(context.Background(), PriorityNormal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r()
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Fatalf("Expected the calls to be spaced by the interval but took %v", d)
+	}
+}
+
+func Test_callPriority(t *testing.T) {
+
+	quote := &Request{ReturnFileCode: "Q"}
+	if p := callPriority(context.Background(), quote); p != PriorityHigh {
+		t.Fatalf("Expected quotes to default to High but got %v", p)
+	}
+	if p := callPriority(WithPriority(context.Background(), PriorityLow), quote); p != PriorityLow {
+		t.Fatalf("Expected the context priority but got %v", p)
+	}
+	if p := callPriority(context.Background(), &Request{ReturnFileCode: "0"}); p != PriorityNormal {
+		t.Fatalf("Expected Normal but got %v", p)
+	}
+}
+
+func Test_PriorityLimiter_Tenants(t *testing.T) {
+
+	l := NewPriorityLimiter(1, 0)
+	ctx := context.Background()
+	release, _ := l.Acquire(ctx, PriorityLow)
+
+	order := make(chan string, 6)
+	for i, tenant := range []string{"a", "a", "a", "a", "b", "b"} {
+		go func(tenant string) {
+			r, err := l.AcquireTenant(ctx, PriorityLow, tenant)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- tenant
+			r()
+		}(tenant)
+
+		for j := 0; j < 1000; j++ {
+			s := l.TenantStats()
+			if s["a"].Waiting+s["b"].Waiting == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	release()
+
+	got := ""
+	for i := 0; i < 6; i++ {
+		got += <-order
+	}
+	if got != "ababaa" {
+		t.Fatalf("Expected the tenants to alternate but got %v", got)
+	}
+	if s := l.TenantStats()["a"]; s.Granted != 4 || s.Waiting != 0 {
+		t.Fatalf("Expected 4 granted calls for tenant a but got %+v", s)
+	}
+}
+
+func Test_callTenant(t *testing.T) {
+
+	req := &Request{BusinessUnit: "east"}
+	if tenant := callTenant(context.Background(), req); tenant != "east" {
+		t.Fatalf("Expected the BusinessUnit but got %v", tenant)
+	}
+	if tenant := callTenant(WithTenant(context.Background(), "acme"), req); tenant != "acme" {
+		t.Fatalf("Expected the context tenant but got %v", tenant)
+	}
+}
+
+func Test_PriorityLimiter_CancelCall(t *testing.T) {
+
+	fake := &fakeHttpClient{bodies: []string{envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	l := NewPriorityLimiter(1, 0)
+	cli := &SuretaxClient{Limiter: l}
+	release, _ := l.Acquire(context.Background(), PriorityLow)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := cli.Cancel(&CancelRequest{TransId: "1"})
+		done <- err
+	}()
+
+	for i := 0; i < 1000 && l.Waiting()[PriorityHigh] == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if w := l.Waiting(); w[PriorityHigh] != 1 || len(fake.requests) != 0 {
+		t.Fatalf("Expected the cancel to wait for the saturated limiter at High but got %v with %v requests", w, len(fake.requests))
+	}
+
+	release()
+	if err := <-done; err != nil || len(fake.requests) != 1 {
+		t.Fatalf("Expected the cancel to be sent once the limiter was released but got %v", err)
+	}
+}
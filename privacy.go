@@ -0,0 +1,112 @@
+package suretax
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Controls how personal data is treated in logs, debug dumps and archives.
+type PrivacyMode int
+
+const (
+	// Payloads are logged as sent and received (default).
+	PrivacyOff PrivacyMode = iota
+
+	// Phone numbers, street addresses and customer numbers are replaced with a short HMAC-SHA256
+	// keyed with a secret, so records for the same customer can still be correlated but the values
+	// can't be recovered by hashing every phone number. See SuretaxClient.PrivacyKey.
+	PrivacyHash
+
+	// Phone numbers, street addresses and customer numbers are removed.
+	PrivacyStrip
+)
+
+// Returns a copy of req with phone numbers, street address lines and customer numbers
+// hashed with key or stripped according to mode. State, postal code, amounts and codes are kept
+// for troubleshooting. The original request is not modified.
+func MinimizeRequest(req *Request, mode PrivacyMode, key []byte) *Request {
+	if mode == PrivacyOff {
+		return req
+	}
+
+	c := *req
+	c.ItemList = make([]RequestItem, len(req.ItemList))
+
+	for i, item := range req.ItemList {
+		item.CustomerNumber = minimize(item.CustomerNumber, mode, key)
+		item.OrigNumber = minimize(item.OrigNumber, mode, key)
+		item.TermNumber = minimize(item.TermNumber, mode, key)
+		item.BillToNumber = minimize(item.BillToNumber, mode, key)
+
+		item.Address.PrimaryAddressLine = minimize(item.Address.PrimaryAddressLine, mode, key)
+		item.Address.SecondaryAddressLine = minimize(item.Address.SecondaryAddressLine, mode, key)
+		item.P2PAddress.PrimaryAddressLine = minimize(item.P2PAddress.PrimaryAddressLine, mode, key)
+		item.P2PAddress.SecondaryAddressLine = minimize(item.P2PAddress.SecondaryAddressLine, mode, key)
+		if item.ServiceAddress != nil {
+			a := *item.ServiceAddress
+			a.PrimaryAddressLine = minimize(a.PrimaryAddressLine, mode, key)
+			a.SecondaryAddressLine = minimize(a.SecondaryAddressLine, mode, key)
+			item.ServiceAddress = &a
+		}
+
+		c.ItemList[i] = item
+	}
+
+	return &c
+}
+
+// Returns a copy of res with customer numbers hashed with key or stripped according to mode.
+// The original response is not modified.
+func MinimizeResponse(res *Response, mode PrivacyMode, key []byte) *Response {
+	if mode == PrivacyOff {
+		return res
+	}
+
+	// lazily received groups are decoded so that none escape minimization
+	groups, _ := res.Groups()
+
+	c := *res
+	c.lazy = nil
+	c.GroupList = make([]Group, len(groups))
+
+	for i, g := range groups {
+		g.CustomerNumber = minimize(g.CustomerNumber, mode, key)
+		c.GroupList[i] = g
+	}
+
+	return &c
+}
+
+func minimize(value string, mode PrivacyMode, key []byte) string {
+	if value == "" {
+		return ""
+	}
+
+	if mode == PrivacyStrip {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return "h:" + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// Returns PrivacyKey, or a random key generated on first use when it is empty.
+func (c *SuretaxClient) privacySecret() []byte {
+	if len(c.PrivacyKey) > 0 {
+		return c.PrivacyKey
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.privacyKey == nil {
+		c.privacyKey = make([]byte, 32)
+		if _, err := rand.Read(c.privacyKey); err != nil {
+			panic(err)
+		}
+	}
+	return c.privacyKey
+}
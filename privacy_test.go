@@ -0,0 +1,61 @@
+package suretax
+
+import "testing"
+
+func Test_MinimizeRequest(t *testing.T) {
+
+	req := getTestRequest()
+	req.ItemList[0].Address.PrimaryAddressLine = "1 Main St"
+	req.ItemList[0].Address.State = "FL"
+	req.ItemList[0].Address.PostalCode = "32034"
+
+	key := []byte("secret")
+	m := MinimizeRequest(req, PrivacyHash, key)
+
+	item := m.ItemList[0]
+
+	if item.BillToNumber == req.ItemList[0].BillToNumber || item.BillToNumber == "" {
+		t.Fatalf("Expected BillToNumber to be hashed but got %v", item.BillToNumber)
+	}
+
+	if item.OrigNumber != item.BillToNumber {
+		t.Fatal("Expected equal values to hash identically")
+	}
+
+	if other := MinimizeRequest(req, PrivacyHash, []byte("other")).ItemList[0]; other.BillToNumber == item.BillToNumber {
+		t.Fatal("Expected hashes to depend on the key")
+	}
+
+	if item.Address.PrimaryAddressLine == "1 Main St" {
+		t.Fatal("Expected PrimaryAddressLine to be hashed")
+	}
+
+	if item.Address.State != "FL" || item.Address.PostalCode != "32034" || item.Revenue != "100" {
+		t.Fatal("Expected State, PostalCode and Revenue to be preserved")
+	}
+
+	if req.ItemList[0].BillToNumber != "9043101723" {
+		t.Fatal("Original request must not be modified")
+	}
+
+	if s := MinimizeRequest(req, PrivacyStrip, key).ItemList[0].CustomerNumber; s != "" {
+		t.Fatalf("Expected CustomerNumber to be stripped but got %v", s)
+	}
+}
+
+func Test_privacySecret(t *testing.T) {
+
+	c := &SuretaxClient{}
+	key := c.privacySecret()
+	if len(key) != 32 || string(c.privacySecret()) != string(key) {
+		t.Fatalf("Expected a random key kept for the client but got %x", key)
+	}
+	if string((&SuretaxClient{}).privacySecret()) == string(key) {
+		t.Fatal("Expected every client to generate its own key")
+	}
+
+	c = &SuretaxClient{PrivacyKey: []byte("secret")}
+	if string(c.privacySecret()) != "secret" {
+		t.Fatalf("Expected PrivacyKey but got %x", c.privacySecret())
+	}
+}
@@ -0,0 +1,185 @@
+package suretax
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Period a Quota is counted over, in UTC.
+type QuotaPeriod int
+
+const (
+	QuotaDaily QuotaPeriod = iota
+	QuotaMonthly
+)
+
+func (p QuotaPeriod) String() string {
+	if p == QuotaMonthly {
+		return "month"
+	}
+	return "day"
+}
+
+func (p QuotaPeriod) bucket(t time.Time) string {
+	if p == QuotaMonthly {
+		return t.UTC().Format("2006-01")
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// Maximum number of calls per period.
+type Quota struct {
+	Period QuotaPeriod
+	Limit  int64
+
+	// Fraction of Limit, e.g. 0.8, at which OnQuota is called ahead of the limit. Zero disables the warning.
+	WarnAt float64
+}
+
+// What happens to quotes once a quota is used up. Finals are always sent.
+type QuotaAction int
+
+const (
+	// Quotes fail with a *QuotaError.
+	QuotaReject QuotaAction = iota
+
+	// Quotes are added to SuretaxClient.Outbox and fail with a *QuotaError with Queued set.
+	QuotaQueue
+)
+
+// Returned for quotes refused because a quota of their ClientNumber is used up.
+type QuotaError struct {
+	ClientNumber string
+	Quota        Quota
+
+	// Set when the request was added to the outbox.
+	Queued bool
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("Quota of %d calls per %s used up for ClientNumber %s", e.Quota.Limit, e.Quota.Period, e.ClientNumber)
+}
+
+// Passed to OnQuota when the warning level or the limit of a quota is reached.
+type QuotaEvent struct {
+	ClientNumber string
+	Quota        Quota
+
+	// Calls counted in the current period, including the one reaching the level.
+	Used int64
+
+	// Set when the limit, rather than the warning level, was reached.
+	Exhausted bool
+
+	Time time.Time
+}
+
+type quotaCounter struct {
+	bucket string
+	used   int64
+}
+
+// Enforces call quotas per ClientNumber. Set as SuretaxClient.Quotas. Safe for concurrent use.
+type QuotaEnforcer struct {
+	// Treatment of quotes over quota. Defaults to QuotaReject.
+	Action QuotaAction
+
+	// Optional callback for QuotaEvents. Called synchronously, once per level and period.
+	OnQuota func(QuotaEvent)
+
+	mu       sync.Mutex
+	quotas   map[string][]Quota
+	counters map[string][]quotaCounter
+	now      func() time.Time
+}
+
+func NewQuotaEnforcer() *QuotaEnforcer {
+	return &QuotaEnforcer{quotas: make(map[string][]Quota), counters: make(map[string][]quotaCounter), now: time.Now}
+}
+
+// Adds a quota for clientNumber. A ClientNumber may have a daily and a monthly quota.
+func (q *QuotaEnforcer) SetQuota(clientNumber string, quota Quota) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	quotas := q.quotas[clientNumber]
+	for i := range quotas {
+		if quotas[i].Period == quota.Period {
+			quotas[i] = quota
+			return
+		}
+	}
+	q.quotas[clientNumber] = append(quotas, quota)
+	q.counters[clientNumber] = append(q.counters[clientNumber], quotaCounter{})
+}
+
+// Returns the calls counted in the current period of each quota of clientNumber.
+func (q *QuotaEnforcer) Used(clientNumber string) map[QuotaPeriod]int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	used := map[QuotaPeriod]int64{}
+	for i, quota := range q.quotas[clientNumber] {
+		if c := q.counters[clientNumber][i]; c.bucket == quota.Period.bucket(now) {
+			used[quota.Period] = c.used
+		}
+	}
+	return used
+}
+
+// Counts a call of req. Returns the exhausted quota refusing it, for quotes only.
+func (q *QuotaEnforcer) take(req *Request) *Quota {
+	q.mu.Lock()
+	now := q.now()
+	quotas, counters := q.quotas[req.ClientNumber], q.counters[req.ClientNumber]
+
+	for i, quota := range quotas {
+		if b := quota.Period.bucket(now); counters[i].bucket != b {
+			counters[i] = quotaCounter{bucket: b}
+		}
+		if req.ReturnFileCode == "Q" && counters[i].used >= quota.Limit {
+			q.mu.Unlock()
+			return &quota
+		}
+	}
+
+	var events []QuotaEvent
+	for i, quota := range quotas {
+		counters[i].used++
+		used := counters[i].used
+		warn := int64(quota.WarnAt * float64(quota.Limit))
+		if quota.WarnAt > 0 && used == warn && warn < quota.Limit {
+			events = append(events, QuotaEvent{req.ClientNumber, quota, used, false, now})
+		}
+		if used == quota.Limit {
+			events = append(events, QuotaEvent{req.ClientNumber, quota, used, true, now})
+		}
+	}
+	q.mu.Unlock()
+
+	if q.OnQuota != nil {
+		for _, e := range events {
+			q.OnQuota(e)
+		}
+	}
+	return nil
+}
+
+// Checks the client quotas for req, queueing refused quotes if configured.
+func (c *SuretaxClient) checkQuota(req *Request) error {
+	exhausted := c.Quotas.take(req)
+	if exhausted == nil {
+		return nil
+	}
+
+	qerr := &QuotaError{ClientNumber: req.ClientNumber, Quota: *exhausted}
+	if c.Quotas.Action == QuotaQueue && c.Outbox != nil {
+		if err := c.Outbox.Enqueue(req); err != nil {
+			return err
+		}
+		qerr.Queued = true
+	}
+	return qerr
+}
@@ -0,0 +1,62 @@
+package suretax
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_QuotaEnforcer_Send(t *testing.T) {
+
+	ok := envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)
+	fake := &fakeHttpClient{bodies: []string{ok, ok, ok, ok}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	var events []QuotaEvent
+	quotas := NewQuotaEnforcer()
+	quotas.OnQuota = func(e QuotaEvent) { events = append(events, e) }
+	quotas.SetQuota("000000", Quota{Period: QuotaDaily, Limit: 4, WarnAt: 0.5})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	quotas.now = func() time.Time { return now }
+
+	outbox := NewMemoryOutbox()
+	cli := &SuretaxClient{Quotas: quotas, Outbox: outbox}
+	newRequest := func(code string) *Request {
+		req := getTestRequest()
+		req.ClientNumber, req.ReturnFileCode = "000000", code
+		return req
+	}
+
+	for _, code := range []string{"Q", "Q", "0", "Q"} {
+		if _, err := cli.Send(newRequest(code)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(events) != 2 || events[0].Used != 2 || events[0].Exhausted || !events[1].Exhausted {
+		t.Fatalf("Expected a warning and an exhausted event but got %+v", events)
+	}
+
+	var qerr *QuotaError
+	if _, err := cli.Send(newRequest("Q")); !errors.As(err, &qerr) || qerr.Queued {
+		t.Fatalf("Expected the quote to be rejected but got %v", err)
+	}
+
+	quotas.Action = QuotaQueue
+	if _, err := cli.Send(newRequest("Q")); !errors.As(err, &qerr) || !qerr.Queued || len(outbox.Pending()) != 1 {
+		t.Fatalf("Expected the quote to be queued but got %v", err)
+	}
+
+	fake.bodies = []string{ok}
+	if _, err := cli.Send(newRequest("0")); err != nil {
+		t.Fatalf("Expected finals to be sent over quota but got %v", err)
+	}
+	if used := quotas.Used("000000")[QuotaDaily]; used != 5 {
+		t.Fatalf("Expected 5 calls counted but got %v", used)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if used := quotas.Used("000000")[QuotaDaily]; used != 0 {
+		t.Fatalf("Expected a new period but got %v", used)
+	}
+}
@@ -0,0 +1,49 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+)
+
+type quoteCallKey struct{}
+
+func isQuoteCall(ctx context.Context) bool {
+	quote, _ := ctx.Value(quoteCallKey{}).(bool)
+	return quote
+}
+
+// Calculates the taxes of req without recording the transaction: the request is sent with ReturnFileCode Q
+// and without STAN, so it can't collide with the STAN of the final submission. req is not modified.
+// The response is marked Quoted and can be committed with FinalizeQuote.
+func (c *SuretaxClient) Quote(ctx context.Context, req *Request) (*Response, error) {
+	q := copyRequest(req)
+	q.ReturnFileCode = "Q"
+	q.STAN = ""
+
+	res, err := c.SendContext(context.WithValue(ctx, quoteCallKey{}, true), q)
+	if res != nil {
+		res.Quoted = true
+		res.quoted = q
+	}
+	return res, err
+}
+
+// Resubmits the request of a Quote response with ReturnFileCode 0, recording the transaction.
+// Line numbers and ClientTracking assigned to the quote are kept; STAN is generated if enabled.
+func (c *SuretaxClient) FinalizeQuote(ctx context.Context, quote *Response) (*Response, error) {
+	if quote == nil || quote.quoted == nil {
+		return nil, errors.New("Response was not returned by Quote")
+	}
+
+	final := copyRequest(quote.quoted)
+	final.ReturnFileCode = "0"
+
+	return c.SendContext(ctx, final)
+}
+
+// Returns a copy of req whose ItemList can be changed independently.
+func copyRequest(req *Request) *Request {
+	c := *req
+	c.ItemList = append([]RequestItem(nil), req.ItemList...)
+	return &c
+}
@@ -0,0 +1,48 @@
+package suretax
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_QuoteFinalize(t *testing.T) {
+
+	var sent []*Request
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		sent = append(sent, decodeTestRequest(t, r))
+		return okResponse(envelope(decodeTestInner(t))), nil
+	})
+
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport))
+	cli.GenerateStan = true
+	cli.GenerateClientTracking = true
+	cli.StanStore = NewMemoryDedupeStore()
+	cli.StanWindow = time.Hour
+
+	req := getTestRequest()
+	req.ClientTracking = ""
+	res, err := cli.Quote(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Quoted || sent[0].ReturnFileCode != "Q" || sent[0].STAN != "" {
+		t.Fatalf("Expected a quote without STAN but got %+v", sent[0])
+	}
+	if req.ReturnFileCode != "0" || req.ClientTracking != "" {
+		t.Fatalf("Expected the request to be left as is but got %+v", req)
+	}
+
+	final, err := cli.FinalizeQuote(context.Background(), res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Quoted || sent[1].ReturnFileCode != "0" || sent[1].STAN == "" || sent[1].ClientTracking != sent[0].ClientTracking {
+		t.Fatalf("Expected the quoted payload posted with a STAN but got %+v", sent[1])
+	}
+
+	if _, err := cli.FinalizeQuote(context.Background(), final); err == nil {
+		t.Fatal("Expected an error finalizing a response that isn't a quote")
+	}
+}
@@ -0,0 +1,239 @@
+package suretax
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long quoted taxes are reused by default.
+const DefaultQuoteCacheTTL = 5 * time.Minute
+
+// Memoizes the taxes of quoted items (ReturnFileCode Q), so previews of identical items
+// don't each call SureTax. Items are identified by their tax-relevant fields: every location a
+// TaxSitusRule may use (the billing, P2P and service addresses in full, NPA-NXX of the numbers),
+// trans type, sales type, regulatory code, units, exemptions and revenue. Safe for concurrent use.
+type QuoteCache struct {
+	ttl time.Duration
+
+	// Width of the revenue buckets, e.g. "1.00". Items whose revenue falls in the same bucket
+	// share an entry and percentage taxes are scaled to each item's revenue. Empty matches exact revenues only.
+	bucket *big.Rat
+
+	mu      sync.Mutex
+	entries map[string]quoteEntry
+	now     func() time.Time
+}
+
+type quoteEntry struct {
+	groups  []Group
+	revenue *big.Rat
+	expires time.Time
+}
+
+// Returns a cache keeping quotes for ttl (DefaultQuoteCacheTTL if 0), with revenue buckets
+// of the given width. Pass "" to only reuse quotes of items with the same revenue.
+func NewQuoteCache(ttl time.Duration, revenueBucket string) (*QuoteCache, error) {
+	if ttl == 0 {
+		ttl = DefaultQuoteCacheTTL
+	}
+	c := &QuoteCache{ttl: ttl, entries: make(map[string]quoteEntry), now: time.Now}
+	if revenueBucket != "" {
+		b, err := parseAmount(revenueBucket)
+		if err != nil {
+			return nil, err
+		}
+		if b.Sign() > 0 {
+			c.bucket = b
+		}
+	}
+	return c, nil
+}
+
+func npaNxx(number string) string {
+	if len(number) >= 6 {
+		return number[:6]
+	}
+	return number
+}
+
+// Returns the fields of a identifying its location, including the street address used when there is no ZIP.
+func quoteAddressKey(a *Address) string {
+	return addressKey(a) + "|" + a.County + "|" + a.Geocode
+}
+
+func (c *QuoteCache) key(req *Request, item *RequestItem) (string, *big.Rat, error) {
+	revenue, err := parseAmount(item.Revenue)
+	if err != nil {
+		return "", nil, err
+	}
+
+	bucket := revenue
+	if c.bucket != nil {
+		q := new(big.Rat).Quo(revenue, c.bucket)
+		floor := new(big.Int).Div(q.Num(), q.Denom())
+		bucket = new(big.Rat).Mul(new(big.Rat).SetInt(floor), c.bucket)
+	}
+
+	p2p := Address(item.P2PAddress)
+	service := ""
+	if item.ServiceAddress != nil {
+		service = quoteAddressKey(item.ServiceAddress)
+	}
+	key := strings.Join([]string{
+		req.ClientNumber, req.BusinessUnit, req.DataYear, req.DataMonth,
+		quoteAddressKey(&item.Address), quoteAddressKey(&p2p), service,
+		npaNxx(item.OrigNumber), npaNxx(item.TermNumber), npaNxx(item.BillToNumber),
+		string(item.TaxSitusRule), item.TransTypeCode, string(item.SalesTypeCode), string(item.RegulatoryCode),
+		item.TaxIncludedCode, item.Units, string(item.UnitType), item.ExemptReasonCode,
+		strings.Join(item.TaxExemptionCodeList, ","), bucket.RatString(),
+	}, "|")
+
+	return key, revenue, nil
+}
+
+// Returns the cached groups for item, relabelled and scaled to its revenue.
+func (c *QuoteCache) lookup(req *Request, item *RequestItem) ([]Group, bool) {
+	key, revenue, err := c.key(req, item)
+	if err != nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	scale := new(big.Rat).SetInt64(1)
+	if e.revenue.Cmp(revenue) != 0 && e.revenue.Sign() != 0 {
+		scale.Quo(revenue, e.revenue)
+	}
+
+	groups := cloneGroups(e.groups)
+	for i := range groups {
+		g := &groups[i]
+		g.LineNumber, g.InvoiceNumber, g.CustomerNumber = item.LineNumber, item.InvoiceNumber, item.CustomerNumber
+		for j := range g.TaxList {
+			t := &g.TaxList[j]
+			t.Revenue = item.Revenue
+			if t.FeeRate == 0 {
+				t.TaxAmount = scaleAmount(t.TaxAmount, scale)
+				t.RevenueBase = scaleAmount(t.RevenueBase, scale)
+				t.TaxOnTax = scaleAmount(t.TaxOnTax, scale)
+			}
+		}
+	}
+	return groups, true
+}
+
+func scaleAmount(s string, scale *big.Rat) string {
+	if scale.Cmp(big.NewRat(1, 1)) == 0 || s == "" {
+		return s
+	}
+	amt, err := parseAmount(s)
+	if err != nil {
+		return s
+	}
+	return formatAmount(amt.Mul(amt, scale), amountDecimals(s))
+}
+
+func (c *QuoteCache) store(req *Request, item *RequestItem, groups []Group) {
+	key, revenue, err := c.key(req, item)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The groups are also returned to the caller, who may change them.
+	c.entries[key] = quoteEntry{groups: cloneGroups(groups), revenue: revenue, expires: c.now().Add(c.ttl)}
+}
+
+// Sends only the items of a quote that aren't cached, and merges their response with the cached taxes.
+// A fully cached quote is answered locally with TransId 0.
+func (c *SuretaxClient) sendQuoteCached(ctx context.Context, req *Request) (*Response, error) {
+	if err := AssignLineNumbers(req); err != nil {
+		return nil, err
+	}
+
+	cached := &Response{}
+	var misses []RequestItem
+	for i := range req.ItemList {
+		groups, ok := c.QuoteCache.lookup(req, &req.ItemList[i])
+		if !ok {
+			misses = append(misses, req.ItemList[i])
+			continue
+		}
+		cached.GroupList = append(cached.GroupList, groups...)
+	}
+
+	var amounts []string
+	for _, g := range cached.GroupList {
+		for _, t := range g.TaxList {
+			amounts = append(amounts, t.TaxAmount)
+		}
+	}
+	total, err := sumAmounts(amounts)
+	if err != nil {
+		return nil, err
+	}
+	cached.TotalTax = total
+
+	parts := []*Response{cached}
+	if len(misses) > 0 {
+		sub, err := subRequest(req, misses)
+		if err != nil {
+			return nil, err
+		}
+		sub.STAN = req.STAN
+
+		res, err := c.send(ctx, sub, &Response{})
+		if err != nil {
+			return res, err
+		}
+		c.storeQuote(sub, res)
+
+		parts = []*Response{res, cached}
+	}
+
+	merged, err := mergeResponses(parts)
+	if err != nil {
+		return nil, err
+	}
+	if merged.ClientTracking == "" {
+		merged.ClientTracking = req.ClientTracking
+	}
+	return merged, nil
+}
+
+// Caches the groups of every item SureTax quoted without error.
+func (c *SuretaxClient) storeQuote(req *Request, res *Response) {
+	groups, err := res.Groups()
+	if err != nil {
+		return
+	}
+
+	failed := map[string]bool{}
+	for _, m := range res.ItemMessages {
+		failed[lineKey(m.LineNumber)] = true
+	}
+	byLine := map[string][]Group{}
+	for _, g := range groups {
+		byLine[lineKey(g.LineNumber)] = append(byLine[lineKey(g.LineNumber)], g)
+	}
+
+	for i := range req.ItemList {
+		item := &req.ItemList[i]
+		if l := lineKey(item.LineNumber); !failed[l] && len(byLine[l]) > 0 {
+			c.QuoteCache.store(req, item, byLine[l])
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package suretax
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_QuoteCache_Send(t *testing.T) {
+
+	body := envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1,"TotalTax":"10.50","GroupList":[{"LineNumber":"1","TaxList":[` +
+		`{"TaxTypeCode":"106","TaxAmount":"10.00","Revenue":"100","RevenueBase":"100"},` +
+		`{"TaxTypeCode":"060","TaxAmount":"0.50","FeeRate":0.5}]}]}`)
+	fake := &fakeHttpClient{bodies: []string{body, body}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	cache, err := NewQuoteCache(time.Minute, "50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	cli := &SuretaxClient{QuoteCache: cache}
+	newRequest := func(revenue string) *Request {
+		req := getTestRequest()
+		req.ReturnFileCode = "Q"
+		req.ItemList[0].Revenue = revenue
+		req.ItemList[0].Address = Address{State: "FL", PostalCode: "32034"}
+		return req
+	}
+
+	first, err := cli.Send(newRequest("100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.GroupList[0].TaxList[0].TaxAmount = "99.00"
+
+	req := newRequest("120")
+	req.ItemList[0].InvoiceNumber = "INV-2"
+	res, err := cli.Send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.requests) != 1 {
+		t.Fatalf("Expected the second quote to be served from the cache but got %v requests", len(fake.requests))
+	}
+	if res.TotalTax != "12.50" || res.TransId != 0 || res.ResponseCode != "9999" {
+		t.Fatalf("Expected TotalTax 12.50 but got %v", res.TotalTax)
+	}
+	g := res.GroupList[0]
+	if g.InvoiceNumber != "INV-2" || g.TaxList[0].RevenueBase != "120" || g.TaxList[1].TaxAmount != "0.50" {
+		t.Fatalf("Expected the cached group scaled to the item but got %+v", g)
+	}
+
+	g.TaxList[1].TaxAmount = "99.00"
+	if again, _ := cli.Send(newRequest("100")); again.TotalTax != "10.50" || again.GroupList[0].TaxList[1].TaxAmount != "0.50" {
+		t.Fatalf("Expected the cache to be independent of the returned responses but got %+v", again)
+	}
+
+	cli.Send(newRequest("150"))
+	if len(fake.requests) != 2 {
+		t.Fatal("Expected a quote in another revenue bucket to be sent")
+	}
+
+	now = now.Add(2 * time.Minute)
+	final := newRequest("100")
+	final.ReturnFileCode = "0"
+	cli.Send(final)
+	if len(fake.requests) != 3 {
+		t.Fatal("Expected non-quote requests to bypass the cache")
+	}
+}
+
+func Test_QuoteCache_Partial(t *testing.T) {
+
+	cache, _ := NewQuoteCache(0, "")
+	cli := &SuretaxClient{QuoteCache: cache}
+
+	req := getTestRequest()
+	req.ReturnFileCode = "Q"
+	other := req.ItemList[0]
+	other.LineNumber, other.TransTypeCode = "02", "010101"
+	req.ItemList = append(req.ItemList, other)
+
+	cache.store(req, &req.ItemList[0], []Group{{LineNumber: "1", TaxList: []Tax{{TaxAmount: "3.00"}}}})
+
+	fake := &fakeHttpClient{bodies: []string{envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":7,"TotalTax":"2.00",` +
+		`"GroupList":[{"LineNumber":"02","TaxList":[{"TaxAmount":"2.00"}]}]}`)}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	res, err := cli.Send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := decodeTestRequest(t, fake.requests[0])
+	if len(sent.ItemList) != 1 || sent.ItemList[0].LineNumber != "02" {
+		t.Fatalf("Expected only the uncached item to be sent but got %+v", sent.ItemList)
+	}
+	if res.TotalTax != "5.00" || res.TransId != 7 || len(res.GroupList) != 2 {
+		t.Fatalf("Expected the merged response but got %+v", res)
+	}
+	if _, ok := cache.lookup(req, &req.ItemList[1]); !ok {
+		t.Fatal("Expected the sent item to be cached")
+	}
+}
+
+func Test_QuoteCache_key(t *testing.T) {
+
+	cache, _ := NewQuoteCache(0, "")
+	req := getTestRequest()
+	req.ReturnFileCode = "Q"
+	item := req.ItemList[0]
+	item.Address = Address{PrimaryAddressLine: "1 Main St", City: "Fernandina Beach", State: "FL"}
+	item.TaxSitusRule = "27"
+	cache.store(req, &item, []Group{{LineNumber: "1", TaxList: []Tax{{TaxAmount: "3.00"}}}})
+
+	if _, ok := cache.lookup(req, &item); !ok {
+		t.Fatal("Expected the same item to be cached")
+	}
+
+	street := item
+	street.Address.PrimaryAddressLine = "9 Ocean Ave"
+	p2p := item
+	p2p.TaxSitusRule = "07"
+	p2p.P2PAddress = P2PAddress{State: "GA", PostalCode: "30301"}
+	service := item
+	service.ServiceAddress = &Address{State: "GA", PostalCode: "30301"}
+	for name, other := range map[string]RequestItem{"street": street, "P2P": p2p, "service": service} {
+		if _, ok := cache.lookup(req, &other); ok {
+			t.Fatalf("Expected an item with another %s address to miss the cache", name)
+		}
+	}
+}
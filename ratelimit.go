@@ -0,0 +1,78 @@
+package suretax
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Blocks until an event is allowed. Satisfied by *rate.Limiter from golang.org/x/time/rate.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// Allows one event per interval.
+type intervalLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// Returns a RateLimiter allowing one event every interval.
+func NewIntervalLimiter(interval time.Duration) RateLimiter {
+	return &intervalLimiter{interval: interval}
+}
+
+func (l *intervalLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	d := at.Sub(now)
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.release(at)
+		return ctx.Err()
+	}
+}
+
+// Gives back the slot reserved at, unless a later caller reserved the next one already.
+func (l *intervalLimiter) release(at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.next.Equal(at.Add(l.interval)) {
+		l.next = at
+	}
+}
+
+// Makes the client wait for l before every HTTP request to SureTax, retries included,
+// e.g. rate.NewLimiter(50, 10) to stay under 50 requests per second.
+func WithRateLimiter(l RateLimiter) Option {
+	return func(c *SuretaxClient) {
+		c.RateLimiter = l
+	}
+}
+
+// Waits for the client RateLimiter, if any.
+func (c *SuretaxClient) throttle(ctx context.Context) error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+	return c.RateLimiter.Wait(ctx)
+}
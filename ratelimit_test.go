@@ -0,0 +1,64 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type countingLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return l.err
+}
+
+func Test_WithRateLimiter(t *testing.T) {
+
+	var posted int
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		posted++
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)), nil
+	})
+
+	limiter := &countingLimiter{}
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport), WithRateLimiter(limiter))
+
+	if _, err := cli.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Cancel(&CancelRequest{ClientNumber: "000000001", ValidationKey: "key", TransId: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if limiter.waits != 2 || posted != 2 {
+		t.Fatalf("Expected every request to wait for the limiter but got %v waits for %v requests", limiter.waits, posted)
+	}
+
+	limiter.err = context.DeadlineExceeded
+	if _, err := cli.Send(getTestRequest()); !errors.Is(err, context.DeadlineExceeded) || posted != 2 {
+		t.Fatalf("Expected the limiter error without a request but got %v after %v requests", err, posted)
+	}
+}
+
+func Test_intervalLimiter_cancelledWait(t *testing.T) {
+
+	l := NewIntervalLimiter(time.Hour).(*intervalLimiter)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	next := l.next
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancelled wait to fail but got %v", err)
+	}
+	if !l.next.Equal(next) {
+		t.Fatalf("Expected the slot of the cancelled wait released but got next %v instead of %v", l.next, next)
+	}
+}
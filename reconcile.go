@@ -0,0 +1,216 @@
+package suretax
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// Expected tax amount per invoice number.
+type ExpectedTax map[string]string
+
+// Returns the tax per invoice of a prior response, typically a quote.
+func ExpectedFromResponse(res *Response) (ExpectedTax, error) {
+	totals, err := taxByInvoice(res)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(ExpectedTax, len(totals))
+	for inv, t := range totals {
+		expected[inv] = formatAmount(t, 2)
+	}
+	return expected, nil
+}
+
+// Returns the expected tax per invoice assuming a flat effective rate (e.g. "0.17") on item revenue.
+func ExpectedFromRate(req *Request, rate string) (ExpectedTax, error) {
+	r, err := parseAmount(rate)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := map[string]*big.Rat{}
+	for _, item := range req.ItemList {
+		rev, err := parseAmount(item.Revenue)
+		if err != nil {
+			return nil, err
+		}
+		t, ok := totals[item.InvoiceNumber]
+		if !ok {
+			t = new(big.Rat)
+			totals[item.InvoiceNumber] = t
+		}
+		t.Add(t, new(big.Rat).Mul(rev, r))
+	}
+
+	expected := make(ExpectedTax, len(totals))
+	for inv, t := range totals {
+		expected[inv] = formatAmount(t, 2)
+	}
+	return expected, nil
+}
+
+// Sums TaxAmount of every group per invoice number.
+func taxByInvoice(res *Response) (map[string]*big.Rat, error) {
+	groups, err := res.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	totals := map[string]*big.Rat{}
+	for _, g := range groups {
+		t, ok := totals[g.InvoiceNumber]
+		if !ok {
+			t = new(big.Rat)
+			totals[g.InvoiceNumber] = t
+		}
+		for _, tax := range g.TaxList {
+			amt, err := parseAmount(tax.TaxAmount)
+			if err != nil {
+				return nil, err
+			}
+			t.Add(t, amt)
+		}
+	}
+	return totals, nil
+}
+
+// Limits above which a variance is flagged. A zero limit is not applied.
+type VarianceThreshold struct {
+	// Absolute difference, e.g. "0.05".
+	Amount string
+
+	// Difference relative to the expected amount, e.g. 0.01 for 1%.
+	Percent float64
+}
+
+// Expected and calculated tax of a single invoice.
+type Variance struct {
+	InvoiceNumber string
+	Expected      string
+	Calculated    string
+
+	// Calculated minus expected.
+	Difference string
+
+	// Difference relative to the expected amount. Zero when nothing was expected.
+	Percent float64
+
+	// Set when the difference exceeds the threshold or the invoice is missing on one side.
+	Flagged bool
+}
+
+// Comparison of expected and calculated tax per invoice.
+type ReconciliationReport struct {
+	Variances []Variance
+}
+
+// Returns the flagged variances only.
+func (r *ReconciliationReport) Flagged() []Variance {
+	var flagged []Variance
+	for _, v := range r.Variances {
+		if v.Flagged {
+			flagged = append(flagged, v)
+		}
+	}
+	return flagged
+}
+
+// Compares expected tax with the amounts calculated in res, per invoice, ordered by invoice number.
+func Reconcile(expected ExpectedTax, res *Response, threshold VarianceThreshold) (*ReconciliationReport, error) {
+
+	calculated, err := taxByInvoice(res)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := parseAmount(threshold.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := map[string]bool{}
+	for inv := range expected {
+		invoices[inv] = true
+	}
+	for inv := range calculated {
+		invoices[inv] = true
+	}
+
+	report := &ReconciliationReport{}
+
+	for inv := range invoices {
+		exp, hasExpected := new(big.Rat), false
+		if s, ok := expected[inv]; ok {
+			if exp, err = parseAmount(s); err != nil {
+				return nil, err
+			}
+			hasExpected = true
+		}
+
+		calc, hasCalculated := calculated[inv]
+		if !hasCalculated {
+			calc = new(big.Rat)
+		}
+
+		diff := new(big.Rat).Sub(calc, exp)
+		absDiff := new(big.Rat).Abs(diff)
+
+		v := Variance{
+			InvoiceNumber: inv,
+			Expected:      formatAmount(exp, 2),
+			Calculated:    formatAmount(calc, 2),
+			Difference:    formatAmount(diff, 2),
+			Flagged:       !hasExpected || !hasCalculated,
+		}
+
+		if exp.Sign() != 0 {
+			v.Percent, _ = new(big.Rat).Quo(diff, exp).Float64()
+		}
+
+		if limit.Sign() > 0 && absDiff.Cmp(limit) > 0 {
+			v.Flagged = true
+		}
+		if threshold.Percent > 0 && exp.Sign() != 0 {
+			rel, _ := new(big.Rat).Quo(absDiff, new(big.Rat).Abs(exp)).Float64()
+			if rel > threshold.Percent {
+				v.Flagged = true
+			}
+		}
+
+		report.Variances = append(report.Variances, v)
+	}
+
+	sort.Slice(report.Variances, func(i, j int) bool {
+		return report.Variances[i].InvoiceNumber < report.Variances[j].InvoiceNumber
+	})
+
+	return report, nil
+}
+
+// Writes the report as CSV with one row per invoice.
+func (r *ReconciliationReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"InvoiceNumber", "Expected", "Calculated", "Difference", "Percent", "Flagged"})
+
+	for _, v := range r.Variances {
+		flagged := "N"
+		if v.Flagged {
+			flagged = "Y"
+		}
+		cw.Write([]string{v.InvoiceNumber, v.Expected, v.Calculated, v.Difference, big.NewFloat(v.Percent).Text('f', 4), flagged})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes the report as a JSON document.
+func (r *ReconciliationReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
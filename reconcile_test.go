@@ -0,0 +1,60 @@
+package suretax
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_Reconcile(t *testing.T) {
+
+	res, err := testCli.parseResponse(getTestResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ExpectedTax{"INV-002": "28.60", "INV-003": "1.00"}
+
+	report, err := Reconcile(expected, res, VarianceThreshold{Amount: "0.10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Variances) != 2 {
+		t.Fatalf("Expected %v variances but got %v", 2, len(report.Variances))
+	}
+
+	v := report.Variances[0]
+	if v.InvoiceNumber != "INV-002" || v.Calculated != "28.65" || v.Difference != "0.05" || v.Flagged {
+		t.Fatalf("Unexpected variance %+v", v)
+	}
+
+	if !report.Variances[1].Flagged {
+		t.Fatal("Expected invoice missing from the response to be flagged")
+	}
+
+	report, _ = Reconcile(expected, res, VarianceThreshold{Percent: 0.001})
+	if !report.Variances[0].Flagged {
+		t.Fatal("Expected variance over the percent threshold to be flagged")
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "INV-002,28.60,28.65,0.05,0.0017,Y") {
+		t.Fatalf("Unexpected CSV %v", buf.String())
+	}
+}
+
+func Test_ExpectedFromRate(t *testing.T) {
+
+	expected, err := ExpectedFromRate(getTestRequest(), "0.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected["INV-002"] != "25.00" {
+		t.Fatalf("Expected %v but got %v", "25.00", expected["INV-002"])
+	}
+}
@@ -0,0 +1,168 @@
+package suretax
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Description of an endpoint registered with an EndpointRegistry.
+type EndpointSpec struct {
+	// Path of the endpoint relative to the registry base URL, e.g. "StatusQuery".
+	Path string
+
+	// Key of the request wrapper, e.g. "request". Empty posts the request JSON as is.
+	WrapperKey string
+
+	Envelope EnvelopeStyle
+
+	// Types of the request and response, struct types or pointers to them.
+	// A nil RequestType accepts any request; a nil ResponseType decodes into a map[string]interface{}.
+	RequestType  reflect.Type
+	ResponseType reflect.Type
+
+	MaxRetries int
+}
+
+// Endpoints the package doesn't model natively, described at runtime and invoked by name through
+// the client's shared transport. Safe for concurrent use.
+//
+//	reg := suretax.NewEndpointRegistry("https://testapi.taxrating.net/Services/Communications/V01/SureTax.asmx")
+//	suretax.RegisterEndpoint[StatusQuery, StatusResult](reg, "StatusQuery", suretax.EndpointSpec{Path: "StatusQuery", WrapperKey: "request"})
+//	res, err := reg.Invoke(ctx, client, "StatusQuery", &StatusQuery{TransId: 42})
+type EndpointRegistry struct {
+	baseUrl string
+
+	mu        sync.RWMutex
+	endpoints map[string]EndpointSpec
+}
+
+// Returns a registry resolving endpoint paths against baseUrl.
+func NewEndpointRegistry(baseUrl string) *EndpointRegistry {
+	return &EndpointRegistry{
+		baseUrl:   strings.TrimRight(baseUrl, "/"),
+		endpoints: make(map[string]EndpointSpec),
+	}
+}
+
+// Adds an endpoint under name. Registering a name twice is an error.
+func (r *EndpointRegistry) Register(name string, spec EndpointSpec) error {
+	if name == "" {
+		return fmt.Errorf("Endpoint name is empty")
+	}
+	if spec.Path == "" {
+		return fmt.Errorf("Endpoint %s has no path", name)
+	}
+	for _, t := range []reflect.Type{spec.RequestType, spec.ResponseType} {
+		if t != nil && structType(t).Kind() != reflect.Struct {
+			return fmt.Errorf("Endpoint %s type %v is not a struct", name, t)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.endpoints[name]; ok {
+		return fmt.Errorf("Endpoint %s is already registered", name)
+	}
+	r.endpoints[name] = spec
+	return nil
+}
+
+// Registers an endpoint under name taking TReq and answering TResp.
+func RegisterEndpoint[TReq, TResp any](r *EndpointRegistry, name string, spec EndpointSpec) error {
+	spec.RequestType = reflect.TypeOf((*TReq)(nil)).Elem()
+	spec.ResponseType = reflect.TypeOf((*TResp)(nil)).Elem()
+	return r.Register(name, spec)
+}
+
+// Returns the spec registered under name.
+func (r *EndpointRegistry) Lookup(name string) (EndpointSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.endpoints[name]
+	return spec, ok
+}
+
+// Returns the registered names in order.
+func (r *EndpointRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.endpoints))
+	for name := range r.endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns the Endpoint called for the endpoint registered under name.
+func (r *EndpointRegistry) Endpoint(name string) (Endpoint, error) {
+	spec, ok := r.Lookup(name)
+	if !ok {
+		return Endpoint{}, fmt.Errorf("Endpoint %s is not registered", name)
+	}
+	return Endpoint{
+		Name:       name,
+		Url:        r.baseUrl + "/" + strings.TrimLeft(spec.Path, "/"),
+		WrapperKey: spec.WrapperKey,
+		Envelope:   spec.Envelope,
+		MaxRetries: spec.MaxRetries,
+	}, nil
+}
+
+// Posts req to the endpoint registered under name and returns the decoded response,
+// a pointer to a new value of its ResponseType.
+func (r *EndpointRegistry) Invoke(ctx context.Context, c *SuretaxClient, name string, req interface{}) (interface{}, error) {
+	endpoint, err := r.Endpoint(name)
+	if err != nil {
+		return nil, err
+	}
+	spec, _ := r.Lookup(name)
+
+	if t := spec.RequestType; t != nil && req != nil {
+		if got := structType(reflect.TypeOf(req)); got != structType(t) {
+			return nil, fmt.Errorf("Endpoint %s takes %v but got %T", name, structType(t), req)
+		}
+	}
+
+	var res interface{}
+	if spec.ResponseType != nil {
+		res = reflect.New(structType(spec.ResponseType)).Interface()
+	} else {
+		res = &map[string]interface{}{}
+	}
+
+	if err := invoke(ctx, c, endpoint, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Posts the request JSON to the endpoint registered under name and returns the response JSON,
+// e.g. for callers that only pass payloads through.
+func (r *EndpointRegistry) InvokeJSON(ctx context.Context, c *SuretaxClient, name string, req json.RawMessage) (json.RawMessage, error) {
+	endpoint, err := r.Endpoint(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var res json.RawMessage
+	if err := invoke(ctx, c, endpoint, req, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Returns t with one level of pointer removed.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
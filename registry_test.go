@@ -0,0 +1,79 @@
+package suretax
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func Test_EndpointRegistry(t *testing.T) {
+
+	var url, body string
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		url = r.URL.String()
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		if r.URL.Path == "/api/bare" {
+			return okResponse(`{"ResponseCode":"9999","Status":"Bare"}`), nil
+		}
+		return okResponse(envelope(`{"ResponseCode":"9999","Status":"Posted"}`)), nil
+	}))
+	defer SetHttpClient(nil)
+
+	reg := NewEndpointRegistry("https://testapi.taxrating.net/api/")
+	if err := RegisterEndpoint[statusQuery, statusResult](reg, "Status", EndpointSpec{Path: "status", WrapperKey: "request"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("Bare", EndpointSpec{Path: "/bare", Envelope: EnvelopeNone}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("Status", EndpointSpec{Path: "other"}); err == nil {
+		t.Fatal("Expected an error registering a name twice")
+	}
+	if err := reg.Register("Int", EndpointSpec{Path: "int", ResponseType: reflect.TypeOf(0)}); err == nil {
+		t.Fatal("Expected an error registering a non-struct type")
+	}
+	if names := reg.Names(); !reflect.DeepEqual(names, []string{"Bare", "Status"}) {
+		t.Fatalf("Expected the registered names but got %v", names)
+	}
+
+	cli := &SuretaxClient{}
+	ctx := context.Background()
+
+	res, err := reg.Invoke(ctx, cli, "Status", &statusQuery{TransId: "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := res.(*statusResult); !ok || r.Status != "Posted" {
+		t.Fatalf("Expected a *statusResult but got %#v", res)
+	}
+	if url != "https://testapi.taxrating.net/api/status" || body != `{"request":"{\"ClientNumber\":\"\",\"TransId\":\"42\"}"}` {
+		t.Fatalf("Expected the wrapped query posted to the endpoint but got %v %v", url, body)
+	}
+
+	if _, err := reg.Invoke(ctx, cli, "Status", &statusResult{}); err == nil {
+		t.Fatal("Expected an error for a request of the wrong type")
+	}
+	if _, err := reg.Invoke(ctx, cli, "Missing", nil); err == nil {
+		t.Fatal("Expected an error for an unregistered endpoint")
+	}
+
+	res, err = reg.Invoke(ctx, cli, "Bare", map[string]int{"TransId": 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := *res.(*map[string]interface{}); m["Status"] != "Bare" || body != `{"TransId":7}` {
+		t.Fatalf("Expected the bare request and response but got %v %v", body, m)
+	}
+
+	raw, err := reg.InvokeJSON(ctx, cli, "Status", json.RawMessage(`{"TransId":"1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `{"ResponseCode":"9999","Status":"Posted"}` {
+		t.Fatalf("Expected the unwrapped response JSON but got %s", raw)
+	}
+}
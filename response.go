@@ -0,0 +1,109 @@
+package suretax
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Returns a *ResponseError if the response code is anything other than 9999 (Success), nil otherwise.
+func (r *Response) Err() error {
+	if r.ResponseCode == ResponseCodeSuccess {
+		return nil
+	}
+	return &ResponseError{ResponseCode: r.ResponseCode, HeaderMessage: r.HeaderMessage, Response: r}
+}
+
+// Reports whether SureTax declined the whole request.
+func (r *Response) declined() bool {
+	if r.Successful != "" {
+		return r.Successful != "Y"
+	}
+	c := r.Category()
+	return c != ResponseSuccess && c != ResponseSuccessWithErrors
+}
+
+// Reports whether SureTax processed the request, possibly with item errors (9999 or 9001).
+func (r *Response) IsSuccess() bool {
+	return r.ResponseCode != "" && !r.declined()
+}
+
+// Returns TotalTax as an exact decimal. A missing TotalTax is zero.
+func (r *Response) TotalTaxDecimal() (*big.Rat, error) {
+	return parseAmount(r.TotalTax)
+}
+
+// Checks that TotalTax matches the sum of the TaxAmount of every tax in GroupList, so a corrupted
+// or truncated response is caught before invoicing. The sum may differ from TotalTax by up to half a unit
+// of the last decimal place of TotalTax, since SureTax rounds it to the precision of ResponseGroup.
+// A response without TotalTax has nothing to check.
+func (r *Response) Verify() error {
+	if r.TotalTax == "" {
+		return nil
+	}
+
+	total, err := parseAmount(r.TotalTax)
+	if err != nil {
+		return fmt.Errorf("Invalid TotalTax: %w", err)
+	}
+
+	groups, err := r.Groups()
+	if err != nil {
+		return err
+	}
+
+	var amounts []string
+	for _, g := range groups {
+		for _, t := range g.TaxList {
+			amounts = append(amounts, t.TaxAmount)
+		}
+	}
+	sum, err := sumAmounts(amounts)
+	if err != nil {
+		return err
+	}
+
+	s, _ := parseAmount(sum)
+	diff := new(big.Rat).Sub(s, total)
+	tolerance := big.NewRat(1, 2)
+	tolerance.Quo(tolerance, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(amountDecimals(r.TotalTax))), nil)))
+	if diff.Abs(diff).Cmp(tolerance) > 0 {
+		return fmt.Errorf("TotalTax %s doesn't match the %d tax amounts, which add up to %s", r.TotalTax, len(amounts), sum)
+	}
+	return nil
+}
+
+// Returns the messages of the items SureTax couldn't process. The slice is a copy.
+func (r *Response) ItemsWithErrors() []ItemMessage {
+	if len(r.ItemMessages) == 0 {
+		return nil
+	}
+	return append([]ItemMessage(nil), r.ItemMessages...)
+}
+
+// Returns the message of the item with the given line number, matching "1" and "01" alike.
+func (r *Response) ItemError(lineNumber string) (ItemMessage, bool) {
+	key := lineKey(lineNumber)
+	for _, m := range r.ItemMessages {
+		if lineKey(m.LineNumber) == key {
+			return m, true
+		}
+	}
+	return ItemMessage{}, false
+}
+
+// Returns the taxes calculated for the item with the given line number.
+func (r *Response) Taxes(lineNumber string) ([]Tax, error) {
+	groups, err := r.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	key := lineKey(lineNumber)
+	var taxes []Tax
+	for _, g := range groups {
+		if lineKey(g.LineNumber) == key {
+			taxes = append(taxes, g.TaxList...)
+		}
+	}
+	return taxes, nil
+}
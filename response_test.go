@@ -0,0 +1,60 @@
+package suretax
+
+import "testing"
+
+func Test_Response_accessors(t *testing.T) {
+
+	res, err := testCli.parseResponse(getTestResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.IsSuccess() {
+		t.Fatal("Expected a successful response")
+	}
+	total, err := res.TotalTaxDecimal()
+	if err != nil || total.FloatString(2) != "28.65" {
+		t.Fatalf("Expected TotalTax 28.65 but got %v", total)
+	}
+
+	if items := res.ItemsWithErrors(); len(items) != 1 || items[0].ResponseCode != "9131" {
+		t.Fatalf("Expected one item error but got %+v", items)
+	}
+	if m, ok := res.ItemError("00"); !ok || m.Message != "Bill To Number is Required" {
+		t.Fatalf("Expected the message of line 0 but got %+v", m)
+	}
+	if _, ok := res.ItemError("1"); ok {
+		t.Fatal("Expected no message for line 1")
+	}
+
+	taxes, err := res.Taxes("1")
+	if err != nil || len(taxes) != 4 {
+		t.Fatalf("Expected 4 taxes for line 1 but got %v", len(taxes))
+	}
+
+	if (&Response{ResponseCode: "1151", Successful: "N"}).IsSuccess() || (&Response{}).IsSuccess() {
+		t.Fatal("Expected declined and empty responses not to be successful")
+	}
+}
+
+func Test_Response_Verify(t *testing.T) {
+
+	res := &Response{TotalTax: "1.75", GroupList: []Group{
+		{LineNumber: "1", TaxList: []Tax{{TaxAmount: "1.25004"}, {TaxAmount: "0.50"}}},
+	}}
+	if err := res.Verify(); err != nil {
+		t.Fatalf("Expected amounts within rounding to pass but got %v", err)
+	}
+
+	res.GroupList[0].TaxList = res.GroupList[0].TaxList[:1]
+	if err := res.Verify(); err == nil || err.Error() != "TotalTax 1.75 doesn't match the 1 tax amounts, which add up to 1.25004" {
+		t.Fatalf("Expected a truncated response to fail but got %v", err)
+	}
+
+	if err := (&Response{TotalTax: "x"}).Verify(); err == nil {
+		t.Fatal("Expected an invalid TotalTax to fail")
+	}
+	if err := (&Response{}).Verify(); err != nil {
+		t.Fatalf("Expected a response without TotalTax to pass but got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package suretax
+
+import "strings"
+
+// Header-level response codes (Appendix I of the SureTax specification).
+const (
+	ResponseCodeSuccess               = "9999"
+	ResponseCodeSuccessWithItemErrors = "9001"
+
+	ResponseCodeGeneralFailure      = "1100"
+	ResponseCodeInvalidClientNumber = "1101"
+	ResponseCodeInvalidTransType    = "1120"
+
+	ResponseCodeValidationKeyRequired = "1150"
+	ResponseCodeInvalidValidationKey  = "1151"
+
+	// Cancel requests
+	ResponseCodeTransactionTooOld    = "1510"
+	ResponseCodeTransactionNotFound  = "1520"
+	ResponseCodeTransactionCancelled = "9410"
+)
+
+// Meaning of a response code, see Classify.
+type ResponseCategory int
+
+const (
+	// The code is not in the table.
+	ResponseUnknown ResponseCategory = iota
+
+	ResponseSuccess
+
+	// The request was processed, but some items were not. See Response.ItemMessages.
+	ResponseSuccessWithErrors
+
+	// Missing or invalid credentials.
+	ResponseAuthFailure
+
+	// SureTax refused the request data. Resending the same request will fail again.
+	ResponseValidationFailure
+
+	// SureTax failed to process the request for another reason.
+	ResponseFailure
+)
+
+func (c ResponseCategory) String() string {
+	switch c {
+	case ResponseSuccess:
+		return "success"
+	case ResponseSuccessWithErrors:
+		return "success with errors"
+	case ResponseAuthFailure:
+		return "auth failure"
+	case ResponseValidationFailure:
+		return "validation failure"
+	case ResponseFailure:
+		return "failure"
+	}
+	return "unknown"
+}
+
+type responseCodeInfo struct {
+	category    ResponseCategory
+	description string
+}
+
+var responseCodes = map[string]responseCodeInfo{
+	ResponseCodeSuccess:               {ResponseSuccess, "Success"},
+	ResponseCodeSuccessWithItemErrors: {ResponseSuccessWithErrors, "Success with Item errors"},
+
+	ResponseCodeGeneralFailure:      {ResponseFailure, "Failure - General failure"},
+	ResponseCodeInvalidClientNumber: {ResponseValidationFailure, "Failure - Invalid client number"},
+	ResponseCodeInvalidTransType:    {ResponseValidationFailure, "Failure - Invalid Trans Type Code"},
+
+	ResponseCodeValidationKeyRequired: {ResponseAuthFailure, "Failure - Validation Key Required"},
+	ResponseCodeInvalidValidationKey:  {ResponseAuthFailure, "Failure - Invalid Validation Key"},
+
+	ResponseCodeTransactionTooOld:    {ResponseValidationFailure, "Failure - Transaction is more than 60 days old"},
+	ResponseCodeTransactionNotFound:  {ResponseValidationFailure, "Failure - Transaction ID not found"},
+	ResponseCodeTransactionCancelled: {ResponseValidationFailure, "Transaction is already cancelled"},
+}
+
+// Returns the category and description of a header-level response code.
+// Codes missing from the table are classified by range: SureTax reports request data problems
+// with 1xxx codes, so those are ResponseValidationFailure with an empty description,
+// anything else is ResponseUnknown.
+func Classify(code string) (ResponseCategory, string) {
+	if info, ok := responseCodes[code]; ok {
+		return info.category, info.description
+	}
+	if len(code) == 4 && isDigits(code) && strings.HasPrefix(code, "1") {
+		return ResponseValidationFailure, ""
+	}
+	return ResponseUnknown, ""
+}
+
+// Returns the category of the response code, see Classify.
+func (r *Response) Category() ResponseCategory {
+	c, _ := Classify(r.ResponseCode)
+	return c
+}
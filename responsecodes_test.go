@@ -0,0 +1,32 @@
+package suretax
+
+import "testing"
+
+func Test_Classify(t *testing.T) {
+
+	cases := []struct {
+		code        string
+		category    ResponseCategory
+		description string
+	}{
+		{"9999", ResponseSuccess, "Success"},
+		{"9001", ResponseSuccessWithErrors, "Success with Item errors"},
+		{"1151", ResponseAuthFailure, "Failure - Invalid Validation Key"},
+		{"1101", ResponseValidationFailure, "Failure - Invalid client number"},
+		{"1100", ResponseFailure, "Failure - General failure"},
+		{"1234", ResponseValidationFailure, ""},
+		{"8000", ResponseUnknown, ""},
+		{"", ResponseUnknown, ""},
+	}
+
+	for _, c := range cases {
+		category, description := Classify(c.code)
+		if category != c.category || description != c.description {
+			t.Fatalf("Expected %q to be %v %q but got %v %q", c.code, c.category, c.description, category, description)
+		}
+	}
+
+	if c := (&Response{ResponseCode: "1150"}).Category(); c != ResponseAuthFailure || c.String() != "auth failure" {
+		t.Fatalf("Expected an auth failure but got %v", c)
+	}
+}
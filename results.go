@@ -0,0 +1,93 @@
+package suretax
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// The outcome of a single request item, correlated from the response's GroupList and ItemMessages.
+type ItemResult struct {
+	LineNumber     string
+	InvoiceNumber  string
+	CustomerNumber string
+
+	ClientTracking string
+	TransId        int
+
+	// Sum of TaxAmount over TaxList
+	TotalTax string
+	TaxList  []Tax
+
+	// Set for items SureTax could not process, or to the response's code and
+	// header message for every item of a declined request.
+	ResponseCode string `json:",omitempty"`
+	Message      string `json:",omitempty"`
+}
+
+// Returns one ItemResult per item of req, in request order.
+func ItemResults(req *Request, res *Response) ([]ItemResult, error) {
+	groups, err := res.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	taxes := map[string][]Tax{}
+	for _, g := range groups {
+		key := lineKey(g.LineNumber)
+		taxes[key] = append(taxes[key], g.TaxList...)
+	}
+
+	messages := map[string]ItemMessage{}
+	for _, m := range res.ItemMessages {
+		messages[lineKey(m.LineNumber)] = m
+	}
+
+	results := make([]ItemResult, len(req.ItemList))
+	for i, item := range req.ItemList {
+		key := lineKey(item.LineNumber)
+
+		r := ItemResult{
+			LineNumber:     item.LineNumber,
+			InvoiceNumber:  item.InvoiceNumber,
+			CustomerNumber: item.CustomerNumber,
+			ClientTracking: res.ClientTracking,
+			TransId:        res.TransId,
+			TaxList:        taxes[key],
+		}
+
+		amounts := make([]string, len(r.TaxList))
+		for j, t := range r.TaxList {
+			amounts[j] = t.TaxAmount
+		}
+		if r.TotalTax, err = sumAmounts(amounts); err != nil {
+			return nil, err
+		}
+
+		if m, ok := messages[key]; ok {
+			r.ResponseCode, r.Message = m.ResponseCode, m.Message
+		} else if res.declined() {
+			r.ResponseCode, r.Message = res.ResponseCode, res.HeaderMessage
+		}
+
+		results[i] = r
+	}
+
+	return results, nil
+}
+
+// Writes the item results of res to w as JSON lines, customer numbers minimized according to mode and key.
+func writeItemResults(w io.Writer, req *Request, res *Response, mode PrivacyMode, key []byte) error {
+	results, err := ItemResults(req, res)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for i := range results {
+		results[i].CustomerNumber = minimize(results[i].CustomerNumber, mode, key)
+		if err := enc.Encode(&results[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
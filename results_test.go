@@ -0,0 +1,68 @@
+package suretax
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func Test_ResultWriter(t *testing.T) {
+
+	SetHttpClient(&fakeHttpClient{bodies: []string{envelope(`{"ResponseCode":"9001","Successful":"Y","TransId":7,"TotalTax":"1.50",` +
+		`"ItemMessages":[{"LineNumber":"2","Message":"Bill To Number is Required","ResponseCode":"9131"}],` +
+		`"GroupList":[{"LineNumber":"1","StateCode":"FL","TaxList":[{"TaxTypeCode":"127","TaxAmount":"1.00"}]},` +
+		`{"LineNumber":"1","StateCode":"GA","TaxList":[{"TaxTypeCode":"035","TaxAmount":"0.50"}]}]}`)}})
+	defer SetHttpClient(nil)
+
+	req := getTestRequest()
+	req.ItemList = append(req.ItemList, req.ItemList[0])
+	req.ItemList[1].LineNumber = "2"
+	req.ItemList[1].CustomerNumber = "002"
+
+	out := new(bytes.Buffer)
+	cli := &SuretaxClient{ResultWriter: out, Privacy: PrivacyStrip}
+	if _, err := cli.Send(req); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []ItemResult
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		r := ItemResult{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 result lines but got %d", len(results))
+	}
+
+	first := results[0]
+	if first.LineNumber != "01" || first.TransId != 7 || len(first.TaxList) != 2 || first.TotalTax != "1.50" || first.ResponseCode != "" {
+		t.Fatalf("Expected taxes of both groups for line 01 but got %+v", first)
+	}
+	if first.CustomerNumber != "" {
+		t.Fatalf("Expected customer number to be stripped but got %v", first.CustomerNumber)
+	}
+
+	second := results[1]
+	if second.LineNumber != "2" || len(second.TaxList) != 0 || second.ResponseCode != "9131" || second.Message != "Bill To Number is Required" {
+		t.Fatalf("Expected the item error for line 2 but got %+v", second)
+	}
+}
+
+func Test_ItemResults_Declined(t *testing.T) {
+
+	res := &Response{ResponseCode: "1101", HeaderMessage: "Failure - invalid client number", Successful: "N"}
+
+	results, err := ItemResults(getTestRequest(), res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ResponseCode != "1101" || results[0].Message != res.HeaderMessage || results[0].TotalTax != "0" {
+		t.Fatalf("Expected the header error for every item but got %+v", results)
+	}
+}
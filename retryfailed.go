@@ -0,0 +1,44 @@
+package suretax
+
+import "errors"
+
+// Returns a request with the fields of req holding only the items res reported in ItemMessages,
+// for resubmitting them once they are fixed. res is the response returned for req, usually a 9001
+// "Success with Item errors". The items are copies, so they can be edited without changing req.
+// TotalRevenue is recalculated and STAN is cleared, as the retry is a SureTax transaction of its own.
+func (r *Response) RetryRequest(req *Request) (*Request, error) {
+	var items []RequestItem
+	seen := map[*RequestItem]bool{}
+	for _, f := range r.FailedItems(req) {
+		if f.Item != nil && !seen[f.Item] {
+			seen[f.Item] = true
+			items = append(items, *f.Item)
+		}
+	}
+	if len(items) == 0 {
+		return nil, errors.New("Response has no item errors to retry")
+	}
+
+	return subRequest(req, items)
+}
+
+// Combines r, the response of the original request, with res, the response of retry, the request
+// built by RetryRequest, into the result for the full invoice: the groups of both responses are
+// concatenated and TotalTax is summed. Item messages of r are dropped for the items retry resubmitted,
+// so only the errors still unresolved are reported. TransId, ClientTracking and STAN are those of r.
+func (r *Response) MergeRetry(retry *Request, res *Response) (*Response, error) {
+	retried := map[string]bool{}
+	for _, item := range retry.ItemList {
+		retried[lineKey(item.LineNumber)] = true
+	}
+
+	original := *r
+	original.ItemMessages = nil
+	for _, m := range r.ItemMessages {
+		if !retried[lineKey(m.LineNumber)] {
+			original.ItemMessages = append(original.ItemMessages, m)
+		}
+	}
+
+	return mergeResponses([]*Response{&original, res})
+}
@@ -0,0 +1,50 @@
+package suretax
+
+import "testing"
+
+func Test_RetryFailedItems(t *testing.T) {
+
+	req := getTestRequest()
+	second := req.ItemList[0]
+	second.LineNumber, second.Revenue, second.BillToNumber = "02", "40", ""
+	req.ItemList = append(req.ItemList, second)
+	req.TotalRevenue = "140"
+
+	res := &Response{
+		ResponseCode:  "9001",
+		HeaderMessage: "Success with Item errors",
+		TransId:       7,
+		TotalTax:      "1.50",
+		GroupList:     []Group{{LineNumber: "1", TaxList: []Tax{{TaxAmount: "1.50"}}}},
+		ItemMessages:  []ItemMessage{{LineNumber: "2", ResponseCode: "9131", Message: "Bill To Number is Required"}},
+	}
+
+	retry, err := res.RetryRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retry.ItemList) != 1 || retry.ItemList[0].LineNumber != "02" || retry.TotalRevenue != "40" || retry.STAN != "" {
+		t.Fatalf("Expected only item 02 with TotalRevenue 40 but got %+v", retry)
+	}
+	retry.ItemList[0].BillToNumber = "9043101723"
+	if req.ItemList[1].BillToNumber != "" {
+		t.Fatal("Expected the original item to be left unchanged")
+	}
+
+	merged, err := res.MergeRetry(retry, &Response{
+		ResponseCode: "9999",
+		TransId:      8,
+		TotalTax:     "0.60",
+		GroupList:    []Group{{LineNumber: "2", TaxList: []Tax{{TaxAmount: "0.60"}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ResponseCode != "9999" || merged.TotalTax != "2.10" || len(merged.GroupList) != 2 || len(merged.ItemMessages) != 0 || merged.TransId != 7 {
+		t.Fatalf("Expected a successful response for the full invoice but got %+v", merged)
+	}
+
+	if _, err := (&Response{ResponseCode: "9999"}).RetryRequest(req); err == nil {
+		t.Fatal("Expected an error for a response without item errors")
+	}
+}
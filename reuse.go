@@ -0,0 +1,36 @@
+package suretax
+
+// Clears r for reuse by SuretaxClient.SendInto, keeping the capacity of its slices.
+// Groups, taxes and item messages obtained from r before the call must no longer be used.
+func (r *Response) Reset() {
+	groups := r.GroupList[:cap(r.GroupList)]
+	for i := range groups {
+		groups[i] = Group{}
+	}
+	messages := r.ItemMessages[:cap(r.ItemMessages)]
+	for i := range messages {
+		messages[i] = ItemMessage{}
+	}
+	taxes := r.taxes[:cap(r.taxes)]
+	for i := range taxes {
+		taxes[i] = Tax{}
+	}
+
+	*r = Response{GroupList: groups[:0], ItemMessages: messages[:0], taxes: taxes[:0]}
+}
+
+// Returns n zeroed groups, reusing the GroupList array when it's large enough.
+func (r *Response) groupBuffer(n int) []Group {
+	if r.GroupList != nil && cap(r.GroupList) >= n {
+		return r.GroupList[:n]
+	}
+	return make([]Group, n)
+}
+
+// Returns n zeroed taxes, reusing the array kept from the previous decode when it's large enough.
+func (r *Response) taxBuffer(n int) []Tax {
+	if cap(r.taxes) < n {
+		r.taxes = make([]Tax, n)
+	}
+	return r.taxes[:n]
+}
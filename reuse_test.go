@@ -0,0 +1,60 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_SendInto(t *testing.T) {
+
+	SetHttpClient(&fakeHttpClient{bodies: []string{
+		envelope(decodeTestInner(t)),
+		envelope(`{"ResponseCode":"9999","HeaderMessage":"Second","Successful":"Y","TransId":2,"GroupList":[{"LineNumber":"1","TaxList":[{"TaxTypeCode":"035"}]}]}`),
+		envelope(`{"ResponseCode":"1101","HeaderMessage":"Failure","Successful":"N"}`),
+	}})
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{}
+	res := &Response{}
+
+	if err := cli.SendInto(getTestRequest(), res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GroupList) != 1 || len(res.GroupList[0].TaxList) != 4 {
+		t.Fatalf("Expected 1 group with 4 taxes but got %+v", res.GroupList)
+	}
+	groups, taxes := &res.GroupList[0], &res.GroupList[0].TaxList[0]
+
+	if err := cli.SendInto(getTestRequest(), res); err != nil {
+		t.Fatal(err)
+	}
+	if res.TransId != 2 || res.HeaderMessage != "Second" || len(res.ItemMessages) != 0 || len(res.GroupList) != 1 {
+		t.Fatalf("Expected the second response only but got %+v", res)
+	}
+
+	g := res.GroupList[0]
+	if g.CustomerNumber != "" || len(g.TaxList) != 1 || g.TaxList[0].TaxTypeCode != "035" || g.TaxList[0].TaxAmount != "" {
+		t.Fatalf("Expected no values left from the first response but got %+v", g)
+	}
+	if &res.GroupList[0] != groups || &g.TaxList[0] != taxes {
+		t.Fatal("Expected GroupList and TaxList arrays to be reused")
+	}
+
+	err := cli.SendInto(getTestRequest(), res)
+	if rerr, ok := err.(*ResponseError); !ok || rerr.ResponseCode != "1101" || res.ResponseCode != "1101" || res.GroupList == nil || len(res.GroupList) != 0 {
+		t.Fatalf("Expected a *ResponseError for the declined request but got %v, %+v", err, res)
+	}
+}
+
+func Benchmark_decodeResponseInto_10k(b *testing.B) {
+	body := []byte(envelope(getLargeTestInner(b, 10000)))
+	res := &Response{}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		res.Reset()
+		if err := testCli.decodeResponseInto(body, res); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package suretax
+
+import "math/big"
+
+// How amounts are rounded to a number of decimal places.
+type RoundingMode int
+
+const (
+	// Halves away from zero: 0.125 → 0.13, -0.125 → -0.13.
+	RoundHalfUp RoundingMode = iota
+
+	// Halves to the even digit, banker's rounding: 0.125 → 0.12, 0.135 → 0.14.
+	RoundHalfEven
+
+	// Truncation toward zero: 0.129 → 0.12, -0.129 → -0.12.
+	RoundDown
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfEven:
+		return "half even"
+	case RoundDown:
+		return "down"
+	}
+	return "half up"
+}
+
+// Rounding mode and precision of the amounts a billing back-end stores.
+type Rounding struct {
+	Mode RoundingMode
+
+	// Decimal places of the rounded amounts.
+	Places int
+}
+
+// Returns r rounded to Places decimal places.
+func (ro Rounding) Round(r *big.Rat) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(ro.Places)), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	// Truncated quotient and remainder of the scaled amount.
+	q, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	if rem.Sign() != 0 && ro.Mode != RoundDown {
+		// Compare twice the remainder with the denominator to find out which side of the half it is on.
+		half := new(big.Int).Abs(rem)
+		half.Lsh(half, 1)
+		c := half.Cmp(scaled.Denom())
+		if c > 0 || c == 0 && (ro.Mode == RoundHalfUp || q.Bit(0) == 1) {
+			q.Add(q, big.NewInt(int64(rem.Sign())))
+		}
+	}
+
+	return new(big.Rat).SetFrac(q, scale)
+}
+
+// Returns r rounded to Places decimal places and formatted with them.
+func (ro Rounding) Format(r *big.Rat) string {
+	return formatAmount(ro.Round(r), ro.Places)
+}
+
+// Returns a copy of s with every total rounded with ro, e.g. for a back-end storing cents.
+// Totals are rounded individually, so the rounded totals of a dimension may not add up to the rounded Total.
+func (s *TaxSummary) Round(ro Rounding) (*TaxSummary, error) {
+	round := func(m map[string]string) (map[string]string, error) {
+		rounded := make(map[string]string, len(m))
+		for k, v := range m {
+			amt, err := parseAmount(v)
+			if err != nil {
+				return nil, err
+			}
+			rounded[k] = ro.Format(amt)
+		}
+		return rounded, nil
+	}
+
+	total, err := round(map[string]string{"": s.Total})
+	if err != nil {
+		return nil, err
+	}
+	r := &TaxSummary{Total: total[""]}
+	for _, f := range []struct {
+		dst *map[string]string
+		src map[string]string
+	}{{&r.ByState, s.ByState}, {&r.ByAuthority, s.ByAuthority}, {&r.ByTaxType, s.ByTaxType}, {&r.ByCategory, s.ByCategory}, {&r.ByInvoice, s.ByInvoice}} {
+		if *f.dst, err = round(f.src); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
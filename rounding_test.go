@@ -0,0 +1,42 @@
+package suretax
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_Rounding(t *testing.T) {
+
+	cases := []struct {
+		mode     RoundingMode
+		amount   string
+		expected string
+	}{
+		{RoundHalfUp, "0.125", "0.13"},
+		{RoundHalfUp, "-0.125", "-0.13"},
+		{RoundHalfUp, "0.1249", "0.12"},
+		{RoundHalfEven, "0.125", "0.12"},
+		{RoundHalfEven, "0.135", "0.14"},
+		{RoundHalfEven, "-0.125", "-0.12"},
+		{RoundHalfEven, "0.12501", "0.13"},
+		{RoundDown, "0.129", "0.12"},
+		{RoundDown, "-0.129", "-0.12"},
+		{RoundDown, "7", "7.00"},
+	}
+
+	for _, c := range cases {
+		amt, _ := new(big.Rat).SetString(c.amount)
+		if got := (Rounding{Mode: c.mode, Places: 2}).Format(amt); got != c.expected {
+			t.Fatalf("Expected %v rounded %v to be %v but got %v", c.amount, c.mode, c.expected, got)
+		}
+	}
+
+	s := &TaxSummary{Total: "1.125", ByState: map[string]string{"IL": "1.125"}, ByInvoice: map[string]string{}}
+	rounded, err := s.Round(Rounding{Mode: RoundHalfEven, Places: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rounded.Total != "1.12" || rounded.ByState["IL"] != "1.12" || s.Total != "1.125" {
+		t.Fatalf("Expected a rounded copy but got %+v", rounded)
+	}
+}
@@ -0,0 +1,131 @@
+package suretax
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Federal USF safe harbor interstate percentages.
+const (
+	SafeHarborWireless  = "37.1"
+	SafeHarborVoIP      = "64.9"
+	SafeHarborPaging    = "12"
+	SafeHarborAnalogSMR = "1"
+)
+
+// Interstate percentages applied when splitting revenue between interstate and intrastate,
+// either the federal safe harbors or the results of a traffic study.
+type SafeHarbor struct {
+	// Interstate percentage (e.g. "64.9") by TransTypeCode
+	Interstate map[string]string
+
+	// Percentage for trans types missing from Interstate. Empty leaves those items unsplit.
+	Default string
+
+	// Codes for the interstate and intrastate sub-items, by the TransTypeCode of the original item
+	TransTypes map[string]JurisdictionTransTypes
+
+	// Recorded with each share for auditability, e.g. "FCC" or "TS-2024Q1"
+	Source string
+
+	// UDF field receiving the applied share: "UDF" or "UDF2" (default)
+	AuditField string
+}
+
+// Revenue of an item divided between jurisdictions.
+type RevenueSplit struct {
+	// Applied interstate percentage
+	Percent string
+
+	Interstate string
+	Intrastate string
+}
+
+// Returns the interstate percentage configured for transTypeCode, or "" if none applies.
+func (s *SafeHarbor) Percent(transTypeCode string) string {
+	if p, ok := s.Interstate[transTypeCode]; ok {
+		return p
+	}
+	return s.Default
+}
+
+// Splits item by the configured interstate percentage into an interstate and an intrastate sub-item
+// with TrafficStudy.Allocate, using the Allocation of its trans type, TransTypes, Source and AuditField.
+// Sub-items, rounding and the audit trail in the UDF field are those of Allocate. Items whose trans type
+// has no percentage are returned unchanged, with an empty RevenueSplit.
+func (s *SafeHarbor) Apply(item RequestItem) ([]RequestItem, RevenueSplit, error) {
+	pct := s.Percent(item.TransTypeCode)
+	if pct == "" {
+		return []RequestItem{item}, RevenueSplit{}, nil
+	}
+	alloc, ok := s.Allocation(item.TransTypeCode)
+	if !ok {
+		return nil, RevenueSplit{}, &ValidationError{LineNumber: item.LineNumber, Field: "TransTypeCode",
+			Message: fmt.Sprintf("invalid interstate percentage %q for %s", pct, item.TransTypeCode)}
+	}
+
+	study := &TrafficStudy{
+		Allocations: map[string]TrafficAllocation{item.TransTypeCode: alloc},
+		TransTypes:  s.TransTypes,
+		Source:      s.Source,
+		AuditField:  s.AuditField,
+	}
+	items, err := study.Allocate(item)
+	if err != nil {
+		return nil, RevenueSplit{}, err
+	}
+
+	// Allocate leaves out the parts with a zero share
+	zero := formatAmount(new(big.Rat), splitPlaces(item.Revenue))
+	split := RevenueSplit{Percent: pct, Interstate: zero, Intrastate: zero}
+	rest := items
+	if p, _ := parseAmount(alloc.Interstate); p.Sign() > 0 {
+		split.Interstate, rest = rest[0].Revenue, rest[1:]
+	}
+	if len(rest) > 0 {
+		split.Intrastate = rest[0].Revenue
+	}
+
+	return items, split, nil
+}
+
+// Replaces the items of req by their safe harbor sub-items. TotalRevenue is unchanged since
+// the sub-items add up to the original revenue.
+func (s *SafeHarbor) ApplyRequest(req *Request) error {
+	items := make([]RequestItem, 0, len(req.ItemList))
+	for _, item := range req.ItemList {
+		subs, _, err := s.Apply(item)
+		if err != nil {
+			return err
+		}
+		items = append(items, subs...)
+	}
+	req.ItemList = items
+	return nil
+}
+
+// Appends record to the given UDF field of item (UDF2 by default), separated by "|".
+func recordUDF(item *RequestItem, field, record string) error {
+	target := &item.UDF2
+	switch field {
+	case "", "UDF2":
+	case "UDF":
+		target = &item.UDF
+	default:
+		return fmt.Errorf("Unknown UDF field %q", field)
+	}
+
+	value := record
+	if *target != "" {
+		value = *target + "|" + record
+	}
+	if len(value) > 100 {
+		name := field
+		if name == "" {
+			name = "UDF2"
+		}
+		return &ValidationError{LineNumber: item.LineNumber, Field: name, Message: "no room left to record " + record}
+	}
+	*target = value
+	return nil
+}
@@ -0,0 +1,81 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_SafeHarbor_Apply(t *testing.T) {
+
+	sh := &SafeHarbor{
+		Interstate: map[string]string{"050104": SafeHarborVoIP},
+		TransTypes: map[string]JurisdictionTransTypes{"050104": {Interstate: "050204", Intrastate: "050104"}},
+		Source:     "FCC",
+	}
+
+	item := getTestRequest().ItemList[0]
+	item.TransTypeCode = "050104"
+	item.Revenue = "100.01"
+	item.UDF2 = "ref-1"
+
+	items, split, err := sh.Apply(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if split.Percent != "64.9" || split.Interstate != "64.9065" || split.Intrastate != "35.1035" {
+		t.Fatalf("Expected 64.9065/35.1035 but got %+v", split)
+	}
+	expected := []struct{ line, code, revenue, udf string }{
+		{"01-inter", "050204", "64.9065", "ref-1|TS:FCC:interstate:64.9"},
+		{"01-intra", "050104", "35.1035", "ref-1|TS:FCC:intrastate:35.1"},
+	}
+	if len(items) != len(expected) {
+		t.Fatalf("Expected %d sub-items but got %+v", len(expected), items)
+	}
+	for i, e := range expected {
+		it := items[i]
+		if it.LineNumber != e.line || it.TransTypeCode != e.code || it.Revenue != e.revenue || it.UDF2 != e.udf {
+			t.Fatalf("Expected %+v but got %s %s %s %s", e, it.LineNumber, it.TransTypeCode, it.Revenue, it.UDF2)
+		}
+	}
+
+	other := RequestItem{TransTypeCode: "010101", Revenue: "10"}
+	if items, split, err := sh.Apply(other); err != nil || len(items) != 1 || items[0].Revenue != "10" || items[0].UDF2 != "" || split.Percent != "" {
+		t.Fatalf("Expected no split for a trans type without percentage but got %+v, %v", items, err)
+	}
+
+	sh.Default = "120"
+	if _, _, err := sh.Apply(other); !IsValidationError(err) {
+		t.Fatalf("Expected a validation error for an invalid percentage but got %v", err)
+	}
+
+	sh.Default = SafeHarborWireless
+	if _, _, err := sh.Apply(other); !IsValidationError(err) {
+		t.Fatalf("Expected a validation error for a trans type without codes but got %v", err)
+	}
+
+	sh.Default = "100"
+	sh.TransTypes["010101"] = JurisdictionTransTypes{Interstate: "010201"}
+	if items, split, err := sh.Apply(other); err != nil || len(items) != 1 || split.Interstate != "10.0000" || split.Intrastate != "0.0000" {
+		t.Fatalf("Expected a fully interstate item but got %+v, %+v, %v", items, split, err)
+	}
+}
+
+func Test_SafeHarbor_ApplyRequest(t *testing.T) {
+
+	sh := &SafeHarbor{
+		Default:    SafeHarborWireless,
+		TransTypes: map[string]JurisdictionTransTypes{"010101": {Interstate: "010201", Intrastate: "010101"}},
+		AuditField: "UDF",
+	}
+
+	req := getTestRequest()
+	req.ItemList[0].TransTypeCode = "010101"
+	req.ItemList[0].Revenue = "10"
+	if err := sh.ApplyRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.ItemList) != 2 || req.ItemList[0].Revenue != "3.7100" || req.ItemList[1].Revenue != "6.2900" ||
+		req.ItemList[0].TransTypeCode != "010201" || req.ItemList[1].UDF != "TS:intrastate:62.9" {
+		t.Fatalf("Expected revenue split 3.71/6.29 but got %+v", req.ItemList)
+	}
+}
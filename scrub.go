@@ -0,0 +1,81 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Replaces secrets removed by the scrub functions.
+const Redacted = "[REDACTED]"
+
+// JSON fields holding account credentials.
+var secretFields = map[string]bool{
+	"clientnumber":  true,
+	"validationkey": true,
+}
+
+// Secret fields of payloads that aren't valid JSON, e.g. truncated, including those quoted inside a JSON string.
+var secretFieldPattern = regexp.MustCompile(`(?i)(\\*"(?:ClientNumber|ValidationKey)\\*"\s*:\s*\\*")((?:[^"\\]|\\[^"])*)(\\*"|$)`)
+
+// Returns payload with the ClientNumber and ValidationKey values replaced with Redacted, including those
+// of requests wrapped for SureTax, whose JSON is quoted in a string. Meant for every stored artifact:
+// debug dumps, recorded fixtures, archives.
+func ScrubPayload(payload []byte) []byte {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return secretFieldPattern.ReplaceAll(payload, []byte("${1}"+Redacted+"${3}"))
+	}
+
+	out, err := json.Marshal(scrubValue(v))
+	if err != nil {
+		return secretFieldPattern.ReplaceAll(payload, []byte("${1}"+Redacted+"${3}"))
+	}
+	return out
+}
+
+func scrubValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if secretFields[strings.ToLower(k)] {
+				if s, ok := e.(string); !ok || s != "" {
+					v[k] = Redacted
+				}
+				continue
+			}
+			v[k] = scrubValue(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = scrubValue(e)
+		}
+	case string:
+		// the wrapped request or response
+		if t := strings.TrimSpace(v); strings.HasPrefix(t, "{") || strings.HasPrefix(t, "[") {
+			return string(ScrubPayload([]byte(v)))
+		}
+	}
+	return v
+}
+
+// Returns a copy of h with credentials removed: Authorization, Proxy-Authorization,
+// and any other header carrying a bearer token.
+func ScrubHeaders(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, values := range h {
+		values = append([]string(nil), values...)
+		ck := http.CanonicalHeaderKey(k)
+		for i, v := range values {
+			if ck == "Authorization" || ck == "Proxy-Authorization" || strings.HasPrefix(strings.ToLower(strings.TrimSpace(v)), "bearer ") {
+				values[i] = Redacted
+			}
+		}
+		c[k] = values
+	}
+	return c
+}
@@ -0,0 +1,85 @@
+package suretax
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	testClientNumber  = "000000777"
+	testValidationKey = "f3b2c1d0-secret-key"
+)
+
+func secretRequest() *Request {
+	req := getTestRequest()
+	req.ClientNumber, req.ValidationKey = testClientNumber, testValidationKey
+	return req
+}
+
+func checkScrubbed(t *testing.T, what string, data []byte) {
+	t.Helper()
+	for _, secret := range []string{testClientNumber, testValidationKey, "bearer-token"} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Fatalf("Expected %v without %v but got %s", what, secret, data)
+		}
+	}
+}
+
+func Test_ScrubPayload(t *testing.T) {
+
+	cli := &SuretaxClient{}
+	r, err := cli.buildRequest(secretRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := requestBody(r)
+
+	out := ScrubPayload([]byte(body))
+	checkScrubbed(t, "the wrapped request", out)
+	if !bytes.Contains(out, []byte(Redacted)) || !bytes.Contains(out, []byte(`\"Revenue\":\"100\"`)) {
+		t.Fatalf("Expected the rest of the request to be kept but got %s", out)
+	}
+
+	cancel, _ := cli.buildCancelRequest(&CancelRequest{ClientNumber: testClientNumber, ValidationKey: testValidationKey, TransId: "1"})
+	body, _ = requestBody(cancel)
+	checkScrubbed(t, "the cancel request", ScrubPayload([]byte(body)))
+
+	truncated := body[:len(body)-5]
+	checkScrubbed(t, "invalid JSON", ScrubPayload([]byte(truncated)))
+}
+
+func Test_ScrubHeaders(t *testing.T) {
+
+	h := http.Header{"Authorization": {"Basic bearer-token"}, "X-Token": {"Bearer bearer-token"}, "Content-Type": {"application/json"}}
+	out := ScrubHeaders(h)
+
+	b := new(bytes.Buffer)
+	out.Write(b)
+	checkScrubbed(t, "the headers", b.Bytes())
+	if out.Get("Content-Type") != "application/json" || h.Get("X-Token") != "Bearer bearer-token" {
+		t.Fatal("Expected other headers and the original to be kept")
+	}
+}
+
+func Test_TraceLogScrubbed(t *testing.T) {
+
+	ok := envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)
+	SetHttpClient(&fakeHttpClient{bodies: []string{ok, ok, envelope(`{"Successful":"Y","ResponseCode":"9999"}`)}})
+	defer SetHttpClient(nil)
+
+	var logged strings.Builder
+	SetTraceLogger(func(v ...interface{}) { fmt.Fprint(&logged, v...) })
+	defer SetTraceLogger(nil)
+
+	(&SuretaxClient{}).Send(secretRequest())
+	(&SuretaxClient{Privacy: PrivacyHash}).Send(secretRequest())
+	(&SuretaxClient{}).Cancel(&CancelRequest{ClientNumber: testClientNumber, ValidationKey: testValidationKey, TransId: "1"})
+
+	if !strings.Contains(logged.String(), "Request Data") {
+		t.Fatal("Expected the payloads to be logged")
+	}
+	checkScrubbed(t, "the trace log", []byte(logged.String()))
+}
@@ -0,0 +1,60 @@
+package suretax
+
+import (
+	"context"
+	"sync"
+)
+
+// Outcome of one request of SendAll.
+type Result struct {
+	Request  *Request
+	Response *Response
+
+	// Error returned by Send. Requests not sent because ctx ended have the context error.
+	Err error
+}
+
+// Sends reqs through a pool of at most maxConcurrency workers (1 if less) and returns one Result per request,
+// in the order of reqs. Unlike SendBatch, a failed request doesn't stop the others: errors are reported
+// per Result. The returned error is the context error if ctx ended before every request was sent.
+func (c *SuretaxClient) SendAll(ctx context.Context, reqs []*Request, maxConcurrency int) ([]*Result, error) {
+	results := make([]*Result, len(reqs))
+	for i, req := range reqs {
+		results[i] = &Result{Request: req}
+	}
+
+	workers := max(1, min(maxConcurrency, len(reqs)))
+	next := make(chan *Result)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range next {
+				r.Response, r.Err = c.SendContext(ctx, r.Request)
+			}
+		}()
+	}
+
+	var err error
+	for _, r := range results {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case next <- r:
+		case <-ctx.Done():
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if err = ctx.Err(); err != nil {
+		for _, r := range results {
+			if r.Response == nil && r.Err == nil {
+				r.Err = err
+			}
+		}
+	}
+	return results, err
+}
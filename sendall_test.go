@@ -0,0 +1,82 @@
+package suretax
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_SendAll(t *testing.T) {
+
+	var inFlight, peak int32
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		req := decodeTestRequest(t, r)
+		if req.ClientTracking == "bad" {
+			return okResponse(envelope(`{"ResponseCode":"1151","Successful":"N","HeaderMessage":"Failure - Invalid Validation Key"}`)), nil
+		}
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y","ClientTracking":"` + req.ClientTracking + `"}`)), nil
+	})
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport))
+
+	var reqs []*Request
+	for _, tracking := range []string{"a", "b", "bad", "c", "d", "e"} {
+		req := getTestRequest()
+		req.ClientTracking = tracking
+		reqs = append(reqs, req)
+	}
+
+	results, err := cli.SendAll(context.Background(), reqs, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r.Request != reqs[i] {
+			t.Fatalf("Expected result %v to be of request %v", i, i)
+		}
+		if i == 2 {
+			if r.Err == nil || r.Response == nil || r.Response.ResponseCode != "1151" {
+				t.Fatalf("Expected the declined request to fail but got %+v", r)
+			}
+			continue
+		}
+		if r.Err != nil || r.Response.ClientTracking != reqs[i].ClientTracking {
+			t.Fatalf("Expected the response of %v but got %+v", reqs[i].ClientTracking, r)
+		}
+	}
+	if p := atomic.LoadInt32(&peak); p > 3 {
+		t.Fatalf("Expected at most 3 requests in flight but got %v", p)
+	}
+}
+
+func Test_SendAll_Canceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	transport := httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		once.Do(cancel)
+		return okResponse(envelope(`{"ResponseCode":"9999","Successful":"Y"}`)), nil
+	})
+	cli := NewClient("https://cert/post", "https://cert/cancel", WithHttpClient(transport))
+
+	reqs := []*Request{getTestRequest(), getTestRequest(), getTestRequest()}
+	results, err := cli.SendAll(ctx, reqs, 1)
+	if err != context.Canceled {
+		t.Fatalf("Expected the context error but got %v", err)
+	}
+	if results[2].Err != context.Canceled || results[2].Response != nil {
+		t.Fatalf("Expected the last request not to be sent but got %+v", results[2])
+	}
+}
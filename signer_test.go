@@ -0,0 +1,66 @@
+package suretax
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_HMACSigner(t *testing.T) {
+
+	now := time.Unix(1700000000, 0)
+	signer := &HMACSigner{Key: []byte("secret"), KeyId: "k1", MaxSkew: time.Minute, now: func() time.Time { return now }}
+
+	var sent *http.Request
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		sent = r
+		return okResponse(envelope(decodeTestInner(t))), nil
+	}))
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{Signer: signer}
+	if _, err := cli.SendContext(context.Background(), getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Header.Get(DefaultSignatureHeader) == "" || sent.Header.Get(SignatureTimestampHeader) != "1700000000" || sent.Header.Get(SignatureKeyIdHeader) != "k1" {
+		t.Fatalf("Expected the signature headers but got %v", sent.Header)
+	}
+
+	r, _ := cli.buildRequest(getTestRequest())
+	if err := cli.sign(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.Verify(r); err != nil {
+		t.Fatalf("Expected the signature to verify but got %v", err)
+	}
+	if body, _ := requestBody(r); body == "" {
+		t.Fatal("Expected the body to remain readable after Verify")
+	}
+
+	tampered, _ := cli.buildRequest(&Request{ClientNumber: "000000002"})
+	tampered.Header = r.Header
+	if err := signer.Verify(tampered); err == nil {
+		t.Fatal("Expected a modified payload to be rejected")
+	}
+
+	now = now.Add(2 * time.Minute)
+	r, _ = cli.buildRequest(getTestRequest())
+	r.Header.Set(SignatureTimestampHeader, "1700000000")
+	r.Header.Set(DefaultSignatureHeader, signer.mac("1700000000", []byte(mustBody(t, r))))
+	if err := signer.Verify(r); err == nil {
+		t.Fatal("Expected a stale signature to be rejected")
+	}
+
+	if err := (&HMACSigner{}).Sign(r, nil); err == nil {
+		t.Fatal("Expected an error signing without a key")
+	}
+}
+
+func mustBody(t *testing.T, r *http.Request) string {
+	body, err := requestBody(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
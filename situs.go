@@ -0,0 +1,230 @@
+package suretax
+
+import (
+	"errors"
+	"strings"
+)
+
+// Rule SureTax uses to determine the taxing jurisdiction of an item, RequestItem.TaxSitusRule.
+type TaxSitusRule string
+
+const (
+	// Two-out-of-three test using the NPA-NXX of OrigNumber, TermNumber and BillToNumber.
+	SitusTwoOutOfThreeNPANXX TaxSitusRule = "01"
+
+	// BillToNumber locates the item.
+	SitusBilledToNumber TaxSitusRule = "02"
+
+	// OrigNumber locates the item.
+	SitusOriginationNumber TaxSitusRule = "03"
+
+	// ZIP code of the billing Address.
+	SitusZipCode TaxSitusRule = "04"
+
+	// ZIP+4 of the billing Address.
+	SitusZipPlus4 TaxSitusRule = "05"
+
+	// ZIP codes of Address and P2PAddress, for private line transactions.
+	SitusPointToPoint TaxSitusRule = "07"
+
+	// Two-out-of-three test using the phone numbers, with the billing ZIP+4 as the taxing jurisdiction.
+	SitusTwoOutOfThreeZipPlus4 TaxSitusRule = "09"
+
+	// ZIP+4 of Address as the billing location and of P2PAddress as the service location.
+	SitusBillingServiceZipPlus4 TaxSitusRule = "11"
+
+	// Address.PostalCode holds the international country code, for VAT calculations.
+	SitusVATCountryCode TaxSitusRule = "14"
+
+	// Same as SitusPointToPoint, with taxes calculated on both the A and Z endpoints.
+	SitusPointToPointBothEnds TaxSitusRule = "17"
+
+	// Billing Address or its ZIP+4 only.
+	SitusBillingAddress TaxSitusRule = "27"
+)
+
+var situsRules = map[TaxSitusRule]string{
+	SitusTwoOutOfThreeNPANXX:    "Two-out-of-three test using NPA-NXX",
+	SitusBilledToNumber:         "Billed to number",
+	SitusOriginationNumber:      "Origination number",
+	SitusZipCode:                "Zip code",
+	SitusZipPlus4:               "Zip code + 4",
+	SitusPointToPoint:           "Point to Point Zip codes",
+	SitusTwoOutOfThreeZipPlus4:  "Two-out-of-three test using Zip+4",
+	SitusBillingServiceZipPlus4: "Billing and service location Zip+4",
+	SitusVATCountryCode:         "Zip code as international country code",
+	SitusPointToPointBothEnds:   "Point to Point Zip codes, both endpoints",
+	SitusBillingAddress:         "Billing Address / Zip+4 only",
+}
+
+// Reports whether r is a rule SureTax knows.
+func (r TaxSitusRule) Valid() bool {
+	_, ok := situsRules[r]
+	return ok
+}
+
+// Returns the SureTax description of r, empty for an unknown rule.
+func (r TaxSitusRule) Description() string {
+	return situsRules[r]
+}
+
+// Checks that the fields required by the TaxSitusRule of every item of req are present and well-formed,
+// and that the rule is known. Items without a TaxSitusRule are not checked.
+// Returns one *ValidationError per problem found, nil if there is none.
+func ValidateSitus(req *Request) []*ValidationError {
+	var errs []*ValidationError
+	for i := range req.ItemList {
+		errs = append(errs, req.ItemList[i].ValidateSitus()...)
+	}
+	return errs
+}
+
+// Same as ValidateSitus for a single item.
+func (item *RequestItem) ValidateSitus() []*ValidationError {
+	if item.TaxSitusRule == "" {
+		return nil
+	}
+	rule, ok := situsRules[item.TaxSitusRule]
+	if !ok {
+		return []*ValidationError{{LineNumber: item.LineNumber, Field: "TaxSitusRule", Message: "is not a known rule: " + string(item.TaxSitusRule)}}
+	}
+
+	v := situsValidator{item: item, rule: string(item.TaxSitusRule) + " (" + rule + ")"}
+	billing := &item.Address
+	service := Address(item.P2PAddress)
+
+	switch item.TaxSitusRule {
+	case SitusTwoOutOfThreeNPANXX:
+		v.phones(item.OrigNumber, item.TermNumber, item.BillToNumber)
+	case SitusBilledToNumber:
+		v.phone("BillToNumber", item.BillToNumber)
+	case SitusOriginationNumber:
+		v.phone("OrigNumber", item.OrigNumber)
+	case SitusZipCode:
+		v.zip("Address", billing, false)
+	case SitusZipPlus4:
+		v.zip("Address", billing, true)
+	case SitusPointToPoint, SitusPointToPointBothEnds:
+		v.zip("Address", billing, false)
+		v.zip("P2PAddress", &service, false)
+	case SitusTwoOutOfThreeZipPlus4:
+		for _, f := range []struct{ name, value string }{
+			{"OrigNumber", item.OrigNumber},
+			{"TermNumber", item.TermNumber},
+			{"BillToNumber", item.BillToNumber},
+		} {
+			if f.value == "" {
+				v.fail(f.name, "is required")
+			}
+		}
+		v.zip("Address", billing, true)
+	case SitusBillingServiceZipPlus4:
+		// Address is the billing location, P2PAddress the service location
+		v.zip("Address", billing, true)
+		v.zip("P2PAddress", &service, true)
+	case SitusVATCountryCode:
+		if billing.PostalCode == "" {
+			v.fail("Address.PostalCode", "is required, holding the country code")
+		}
+	case SitusBillingAddress:
+		if billing.Geocode == "" && billing.PostalCode == "" && billing.PrimaryAddressLine != "" && billing.City != "" && billing.State != "" {
+			// the street address is enough, the ZIP code is looked up
+			v.plus4("Address", billing, false)
+			break
+		}
+		v.zip("Address", billing, false)
+	}
+
+	return v.errs
+}
+
+type situsValidator struct {
+	item *RequestItem
+	rule string
+	errs []*ValidationError
+}
+
+func (v *situsValidator) fail(field, message string) {
+	v.errs = append(v.errs, &ValidationError{
+		LineNumber: v.item.LineNumber,
+		Field:      field,
+		Message:    message + " for TaxSitusRule " + v.rule,
+	})
+}
+
+func (v *situsValidator) phone(field, number string) {
+	switch {
+	case number == "":
+		v.fail(field, "is required")
+	case !isPhoneNumber(number):
+		v.fail(field, "must be a 10 digit NPANXXNNNN number")
+	}
+}
+
+func (v *situsValidator) phones(orig, term, billTo string) {
+	v.phone("OrigNumber", orig)
+	v.phone("TermNumber", term)
+	v.phone("BillToNumber", billTo)
+}
+
+// Postal code formats are only checked for US addresses.
+func isUSAddress(a *Address) bool {
+	return a.Country == "" || strings.EqualFold(a.Country, "US") || strings.EqualFold(a.Country, "USA")
+}
+
+// Checks PostalCode and Plus4 of a. A Geocode takes precedence over both.
+func (v *situsValidator) zip(prefix string, a *Address, plus4Required bool) {
+	if a.Geocode != "" {
+		return
+	}
+	v.postalCode(prefix, a)
+	v.plus4(prefix, a, plus4Required)
+}
+
+func (v *situsValidator) postalCode(prefix string, a *Address) {
+	field := prefix + ".PostalCode"
+	switch {
+	case a.PostalCode == "":
+		v.fail(field, "is required")
+	case !isUSAddress(a):
+	case len(a.PostalCode) == 10 && a.PostalCode[5] == '-' && isDigits(a.PostalCode[:5]) && isDigits(a.PostalCode[6:]):
+		v.fail(field, "must be the 5 digit ZIP code, move the +4 extension to Plus4")
+	case len(a.PostalCode) != 5 || !isDigits(a.PostalCode):
+		v.fail(field, "must be a 5 digit ZIP code")
+	}
+}
+
+func (v *situsValidator) plus4(prefix string, a *Address, required bool) {
+	field := prefix + ".Plus4"
+	switch {
+	case a.Plus4 == "":
+		if required {
+			v.fail(field, "is required")
+		}
+	case !isUSAddress(a):
+	case len(a.Plus4) != 4 || !isDigits(a.Plus4):
+		v.fail(field, "must be 4 digits")
+	}
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Returns the situs errors of req joined into one error, or nil.
+func situsError(req *Request) error {
+	verrs := ValidateSitus(req)
+	if len(verrs) == 0 {
+		return nil
+	}
+	errs := make([]error, len(verrs))
+	for i, e := range verrs {
+		errs[i] = e
+	}
+	return errors.Join(errs...)
+}
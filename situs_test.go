@@ -0,0 +1,93 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_ValidateSitus(t *testing.T) {
+
+	cases := []struct {
+		rule   TaxSitusRule
+		edit   func(item *RequestItem)
+		fields []string
+	}{
+		{"01", func(item *RequestItem) {}, nil},
+		{SitusTwoOutOfThreeNPANXX, func(item *RequestItem) { item.TermNumber = "904-310-1723" }, []string{"TermNumber"}},
+		{SitusBilledToNumber, func(item *RequestItem) { item.BillToNumber = "" }, []string{"BillToNumber"}},
+		{SitusOriginationNumber, func(item *RequestItem) {}, nil},
+		{SitusPointToPoint, func(item *RequestItem) { item.Address.PostalCode = "32034" }, []string{"P2PAddress.PostalCode"}},
+		{SitusVATCountryCode, func(item *RequestItem) {}, []string{"Address.PostalCode"}},
+		{"99", func(item *RequestItem) {}, []string{"TaxSitusRule"}},
+		{"", func(item *RequestItem) {}, nil},
+		{"04", func(item *RequestItem) { item.Address.PostalCode = "32034" }, nil},
+		{"04", func(item *RequestItem) {}, []string{"Address.PostalCode"}},
+		{"04", func(item *RequestItem) { item.Address.PostalCode = "32034-1234" }, []string{"Address.PostalCode"}},
+		{"04", func(item *RequestItem) { item.Address.Geocode = "US12089" }, nil},
+		{"04", func(item *RequestItem) { item.Address.PostalCode = "K1A 0B1"; item.Address.Country = "CA" }, nil},
+		{"05", func(item *RequestItem) { item.Address.PostalCode = "32034" }, []string{"Address.Plus4"}},
+		{"05", func(item *RequestItem) { item.Address.PostalCode = "32034"; item.Address.Plus4 = "12a4" }, []string{"Address.Plus4"}},
+		{"05", func(item *RequestItem) { item.Address.PostalCode = "32034"; item.Address.Plus4 = "1234" }, nil},
+		{"09", func(item *RequestItem) {
+			item.TermNumber = ""
+			item.Address.PostalCode = "32034"
+			item.Address.Plus4 = "1234"
+		}, []string{"TermNumber"}},
+		{"11", func(item *RequestItem) {
+			item.Address.PostalCode = "32034"
+			item.Address.Plus4 = "1234"
+			item.P2PAddress.PostalCode = "3203"
+		}, []string{"P2PAddress.PostalCode", "P2PAddress.Plus4"}},
+		{"27", func(item *RequestItem) {
+			item.Address.PrimaryAddressLine = "1 Main St"
+			item.Address.City = "Fernandina Beach"
+			item.Address.State = "FL"
+		}, nil},
+		{"27", func(item *RequestItem) { item.Address.City = "Fernandina Beach" }, []string{"Address.PostalCode"}},
+	}
+
+	for i, c := range cases {
+		req := getTestRequest()
+		item := &req.ItemList[0]
+		item.TaxSitusRule = c.rule
+		c.edit(item)
+
+		errs := ValidateSitus(req)
+		if len(errs) != len(c.fields) {
+			t.Fatalf("Expected %d errors for case %d but got %v", len(c.fields), i, errs)
+		}
+		for j, e := range errs {
+			if e.Field != c.fields[j] || e.LineNumber != item.LineNumber {
+				t.Fatalf("Expected an error for %s on line %s in case %d but got %v", c.fields[j], item.LineNumber, i, e)
+			}
+		}
+	}
+}
+
+func Test_Send_CheckSitus(t *testing.T) {
+
+	fake := &fakeHttpClient{}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	req := getTestRequest()
+	req.ItemList[0].TaxSitusRule = "05"
+
+	cli := &SuretaxClient{CheckSitus: true}
+	_, err := cli.Send(req)
+	if !IsValidationError(err) {
+		t.Fatalf("Expected a validation error but got %v", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Fatal("Expected the request not to be sent")
+	}
+}
+
+func Test_TaxSitusRule(t *testing.T) {
+
+	if !SitusZipPlus4.Valid() || SitusZipPlus4.Description() != "Zip code + 4" {
+		t.Fatalf("Expected rule 05 to be known but got %q", SitusZipPlus4.Description())
+	}
+	if TaxSitusRule("06").Valid() {
+		t.Fatal("Expected rule 06 to be unknown")
+	}
+}
@@ -0,0 +1,48 @@
+package suretax
+
+// A TaxSitusRule suggested for an item, with the reason it was chosen.
+type SitusRecommendation struct {
+	// Empty if the item lacks the data any rule needs
+	Rule   TaxSitusRule
+	Reason string
+}
+
+// Recommends the TaxSitusRule best suited to the data available on item, following the SureTax
+// documentation: international transactions use the country code, private lines both endpoints,
+// calls the two-out-of-three NPA-NXX test, and everything else the most precise billing location.
+// Only checks which fields are present and well-formed, the current TaxSitusRule is ignored.
+func RecommendSitus(item *RequestItem) SitusRecommendation {
+	a, p2p := &item.Address, Address(item.P2PAddress)
+
+	orig, term, billTo := isPhoneNumber(item.OrigNumber), isPhoneNumber(item.TermNumber), isPhoneNumber(item.BillToNumber)
+
+	switch {
+	case a.Country != "" && !isUSAddress(a):
+		return SitusRecommendation{SitusVATCountryCode, "the billing address is outside the US, so the country code drives VAT calculations"}
+	case hasZip(&p2p) && hasZip(a):
+		return SitusRecommendation{SitusPointToPoint, "both endpoints have ZIP codes, as for private line (point to point) transactions; use 17 to calculate taxes on both ends"}
+	case orig && term && billTo:
+		return SitusRecommendation{SitusTwoOutOfThreeNPANXX, "originating, terminating and billed-to numbers are all available for the two-out-of-three test"}
+	case hasZip(a) && isDigits(a.Plus4) && len(a.Plus4) == 4:
+		return SitusRecommendation{SitusZipPlus4, "the billing ZIP+4 is the most precise location available"}
+	case !hasZip(a) && a.PrimaryAddressLine != "" && a.City != "" && a.State != "":
+		return SitusRecommendation{SitusBillingAddress, "only the billing street address is available, SureTax looks up its ZIP+4"}
+	case hasZip(a):
+		return SitusRecommendation{SitusZipCode, "the billing ZIP code is available but not its +4 extension"}
+	case billTo:
+		return SitusRecommendation{SitusBilledToNumber, "only the billed-to number is available"}
+	case orig:
+		return SitusRecommendation{SitusOriginationNumber, "only the originating number is available"}
+	}
+
+	return SitusRecommendation{"", "no phone numbers, ZIP code or billing address to locate the transaction"}
+}
+
+func hasZip(a *Address) bool {
+	return len(a.PostalCode) == 5 && isDigits(a.PostalCode)
+}
+
+// NPANXXNNNN
+func isPhoneNumber(s string) bool {
+	return len(s) == 10 && isDigits(s)
+}
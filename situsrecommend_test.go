@@ -0,0 +1,35 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_RecommendSitus(t *testing.T) {
+
+	cases := []struct {
+		item RequestItem
+		rule TaxSitusRule
+	}{
+		{RequestItem{OrigNumber: "9043101723", TermNumber: "9043101724", BillToNumber: "9043101725"}, "01"},
+		{RequestItem{BillToNumber: "9043101725"}, "02"},
+		{RequestItem{OrigNumber: "9043101723", BillToNumber: "904"}, "03"},
+		{RequestItem{Address: Address{PostalCode: "32034"}}, "04"},
+		{RequestItem{Address: Address{PostalCode: "32034", Plus4: "1234"}, BillToNumber: "9043101725"}, "05"},
+		{RequestItem{Address: Address{PostalCode: "32034"}, P2PAddress: P2PAddress{PostalCode: "30301"}}, "07"},
+		{RequestItem{Address: Address{PostalCode: "SW1A", Country: "GB"}}, "14"},
+		{RequestItem{Address: Address{PrimaryAddressLine: "1 Main St", City: "Fernandina Beach", State: "FL"}}, "27"},
+		{RequestItem{}, ""},
+	}
+
+	for i, c := range cases {
+		rec := RecommendSitus(&c.item)
+		if rec.Rule != c.rule || rec.Reason == "" {
+			t.Fatalf("Expected rule %q for case %d but got %+v", c.rule, i, rec)
+		}
+
+		c.item.TaxSitusRule = rec.Rule
+		if errs := c.item.ValidateSitus(); len(errs) != 0 {
+			t.Fatalf("Expected the recommended rule to pass validation in case %d but got %v", i, errs)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package suretax
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits watched by an SLOMonitor. A zero limit is not applied.
+type SLOThresholds struct {
+	// Fraction of failed calls, e.g. 0.05 for 5%.
+	ErrorRate float64
+
+	// 99th percentile latency of the calls.
+	P99Latency time.Duration
+
+	// Calls needed in the window before thresholds are evaluated, so a single early failure doesn't alert.
+	MinCalls int
+}
+
+// Health of the calls in the monitor window.
+type SLOStatus struct {
+	Calls     int
+	Errors    int
+	ErrorRate float64
+	P99       time.Duration
+
+	// Thresholds currently exceeded: "ErrorRate", "P99Latency".
+	Breached []string
+}
+
+// Passed to the alert callback whenever the breached thresholds change: when one is first breached,
+// and, with no Breached, once they recover.
+type SLOAlert struct {
+	SLOStatus
+	Time time.Time
+}
+
+type sloSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+// Tracks the error rate and p99 latency of SureTax calls over a rolling window and calls OnAlert
+// when thresholds are breached. Set as SuretaxClient.Monitor. Safe for concurrent use.
+type SLOMonitor struct {
+	window     time.Duration
+	thresholds SLOThresholds
+	onAlert    func(SLOAlert)
+
+	mu       sync.Mutex
+	samples  []sloSample
+	breached string
+	now      func() time.Time
+}
+
+// Returns a monitor evaluating thresholds over the calls of the last window.
+func NewSLOMonitor(window time.Duration, thresholds SLOThresholds, onAlert func(SLOAlert)) *SLOMonitor {
+	return &SLOMonitor{window: window, thresholds: thresholds, onAlert: onAlert, now: time.Now}
+}
+
+// Records a call that took latency and failed with err. Client-side and SureTax validation
+// failures are caused by the request, not the service, and count as successful calls.
+func (m *SLOMonitor) Record(latency time.Duration, err error) {
+	failed := err != nil && !IsValidationError(err) && !IsItemError(err)
+
+	m.mu.Lock()
+	now := m.now()
+	m.samples = append(m.samples, sloSample{at: now, latency: latency, failed: failed})
+	m.prune(now)
+
+	status := m.status()
+	breached := strings.Join(status.Breached, ",")
+	changed := breached != m.breached
+	m.breached = breached
+	m.mu.Unlock()
+
+	if changed && m.onAlert != nil {
+		m.onAlert(SLOAlert{SLOStatus: status, Time: now})
+	}
+}
+
+// Returns the health of the calls currently in the window.
+func (m *SLOMonitor) Status() SLOStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune(m.now())
+	return m.status()
+}
+
+func (m *SLOMonitor) prune(now time.Time) {
+	cutoff := now.Add(-m.window)
+	i := 0
+	for i < len(m.samples) && !m.samples[i].at.After(cutoff) {
+		i++
+	}
+	if i > 0 {
+		m.samples = append(m.samples[:0], m.samples[i:]...)
+	}
+}
+
+func (m *SLOMonitor) status() SLOStatus {
+	s := SLOStatus{Calls: len(m.samples)}
+	if s.Calls == 0 {
+		return s
+	}
+
+	latencies := make([]time.Duration, len(m.samples))
+	for i, sample := range m.samples {
+		latencies[i] = sample.latency
+		if sample.failed {
+			s.Errors++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	// nearest-rank percentile
+	rank := (99*len(latencies) + 99) / 100
+	s.P99 = latencies[rank-1]
+	s.ErrorRate = float64(s.Errors) / float64(s.Calls)
+
+	if s.Calls < m.thresholds.MinCalls {
+		return s
+	}
+	if m.thresholds.ErrorRate > 0 && s.ErrorRate > m.thresholds.ErrorRate {
+		s.Breached = append(s.Breached, "ErrorRate")
+	}
+	if m.thresholds.P99Latency > 0 && s.P99 > m.thresholds.P99Latency {
+		s.Breached = append(s.Breached, "P99Latency")
+	}
+	return s
+}
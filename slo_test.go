@@ -0,0 +1,58 @@
+package suretax
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func Test_SLOMonitor(t *testing.T) {
+
+	var alerts []SLOAlert
+	m := NewSLOMonitor(time.Minute, SLOThresholds{ErrorRate: 0.3, P99Latency: time.Second, MinCalls: 3}, func(a SLOAlert) {
+		alerts = append(alerts, a)
+	})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	m.Record(10*time.Millisecond, io.ErrUnexpectedEOF)
+	m.Record(10*time.Millisecond, nil)
+	if len(alerts) != 0 {
+		t.Fatal("Expected no alert below MinCalls")
+	}
+
+	m.Record(10*time.Millisecond, &ValidationError{Field: "Revenue"})
+	if len(alerts) != 1 || alerts[0].Breached[0] != "ErrorRate" || alerts[0].Errors != 1 {
+		t.Fatalf("Expected an ErrorRate alert but got %+v", alerts)
+	}
+
+	m.Record(10*time.Millisecond, io.ErrUnexpectedEOF)
+	if len(alerts) != 1 {
+		t.Fatal("Expected a breach to alert once")
+	}
+
+	now = now.Add(2 * time.Minute)
+	m.Record(2*time.Second, nil)
+	m.Record(10*time.Millisecond, nil)
+	m.Record(10*time.Millisecond, nil)
+	if len(alerts) != 3 || len(alerts[1].Breached) != 0 || alerts[2].Breached[0] != "P99Latency" {
+		t.Fatalf("Expected the error rate to be recovered and a latency breach but got %+v", alerts)
+	}
+	if s := m.Status(); s.Calls != 3 || s.P99 != 2*time.Second {
+		t.Fatalf("Expected 3 calls with p99 2s but got %+v", s)
+	}
+}
+
+func Test_SLOMonitor_Send(t *testing.T) {
+
+	fake := &fakeHttpClient{err: io.ErrUnexpectedEOF}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{Monitor: NewSLOMonitor(time.Minute, SLOThresholds{}, nil)}
+	cli.Send(getTestRequest())
+
+	if s := cli.Monitor.Status(); s.Calls != 1 || s.Errors != 1 {
+		t.Fatalf("Expected one failed call but got %+v", s)
+	}
+}
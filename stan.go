@@ -0,0 +1,70 @@
+package suretax
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// Default period during which a STAN may not be reused.
+const DefaultStanWindow = 24 * time.Hour
+
+// Returns a new 16 character STAN made of a 40 bit millisecond timestamp followed
+// by 40 random bits, encoded with Crockford's base32.
+func NewStan() string {
+	var b [10]byte
+
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 4; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+
+	if _, err := rand.Read(b[5:]); err != nil {
+		panic("suretax: unable to read random bytes: " + err.Error())
+	}
+
+	return encodeCrockford(b[:], 16)
+}
+
+func stanKey(req *Request) string {
+	return req.ClientNumber + "/" + req.STAN
+}
+
+// Rejects a request whose STAN was already used by the same client number within the window.
+func checkStan(store DedupeStore, window time.Duration, req *Request) error {
+
+	if store == nil || req.STAN == "" {
+		return nil
+	}
+
+	if window <= 0 {
+		window = DefaultStanWindow
+	}
+
+	ok, err := store.Add(stanKey(req), window)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return &ValidationError{
+			Field:   "STAN",
+			Message: fmt.Sprintf("STAN %s was already used within %v", req.STAN, window),
+		}
+	}
+
+	return nil
+}
+
+// Releases the STAN recorded by checkStan for a request SureTax didn't accept, so it can be retried.
+func releaseStan(store DedupeStore, req *Request) {
+
+	if store == nil || req.STAN == "" {
+		return
+	}
+
+	if err := store.Delete(stanKey(req)); err != nil {
+		logger.Error("Releasing STAN failed", "STAN", req.STAN, "Error", err)
+	}
+}
@@ -0,0 +1,85 @@
+package suretax
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_NewStan(t *testing.T) {
+
+	a := NewStan()
+	b := NewStan()
+
+	if len(a) != 16 {
+		t.Fatalf("Expected STAN length %v but got %v", 16, len(a))
+	}
+
+	if a == b {
+		t.Fatalf("Expected unique STANs but got %v twice", a)
+	}
+}
+
+func Test_checkStan(t *testing.T) {
+
+	now := time.Date(2017, 5, 26, 0, 0, 0, 0, time.UTC)
+
+	store := NewMemoryDedupeStore()
+	store.now = func() time.Time { return now }
+
+	req := &Request{ClientNumber: "000000001", STAN: "STAN1"}
+
+	if err := checkStan(store, time.Hour, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := checkStan(store, time.Hour, req).(*ValidationError); !ok {
+		t.Fatal("Expected reused STAN to be rejected")
+	}
+
+	now = now.Add(time.Hour)
+
+	if err := checkStan(store, time.Hour, req); err != nil {
+		t.Fatalf("Expected STAN to be accepted after window but got %v", err)
+	}
+}
+
+func Test_Send_stanRetry(t *testing.T) {
+
+	var fail error
+	status := 200
+	cli := NewClient("", "", WithHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		if fail != nil {
+			return nil, fail
+		}
+		resp := okResponse(envelope(`{"Successful":"Y","ResponseCode":"9999","TransId":7,"TotalTax":"0","GroupList":[]}`))
+		resp.StatusCode, resp.Status = status, http.StatusText(status)
+		return resp, nil
+	})))
+	cli.StanStore = NewMemoryDedupeStore()
+
+	newRequest := func() *Request {
+		req := getTestRequest()
+		req.STAN = "STAN-1"
+		return req
+	}
+
+	status = 400
+	if _, err := cli.Send(newRequest()); !IsValidationError(err) {
+		t.Fatalf("Expected the 400 to be returned but got %v", err)
+	}
+	status = 200
+	fail = context.DeadlineExceeded
+	if _, err := cli.Send(newRequest()); !IsTransient(err) {
+		t.Fatalf("Expected the timeout to be returned but got %v", err)
+	}
+	fail = nil
+	if _, err := cli.Send(newRequest()); err != nil {
+		t.Fatalf("Expected the retry of a failed request to be sent but got %v", err)
+	}
+
+	if _, ok := checkStan(cli.StanStore, 0, newRequest()).(*ValidationError); !ok {
+		t.Fatal("Expected the STAN of the accepted request to be recorded")
+	}
+}
@@ -0,0 +1,115 @@
+package suretax
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Measurements of one Send call, reported to SuretaxClient.StatsHook once its response is decoded.
+type CallStats struct {
+	// Number of request items and of decoded response groups
+	Items  int
+	Groups int
+
+	// Size of the HTTP request and response bodies in bytes
+	RequestBytes  int64
+	ResponseBytes int64
+
+	// Time from sending the request until the response body was read
+	RoundTrip time.Duration
+
+	// Time spent decoding the response
+	Decode time.Duration
+
+	// Estimated heap size of the decoded response: its groups and taxes plus the response body,
+	// an upper bound of the strings they reference. Computed from the response alone, so it isn't
+	// skewed by concurrent calls and costs no stop of the world.
+	DecodedBytes int64
+}
+
+var (
+	responseSize = int64(reflect.TypeOf(Response{}).Size())
+	groupSize    = int64(reflect.TypeOf(Group{}).Size())
+	taxSize      = int64(reflect.TypeOf(Tax{}).Size())
+)
+
+// Returns the estimated heap size of res decoded from a body of bodyBytes, see CallStats.DecodedBytes.
+func decodedSize(res *Response, bodyBytes int64) int64 {
+	size := responseSize + bodyBytes
+	for i := range res.GroupList {
+		size += groupSize + int64(len(res.GroupList[i].TaxList))*taxSize
+	}
+	return size
+}
+
+// Aggregated CallStats of a batch run.
+type StatsSummary struct {
+	Calls int
+	Items int
+
+	PeakRequestBytes   int64
+	PeakResponseBytes  int64
+	TotalRequestBytes  int64
+	TotalResponseBytes int64
+
+	PeakDecode  time.Duration
+	TotalDecode time.Duration
+
+	PeakDecodedBytes  int64
+	TotalDecodedBytes int64
+
+	TotalRoundTrip time.Duration
+}
+
+// Collects CallStats across calls, e.g. the chunks of a batch run. Safe for concurrent use.
+//
+//	stats := &suretax.StatsCollector{}
+//	client.StatsHook = stats.Record
+type StatsCollector struct {
+	mu      sync.Mutex
+	summary StatsSummary
+}
+
+func (c *StatsCollector) Record(s CallStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sum := &c.summary
+	sum.Calls++
+	sum.Items += s.Items
+
+	sum.TotalRequestBytes += s.RequestBytes
+	if s.RequestBytes > sum.PeakRequestBytes {
+		sum.PeakRequestBytes = s.RequestBytes
+	}
+	sum.TotalResponseBytes += s.ResponseBytes
+	if s.ResponseBytes > sum.PeakResponseBytes {
+		sum.PeakResponseBytes = s.ResponseBytes
+	}
+
+	sum.TotalDecode += s.Decode
+	if s.Decode > sum.PeakDecode {
+		sum.PeakDecode = s.Decode
+	}
+	sum.TotalDecodedBytes += s.DecodedBytes
+	if s.DecodedBytes > sum.PeakDecodedBytes {
+		sum.PeakDecodedBytes = s.DecodedBytes
+	}
+
+	sum.TotalRoundTrip += s.RoundTrip
+}
+
+// Returns the stats recorded so far.
+func (c *StatsCollector) Summary() StatsSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.summary
+}
+
+// Clears the recorded stats.
+func (c *StatsCollector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.summary = StatsSummary{}
+}
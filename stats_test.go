@@ -0,0 +1,49 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_StatsHook(t *testing.T) {
+
+	small := envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)
+	large := envelope(decodeTestInner(t))
+	SetHttpClient(&fakeHttpClient{bodies: []string{large, small}})
+	defer SetHttpClient(nil)
+
+	var calls []CallStats
+	collector := &StatsCollector{}
+	cli := &SuretaxClient{StatsHook: func(s CallStats) {
+		calls = append(calls, s)
+		collector.Record(s)
+	}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cli.Send(getTestRequest()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 reported calls but got %d", len(calls))
+	}
+	if calls[0].Items != 1 || calls[0].Groups != 1 || calls[0].ResponseBytes != int64(len(large)) || calls[0].RequestBytes == 0 {
+		t.Fatalf("Expected sizes of the first call but got %+v", calls[0])
+	}
+	if calls[1].Groups != 0 || calls[1].ResponseBytes != int64(len(small)) {
+		t.Fatalf("Expected sizes of the second call but got %+v", calls[1])
+	}
+
+	sum := collector.Summary()
+	if sum.Calls != 2 || sum.Items != 2 || sum.PeakResponseBytes != int64(len(large)) || sum.TotalResponseBytes != int64(len(large)+len(small)) {
+		t.Fatalf("Expected aggregated stats but got %+v", sum)
+	}
+	if sum.TotalDecode < sum.PeakDecode || sum.TotalDecodedBytes < sum.PeakDecodedBytes || sum.PeakDecodedBytes <= int64(len(large)) {
+		t.Fatalf("Expected decode totals to include the peaks but got %+v", sum)
+	}
+
+	collector.Reset()
+	if collector.Summary().Calls != 0 {
+		t.Fatal("Expected Reset to clear the stats")
+	}
+}
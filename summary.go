@@ -0,0 +1,93 @@
+package suretax
+
+import "math/big"
+
+// Taxes of a response totalled along several dimensions. Amounts are exact sums formatted with
+// as many decimal places as the most precise TaxAmount they include.
+type TaxSummary struct {
+	Total string
+
+	// Keyed by Group.StateCode
+	ByState map[string]string
+
+	// Keyed by Tax.TaxAuthorityID
+	ByAuthority map[string]string
+
+	// Keyed by Tax.TaxTypeCode
+	ByTaxType map[string]string
+
+	// Keyed by the name of Tax.Category, e.g. "USF"
+	ByCategory map[string]string
+
+	// Keyed by Group.InvoiceNumber
+	ByInvoice map[string]string
+}
+
+type amountTotals struct {
+	totals   map[string]*big.Rat
+	decimals map[string]int
+}
+
+func newAmountTotals() *amountTotals {
+	return &amountTotals{totals: map[string]*big.Rat{}, decimals: map[string]int{}}
+}
+
+func (a *amountTotals) add(key string, amount *big.Rat, decimals int) {
+	t, ok := a.totals[key]
+	if !ok {
+		t = new(big.Rat)
+		a.totals[key] = t
+	}
+	t.Add(t, amount)
+	a.decimals[key] = max(a.decimals[key], decimals)
+}
+
+func (a *amountTotals) format() map[string]string {
+	sums := make(map[string]string, len(a.totals))
+	for k, t := range a.totals {
+		sums[k] = formatAmount(t, a.decimals[k])
+	}
+	return sums
+}
+
+// Totals the TaxAmount of every tax of the response by state, tax authority, tax type, category and invoice.
+func (r *Response) Summary() (*TaxSummary, error) {
+	groups, err := r.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	total := newAmountTotals()
+	states, authorities, types, invoices := newAmountTotals(), newAmountTotals(), newAmountTotals(), newAmountTotals()
+	categories := newAmountTotals()
+	for _, g := range groups {
+		for i := range g.TaxList {
+			t := &g.TaxList[i]
+			amt, err := parseAmount(t.TaxAmount)
+			if err != nil {
+				return nil, err
+			}
+			d := amountDecimals(t.TaxAmount)
+
+			total.add("", amt, d)
+			states.add(g.StateCode, amt, d)
+			authorities.add(t.TaxAuthorityID, amt, d)
+			types.add(t.TaxTypeCode, amt, d)
+			categories.add(t.Category().String(), amt, d)
+			invoices.add(g.InvoiceNumber, amt, d)
+		}
+	}
+
+	s := &TaxSummary{
+		Total:       "0",
+		ByState:     states.format(),
+		ByAuthority: authorities.format(),
+		ByTaxType:   types.format(),
+		ByCategory:  categories.format(),
+		ByInvoice:   invoices.format(),
+	}
+	if t, ok := total.format()[""]; ok {
+		s.Total = t
+	}
+	return s, nil
+}
@@ -0,0 +1,47 @@
+package suretax
+
+import "testing"
+
+func Test_Summary(t *testing.T) {
+
+	res := &Response{GroupList: []Group{
+		{StateCode: "IL", InvoiceNumber: "INV1", TaxList: []Tax{
+			{TaxTypeCode: "035", TaxAuthorityID: "17", TaxAmount: "1.25"},
+			{TaxTypeCode: "106", TaxAuthorityID: "17031", TaxAmount: "0.50"},
+		}},
+		{StateCode: "IL", InvoiceNumber: "INV2", TaxList: []Tax{
+			{TaxTypeCode: "035", TaxAuthorityID: "17", TaxAmount: "0.12345"},
+		}},
+		{StateCode: "FL", InvoiceNumber: "INV2", TaxList: []Tax{
+			{TaxTypeCode: "035", TaxAuthorityID: "12", TaxAmount: "0.10"},
+		}},
+	}}
+
+	s, err := res.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Total != "1.97345" {
+		t.Fatalf("Expected total 1.97345 but got %v", s.Total)
+	}
+	if s.ByState["IL"] != "1.87345" || s.ByState["FL"] != "0.10" {
+		t.Fatalf("Unexpected state totals %v", s.ByState)
+	}
+	if s.ByAuthority["17"] != "1.37345" || s.ByAuthority["17031"] != "0.50" {
+		t.Fatalf("Unexpected authority totals %v", s.ByAuthority)
+	}
+	if s.ByTaxType["035"] != "1.47345" || s.ByTaxType["106"] != "0.50" {
+		t.Fatalf("Unexpected tax type totals %v", s.ByTaxType)
+	}
+	if s.ByCategory["USF"] != "1.47345" || s.ByCategory["Other"] != "0.50" {
+		t.Fatalf("Unexpected category totals %v", s.ByCategory)
+	}
+	if s.ByInvoice["INV1"] != "1.75" || s.ByInvoice["INV2"] != "0.22345" {
+		t.Fatalf("Unexpected invoice totals %v", s.ByInvoice)
+	}
+
+	if s, err := (&Response{}).Summary(); err != nil || s.Total != "0" || len(s.ByState) != 0 {
+		t.Fatalf("Expected an empty summary but got %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package suretaxprom exports the metrics of SureTax calls in the Prometheus text format.
+//
+//	metrics := suretaxprom.NewCollector()
+//	client.Metrics = metrics
+//	http.Handle("/metrics", metrics)
+//
+// Exported series:
+//
+//	suretax_requests_total{endpoint}                  calls made
+//	suretax_errors_total{endpoint,response_code}      failed calls and calls answered with a code other than 9999
+//	suretax_request_duration_seconds{endpoint}        latency histogram
+//	suretax_items_total{endpoint}                     line items sent
+//
+// response_code is "none" for calls that failed before SureTax answered, e.g. on network or HTTP errors.
+package suretaxprom
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+// Latency buckets in seconds, the Prometheus client defaults.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type errorKey struct {
+	endpoint     string
+	responseCode string
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// suretax.Metrics collecting the calls of one or more clients, served over HTTP in the Prometheus text format.
+// Safe for concurrent use.
+type Collector struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	requests  map[string]uint64
+	errors    map[errorKey]uint64
+	items     map[string]uint64
+	durations map[string]*histogram
+}
+
+var _ suretax.Metrics = (*Collector)(nil)
+
+// Returns a collector with the given latency buckets in seconds, DefaultBuckets if none.
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Collector{
+		buckets:   buckets,
+		requests:  make(map[string]uint64),
+		errors:    make(map[errorKey]uint64),
+		items:     make(map[string]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+func (c *Collector) ObserveCall(m suretax.CallMetrics) {
+	seconds := m.Latency.Seconds()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests[m.Endpoint]++
+	c.items[m.Endpoint] += uint64(m.Items)
+
+	if !m.Success() {
+		code := m.ResponseCode
+		if code == "" {
+			code = "none"
+		}
+		c.errors[errorKey{m.Endpoint, code}]++
+	}
+
+	h, ok := c.durations[m.Endpoint]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.durations[m.Endpoint] = h
+	}
+	h.count++
+	h.sum += seconds
+	if i := sort.SearchFloat64s(c.buckets, seconds); i < len(c.buckets) {
+		h.counts[i]++
+	}
+}
+
+// Serves the metrics in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// Writes the metrics in the Prometheus text format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	fmt.Fprintln(cw, "# HELP suretax_requests_total SureTax calls made.")
+	fmt.Fprintln(cw, "# TYPE suretax_requests_total counter")
+	for _, endpoint := range sortedKeys(c.requests) {
+		fmt.Fprintf(cw, "suretax_requests_total{endpoint=%q} %d\n", endpoint, c.requests[endpoint])
+	}
+
+	fmt.Fprintln(cw, "# HELP suretax_errors_total SureTax calls failed or answered with a code other than 9999.")
+	fmt.Fprintln(cw, "# TYPE suretax_errors_total counter")
+	keys := make([]errorKey, 0, len(c.errors))
+	for k := range c.errors {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+		return keys[i].responseCode < keys[j].responseCode
+	})
+	for _, k := range keys {
+		fmt.Fprintf(cw, "suretax_errors_total{endpoint=%q,response_code=%q} %d\n", k.endpoint, k.responseCode, c.errors[k])
+	}
+
+	fmt.Fprintln(cw, "# HELP suretax_request_duration_seconds Latency of SureTax calls.")
+	fmt.Fprintln(cw, "# TYPE suretax_request_duration_seconds histogram")
+	for _, endpoint := range sortedKeys(c.durations) {
+		h := c.durations[endpoint]
+		var cumulative uint64
+		for i, le := range c.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(cw, "suretax_request_duration_seconds_bucket{endpoint=%q,le=%q} %d\n",
+				endpoint, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(cw, "suretax_request_duration_seconds_bucket{endpoint=%q,le=\"+Inf\"} %d\n", endpoint, h.count)
+		fmt.Fprintf(cw, "suretax_request_duration_seconds_sum{endpoint=%q} %s\n", endpoint, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(cw, "suretax_request_duration_seconds_count{endpoint=%q} %d\n", endpoint, h.count)
+	}
+
+	fmt.Fprintln(cw, "# HELP suretax_items_total Line items sent to SureTax.")
+	fmt.Fprintln(cw, "# TYPE suretax_items_total counter")
+	for _, endpoint := range sortedKeys(c.items) {
+		fmt.Fprintf(cw, "suretax_items_total{endpoint=%q} %d\n", endpoint, c.items[endpoint])
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
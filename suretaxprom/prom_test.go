@@ -0,0 +1,43 @@
+package suretaxprom
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+func Test_Collector(t *testing.T) {
+
+	c := NewCollector(0.1, 1)
+	c.ObserveCall(suretax.CallMetrics{Endpoint: suretax.UsageEndpointSend, ResponseCode: "9999", Latency: 50 * time.Millisecond, Items: 3})
+	c.ObserveCall(suretax.CallMetrics{Endpoint: suretax.UsageEndpointSend, ResponseCode: "9001", Latency: 500 * time.Millisecond, Items: 2})
+	c.ObserveCall(suretax.CallMetrics{Endpoint: suretax.UsageEndpointCancel, Err: errors.New("timeout"), Latency: 2 * time.Second})
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	out := rec.Body.String()
+	for _, line := range []string{
+		`suretax_requests_total{endpoint="Send"} 2`,
+		`suretax_requests_total{endpoint="Cancel"} 1`,
+		`suretax_errors_total{endpoint="Cancel",response_code="none"} 1`,
+		`suretax_errors_total{endpoint="Send",response_code="9001"} 1`,
+		`suretax_request_duration_seconds_bucket{endpoint="Send",le="0.1"} 1`,
+		`suretax_request_duration_seconds_bucket{endpoint="Send",le="1"} 2`,
+		`suretax_request_duration_seconds_bucket{endpoint="Cancel",le="1"} 0`,
+		`suretax_request_duration_seconds_bucket{endpoint="Cancel",le="+Inf"} 1`,
+		`suretax_request_duration_seconds_sum{endpoint="Send"} 0.55`,
+		`suretax_items_total{endpoint="Send"} 5`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("Expected %v in\n%v", line, out)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("Expected the Prometheus text format but got %v", ct)
+	}
+}
@@ -0,0 +1,100 @@
+// Package suretaxslog sends the logs of package suretax to log/slog with attribute names suited to log pipelines:
+// trans_id, client_tracking, response_code, latency_ms and so on.
+//
+//	suretaxslog.Install(slog.Default(), suretax.LevelInfo)
+package suretaxslog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+// suretax.Logger writing to a *slog.Logger.
+type Logger struct {
+	l *slog.Logger
+}
+
+var _ suretax.Logger = (*Logger)(nil)
+
+// Returns a Logger writing to l.
+func New(l *slog.Logger) *Logger {
+	return &Logger{l}
+}
+
+// Sends the package's messages up to the given level to l, see suretax.SetLogger.
+func Install(l *slog.Logger, level suretax.Level) {
+	suretax.SetLogger(New(l), level)
+}
+
+func (l *Logger) Debug(msg string, kv ...interface{}) {
+	l.log(slog.LevelDebug, msg, kv)
+}
+
+func (l *Logger) Info(msg string, kv ...interface{}) {
+	l.log(slog.LevelInfo, msg, kv)
+}
+
+func (l *Logger) Warn(msg string, kv ...interface{}) {
+	l.log(slog.LevelWarn, msg, kv)
+}
+
+func (l *Logger) Error(msg string, kv ...interface{}) {
+	l.log(slog.LevelError, msg, kv)
+}
+
+func (l *Logger) log(level slog.Level, msg string, kv []interface{}) {
+	ctx := context.Background()
+	if !l.l.Enabled(ctx, level) {
+		return
+	}
+	l.l.LogAttrs(ctx, level, msg, Attrs(kv)...)
+}
+
+// Returns the key-value fields of a suretax log message as slog attributes.
+// Keys are converted to snake case, durations to a _ms attribute in milliseconds
+// and errors to their message.
+func Attrs(kv []interface{}) []slog.Attr {
+	attrs := make([]slog.Attr, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok || i+1 == len(kv) {
+			attrs = append(attrs, slog.Any("!BADKEY", kv[i]))
+			i--
+			continue
+		}
+
+		name := snakeCase(key)
+		switch v := kv[i+1].(type) {
+		case time.Duration:
+			attrs = append(attrs, slog.Float64(name+"_ms", float64(v)/float64(time.Millisecond)))
+		case error:
+			attrs = append(attrs, slog.String(name, v.Error()))
+		default:
+			attrs = append(attrs, slog.Any(name, v))
+		}
+	}
+	return attrs
+}
+
+// Converts SureTax field names to snake case: TransId to trans_id, APIVersion to api_version.
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// a word starts at an upper case letter following a lower case one,
+			// or at the last letter of an acronym followed by a lower case one
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
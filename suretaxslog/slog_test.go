@@ -0,0 +1,55 @@
+package suretaxslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func Test_Logger(t *testing.T) {
+
+	var out bytes.Buffer
+	l := New(slog.New(slog.NewJSONHandler(&out, nil)))
+
+	l.Info("SureTax response", "TransId", 616039832, "ResponseCode", "9999", "ClientTracking", "Certi", "Latency", 1500*time.Microsecond)
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["msg"] != "SureTax response" || rec["trans_id"] != 616039832.0 || rec["response_code"] != "9999" ||
+		rec["client_tracking"] != "Certi" || rec["latency_ms"] != 1.5 {
+		t.Fatalf("Expected the structured attributes but got %v", rec)
+	}
+
+	out.Reset()
+	l.Debug("SureTax HTTP response", "StatusCode", 200)
+	if out.Len() != 0 {
+		t.Fatalf("Expected debug to be disabled by the handler but got %v", out.String())
+	}
+
+	l.Error("Ledger update failed", "TransId", 7, "Error", errors.New("disk full"))
+	rec = nil
+	json.Unmarshal(out.Bytes(), &rec)
+	if rec["level"] != "ERROR" || rec["error"] != "disk full" {
+		t.Fatalf("Expected the error message but got %v", rec)
+	}
+}
+
+func Test_snakeCase(t *testing.T) {
+
+	for in, expected := range map[string]string{
+		"TransId":       "trans_id",
+		"APIVersion":    "api_version",
+		"StatusCode":    "status_code",
+		"Url":           "url",
+		"validationKey": "validation_key",
+	} {
+		if s := snakeCase(in); s != expected {
+			t.Fatalf("Expected %v for %v but got %v", expected, in, s)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package suretaxtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+// Taxing jurisdiction levying one tax on every line of a response built by NewResponse.
+type Jurisdiction struct {
+	StateCode  string
+	CountyName string
+	CityName   string
+	Juriscode  string
+
+	TaxAuthorityID   string
+	TaxAuthorityName string
+	TaxTypeCode      string
+	TaxTypeDesc      string
+
+	// Tax rate in decimal format, e.g. "0.0625".
+	Rate string
+}
+
+// Jurisdictions used by NewResponse unless WithJurisdictions is given: Illinois, Cook County and Chicago.
+var DefaultJurisdictions = []Jurisdiction{
+	{StateCode: "IL", Juriscode: "17", TaxAuthorityID: "17", TaxAuthorityName: "STATE", TaxTypeCode: "035", TaxTypeDesc: "STATE SALES TAX", Rate: "0.0625"},
+	{StateCode: "IL", CountyName: "COOK", Juriscode: "17031", TaxAuthorityID: "17031", TaxAuthorityName: "COOK", TaxTypeCode: "036", TaxTypeDesc: "COUNTY SALES TAX", Rate: "0.0175"},
+	{StateCode: "IL", CountyName: "COOK", CityName: "CHICAGO", Juriscode: "1714000", TaxAuthorityID: "1714000", TaxAuthorityName: "CHICAGO", TaxTypeCode: "037", TaxTypeDesc: "CITY SALES TAX", Rate: "0.0125"},
+}
+
+type fixture struct {
+	lines         int
+	invoices      int
+	revenue       string
+	jurisdictions []Jurisdiction
+	transId       int
+	messages      []suretax.ItemMessage
+	req           *suretax.Request
+}
+
+// Configures a response built by NewResponse.
+type ResponseOption func(*fixture)
+
+// Makes the response tax n lines, numbered from 1. Defaults to 1.
+func WithLines(n int) ResponseOption {
+	return func(f *fixture) { f.lines = n }
+}
+
+// Spreads the lines over n invoices, INV1 to INVn, in turn. Defaults to 1.
+func WithInvoices(n int) ResponseOption {
+	return func(f *fixture) { f.invoices = n }
+}
+
+// Sets the Revenue of every line. Defaults to "100".
+func WithRevenue(revenue string) ResponseOption {
+	return func(f *fixture) { f.revenue = revenue }
+}
+
+// Makes every line taxed by each of the jurisdictions, in order. Defaults to DefaultJurisdictions.
+func WithJurisdictions(j ...Jurisdiction) ResponseOption {
+	return func(f *fixture) { f.jurisdictions = j }
+}
+
+// Sets TransId and MasterTransId. Defaults to 100000001.
+func WithTransId(id int) ResponseOption {
+	return func(f *fixture) { f.transId = id }
+}
+
+// Answers 9001 "Success with Item errors" with the messages, leaving their lines untaxed.
+func WithItemMessages(messages ...suretax.ItemMessage) ResponseOption {
+	return func(f *fixture) { f.messages = messages }
+}
+
+// Makes the response answer req: one line per item with its line, invoice and customer numbers,
+// Revenue and state, and req's ClientTracking and STAN. Overrides WithLines, WithInvoices and WithRevenue.
+func WithRequest(req *suretax.Request) ResponseOption {
+	return func(f *fixture) { f.req = req }
+}
+
+// Returns a successful response taxing every line once per jurisdiction, TaxAmount is Revenue * Rate
+// with 5 decimal places like SureTax returns it, and TotalTax their sum rounded to cents, so it passes Verify.
+//
+//	res := suretaxtest.NewResponse(suretaxtest.WithLines(20), suretaxtest.WithInvoices(4))
+//	body := suretaxtest.Encode(res)
+func NewResponse(opts ...ResponseOption) *suretax.Response {
+	f := &fixture{lines: 1, invoices: 1, revenue: "100", jurisdictions: DefaultJurisdictions, transId: 100000001}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	items := f.items()
+	failed := map[string]bool{}
+	for _, m := range f.messages {
+		failed[strings.TrimLeft(m.LineNumber, "0")] = true
+	}
+
+	res := &suretax.Response{
+		Successful:    "Y",
+		ResponseCode:  suretax.ResponseCodeSuccess,
+		HeaderMessage: "Success",
+		TransId:       f.transId,
+		MasterTransId: f.transId,
+		GroupList:     []suretax.Group{},
+		ItemMessages:  f.messages,
+	}
+	if f.req != nil {
+		res.ClientTracking, res.STAN = f.req.ClientTracking, f.req.STAN
+	}
+	if len(f.messages) > 0 {
+		res.ResponseCode, res.HeaderMessage = suretax.ResponseCodeSuccessWithItemErrors, "Success with Item errors"
+	}
+
+	total := new(big.Rat)
+	for _, item := range items {
+		if failed[strings.TrimLeft(item.LineNumber, "0")] {
+			continue
+		}
+		revenue, ok := new(big.Rat).SetString(item.Revenue)
+		if !ok {
+			revenue = new(big.Rat)
+		}
+
+		g := suretax.Group{
+			CustomerNumber: item.CustomerNumber,
+			InvoiceNumber:  item.InvoiceNumber,
+			LineNumber:     item.LineNumber,
+			StateCode:      item.Address.State,
+			TaxList:        make([]suretax.Tax, 0, len(f.jurisdictions)),
+		}
+		for _, j := range f.jurisdictions {
+			if g.StateCode == "" {
+				g.StateCode = j.StateCode
+			}
+			rate, ok := new(big.Rat).SetString(j.Rate)
+			if !ok {
+				rate = new(big.Rat)
+			}
+			amount := new(big.Rat).Mul(revenue, rate).FloatString(5)
+			exact, _ := new(big.Rat).SetString(amount)
+			total.Add(total, exact)
+
+			rateFloat, _ := rate.Float64()
+			g.TaxList = append(g.TaxList, suretax.Tax{
+				CityName:         j.CityName,
+				CountyName:       j.CountyName,
+				Juriscode:        j.Juriscode,
+				PercentTaxable:   1,
+				Revenue:          item.Revenue,
+				RevenueBase:      item.Revenue,
+				TaxAmount:        amount,
+				TaxAuthorityID:   j.TaxAuthorityID,
+				TaxAuthorityName: j.TaxAuthorityName,
+				TaxOnTax:         "0",
+				TaxRate:          rateFloat,
+				TaxTypeCode:      j.TaxTypeCode,
+				TaxTypeDesc:      j.TaxTypeDesc,
+			})
+		}
+		res.GroupList = append(res.GroupList, g)
+	}
+	res.TotalTax = suretax.Rounding{Places: 2}.Format(total)
+
+	return res
+}
+
+// Returns the lines of the response, the items of the request given with WithRequest if any.
+func (f *fixture) items() []suretax.RequestItem {
+	if f.req != nil {
+		return f.req.ItemList
+	}
+
+	invoices := f.invoices
+	if invoices <= 0 {
+		invoices = 1
+	}
+	items := make([]suretax.RequestItem, f.lines)
+	for i := range items {
+		items[i] = suretax.RequestItem{
+			LineNumber:     fmt.Sprint(i + 1),
+			InvoiceNumber:  fmt.Sprintf("INV%d", i%invoices+1),
+			CustomerNumber: "000000001",
+			Revenue:        f.revenue,
+		}
+	}
+	return items
+}
+
+// Returns the HTTP body SureTax answers with: v, e.g. a Response or CancelResponse, JSON encoded
+// and the JSON wrapped as a string under "d". Panics if v can't be encoded.
+func Encode(v interface{}) []byte {
+	inner, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	body, err := json.Marshal(suretax.ResponseWrapper{D: string(inner)})
+	if err != nil {
+		panic(err)
+	}
+	return body
+}
@@ -0,0 +1,74 @@
+package suretaxtest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+func Test_NewResponse(t *testing.T) {
+
+	res := NewResponse()
+	if len(res.GroupList) != 1 || len(res.GroupList[0].TaxList) != 3 || res.TotalTax != "9.25" {
+		t.Fatalf("Expected one line taxed by 3 jurisdictions but got %+v", res)
+	}
+	if err := res.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	res = NewResponse(WithLines(5), WithInvoices(2), WithRevenue("10.01"),
+		WithJurisdictions(Jurisdiction{StateCode: "TX", TaxAuthorityName: "STATE", TaxTypeCode: "035", Rate: "0.0625"}))
+	if len(res.GroupList) != 5 || res.GroupList[4].LineNumber != "5" || res.GroupList[1].InvoiceNumber != "INV2" {
+		t.Fatalf("Expected 5 lines over 2 invoices but got %+v", res.GroupList)
+	}
+	if res.GroupList[0].TaxList[0].TaxAmount != "0.62563" || res.TotalTax != "3.13" {
+		t.Fatalf("Expected 6.25%% of 10.01 per line but got %+v", res)
+	}
+	summary, err := res.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.ByInvoice) != 2 || summary.ByState["TX"] != "3.12815" {
+		t.Fatalf("Expected totals by invoice and state but got %+v", summary)
+	}
+
+	res = NewResponse(WithLines(3), WithItemMessages(suretax.ItemMessage{LineNumber: "2", ResponseCode: "9151", Message: "Invalid Zip Code"}))
+	if res.ResponseCode != suretax.ResponseCodeSuccessWithItemErrors || len(res.GroupList) != 2 || res.GroupList[1].LineNumber != "3" {
+		t.Fatalf("Expected line 2 left untaxed but got %+v", res)
+	}
+
+	req := testRequest()
+	req.STAN = "stan"
+	res = NewResponse(WithRequest(req), WithTransId(7))
+	req2 := req.ItemList[1]
+	if res.ClientTracking != "INV-1" || res.STAN != "stan" || res.TransId != 7 || len(res.GroupList) != 2 ||
+		res.GroupList[0].StateCode != "IL" || res.GroupList[1].TaxList[0].Revenue != req2.Revenue {
+		t.Fatalf("Expected the response to answer the request but got %+v", res)
+	}
+}
+
+func Test_Encode(t *testing.T) {
+
+	res := NewResponse(WithLines(2))
+
+	var wrapper suretax.ResponseWrapper
+	if err := json.Unmarshal(Encode(res), &wrapper); err != nil {
+		t.Fatal(err)
+	}
+	decoded := &suretax.Response{}
+	if err := json.Unmarshal([]byte(wrapper.D), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.TotalTax != res.TotalTax || len(decoded.GroupList) != 2 {
+		t.Fatalf("Expected the response double-encoded under d but got %s", Encode(res))
+	}
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.Respond(Canned(NewResponse(WithRequest(testRequest()))))
+	got, err := srv.Client().Send(testRequest())
+	if err != nil || got.TotalTax != "13.88" {
+		t.Fatalf("Expected the generated response but got %+v, %v", got, err)
+	}
+}
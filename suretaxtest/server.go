@@ -0,0 +1,354 @@
+// Package suretaxtest provides a fake SureTax server for tests of code calling SureTax.
+//
+//	srv := suretaxtest.NewServer()
+//	defer srv.Close()
+//	srv.Respond(suretaxtest.Success("0.07"))
+//
+//	client := srv.Client()
+//	res, err := client.Send(req)
+//
+// The server speaks the SureTax wire format: requests wrapped under "request" or "requestCancel"
+// and responses double-encoded under "d". Every request received is recorded, see Server.Requests.
+// NewResponse builds responses for unit tests without a server, see Encode for their wire format.
+package suretaxtest
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+// Paths of the post and cancel endpoints, the ones of the SureTax communications API.
+const (
+	PostPath   = "/Services/Communications/V01/SureTax.asmx/PostRequest"
+	CancelPath = "/Services/Communications/V01/SureTax.asmx/CancelPostRequest"
+)
+
+// Computes the response to a request. transId is the transaction ID assigned by the server.
+type Responder func(req *suretax.Request, transId int) *suretax.Response
+
+// Computes the response to a cancel request.
+type CancelResponder func(req *suretax.CancelRequest) *suretax.CancelResponse
+
+type rule struct {
+	match   func(*suretax.Request) bool
+	respond Responder
+}
+
+// Fake SureTax server backed by an httptest.Server. Safe for concurrent use.
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	rules    []rule
+	respond  Responder
+	cancel   CancelResponder
+	key      string
+	latency  time.Duration
+	failures []int
+	transId  int
+	requests []*suretax.Request
+	cancels  []*suretax.CancelRequest
+}
+
+// Starts a server answering every request with Success("0") and every cancel request with CancelSuccess.
+func NewServer() *Server {
+	s := &Server{respond: Success("0"), cancel: CancelSuccess, transId: 100000000}
+	mux := http.NewServeMux()
+	mux.HandleFunc(PostPath, s.handlePost)
+	mux.HandleFunc(CancelPath, s.handleCancel)
+	s.srv = httptest.NewServer(mux)
+	return s
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+func (s *Server) Url() string {
+	return s.srv.URL + PostPath
+}
+
+func (s *Server) CancelUrl() string {
+	return s.srv.URL + CancelPath
+}
+
+// Returns a client posting to the server, configured by opts.
+func (s *Server) Client(opts ...suretax.Option) *suretax.SuretaxClient {
+	return suretax.NewClient(s.Url(), s.CancelUrl(), opts...)
+}
+
+// Sets the responder used for requests matching no rule.
+func (s *Server) Respond(r Responder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.respond = r
+}
+
+// Answers requests for which match returns true with r. Rules are checked in the order they were added.
+func (s *Server) RespondWhen(match func(*suretax.Request) bool, r Responder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule{match, r})
+}
+
+func (s *Server) RespondCancel(r CancelResponder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancel = r
+}
+
+// Makes the server answer requests with another ValidationKey with 1151 "Invalid Validation Key".
+// An empty key accepts any request.
+func (s *Server) RequireValidationKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+}
+
+// Delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Makes the server answer the next n requests with the HTTP status, e.g. 503, before processing them.
+func (s *Server) FailNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// Returns the requests received so far, in order, including the ones failed with FailNext.
+func (s *Server) Requests() []*suretax.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*suretax.Request(nil), s.requests...)
+}
+
+// Returns the cancel requests received so far, in order.
+func (s *Server) CancelRequests() []*suretax.CancelRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*suretax.CancelRequest(nil), s.cancels...)
+}
+
+// Decodes the request wrapped under key in the body of r into v.
+func decodeWrapped(r *http.Request, key string, v interface{}) bool {
+	var wrapper map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&wrapper); err != nil {
+		return false
+	}
+	inner, ok := wrapper[key]
+	return ok && json.Unmarshal([]byte(inner), v) == nil
+}
+
+// Writes v double-encoded under "d".
+func writeWrapped(w http.ResponseWriter, v interface{}) {
+	inner, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(suretax.ResponseWrapper{D: string(inner)})
+}
+
+// Sleeps for the configured latency and returns the status of a queued failure, 0 if there is none.
+func (s *Server) delay(r *http.Request) int {
+	s.mu.Lock()
+	latency := s.latency
+	status := 0
+	if len(s.failures) > 0 {
+		status, s.failures = s.failures[0], s.failures[1:]
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+		}
+	}
+	return status
+}
+
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	req := &suretax.Request{}
+	if !decodeWrapped(r, "request", req) {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	if status := s.delay(r); status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	s.mu.Lock()
+	s.transId++
+	transId := s.transId
+	respond := s.respond
+	for _, rl := range s.rules {
+		if rl.match(req) {
+			respond = rl.respond
+			break
+		}
+	}
+	if s.key != "" && req.ValidationKey != s.key {
+		respond = AuthFailure()
+	}
+	s.mu.Unlock()
+
+	writeWrapped(w, respond(req, transId))
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	req := &suretax.CancelRequest{}
+	if !decodeWrapped(r, "requestCancel", req) {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.cancels = append(s.cancels, req)
+	s.mu.Unlock()
+
+	if status := s.delay(r); status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	s.mu.Lock()
+	cancel := s.cancel
+	if s.key != "" && req.ValidationKey != s.key {
+		cancel = func(req *suretax.CancelRequest) *suretax.CancelResponse {
+			return &suretax.CancelResponse{Successful: "N", ResponseCode: suretax.ResponseCodeInvalidValidationKey,
+				HeaderMessage: "Failure - Invalid Validation Key", ClientTracking: req.ClientTracking}
+		}
+	}
+	s.mu.Unlock()
+
+	writeWrapped(w, cancel(req))
+}
+
+// Returns a responder taxing every item with a single state sales tax of rate (e.g. "0.0625") on its Revenue.
+func Success(rate string) Responder {
+	return func(req *suretax.Request, transId int) *suretax.Response {
+		return taxed(req, transId, rate, nil)
+	}
+}
+
+// Returns a responder answering 9001 "Success with Item errors" with the given messages,
+// taxing the other items like Success(rate).
+func ItemErrors(rate string, messages ...suretax.ItemMessage) Responder {
+	return func(req *suretax.Request, transId int) *suretax.Response {
+		return taxed(req, transId, rate, messages)
+	}
+}
+
+// Returns a responder declining every request with 1151 "Invalid Validation Key".
+func AuthFailure() Responder {
+	return Declined(suretax.ResponseCodeInvalidValidationKey, "Failure - Invalid Validation Key")
+}
+
+// Returns a responder declining every request with the given response code and header message.
+func Declined(code, message string) Responder {
+	return func(req *suretax.Request, transId int) *suretax.Response {
+		return &suretax.Response{Successful: "N", ResponseCode: code, HeaderMessage: message,
+			ClientTracking: req.ClientTracking, STAN: req.STAN, TotalTax: "0", GroupList: []suretax.Group{}}
+	}
+}
+
+// Returns a responder answering every request with a copy of res, its TransId replaced by the server's.
+func Canned(res *suretax.Response) Responder {
+	return func(req *suretax.Request, transId int) *suretax.Response {
+		c := *res
+		c.TransId = transId
+		return &c
+	}
+}
+
+// Answers with 9999 "Cancel Request was successful".
+func CancelSuccess(req *suretax.CancelRequest) *suretax.CancelResponse {
+	transId, _ := strconv.Atoi(strings.TrimSpace(req.TransId))
+	return &suretax.CancelResponse{Successful: "Y", ResponseCode: suretax.ResponseCodeSuccess,
+		HeaderMessage: "Cancel Request was successful", ClientTracking: req.ClientTracking, TransId: transId}
+}
+
+func taxed(req *suretax.Request, transId int, rate string, messages []suretax.ItemMessage) *suretax.Response {
+	failed := map[string]bool{}
+	for _, m := range messages {
+		failed[strings.TrimLeft(m.LineNumber, "0")] = true
+	}
+
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok {
+		r = new(big.Rat)
+	}
+
+	res := &suretax.Response{
+		Successful:     "Y",
+		ResponseCode:   suretax.ResponseCodeSuccess,
+		HeaderMessage:  "Success",
+		ClientTracking: req.ClientTracking,
+		STAN:           req.STAN,
+		TransId:        transId,
+		MasterTransId:  transId,
+		GroupList:      []suretax.Group{},
+		ItemMessages:   messages,
+	}
+	if len(messages) > 0 {
+		res.ResponseCode, res.HeaderMessage = suretax.ResponseCodeSuccessWithItemErrors, "Success with Item errors"
+	}
+
+	total := new(big.Rat)
+	for _, item := range req.ItemList {
+		if failed[strings.TrimLeft(item.LineNumber, "0")] {
+			continue
+		}
+		revenue, ok := new(big.Rat).SetString(item.Revenue)
+		if !ok {
+			revenue = new(big.Rat)
+		}
+		amount := new(big.Rat).Mul(revenue, r)
+		total.Add(total, amount)
+
+		rateFloat, _ := r.Float64()
+		res.GroupList = append(res.GroupList, suretax.Group{
+			CustomerNumber: item.CustomerNumber,
+			InvoiceNumber:  item.InvoiceNumber,
+			LineNumber:     item.LineNumber,
+			StateCode:      item.Address.State,
+			TaxList: []suretax.Tax{{
+				Revenue:          item.Revenue,
+				RevenueBase:      item.Revenue,
+				TaxAmount:        amount.FloatString(5),
+				TaxAuthorityName: "STATE",
+				TaxRate:          rateFloat,
+				PercentTaxable:   1,
+				TaxTypeCode:      "035",
+				TaxTypeDesc:      "STATE SALES TAX",
+			}},
+		})
+	}
+	res.TotalTax = total.FloatString(2)
+
+	return res
+}
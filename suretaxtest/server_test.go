@@ -0,0 +1,99 @@
+package suretaxtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+func testRequest() *suretax.Request {
+	return &suretax.Request{
+		ClientNumber:   "000000001",
+		ValidationKey:  "key",
+		DataYear:       "2026",
+		DataMonth:      "10",
+		ReturnFileCode: "0",
+		ClientTracking: "INV-1",
+		ItemList: []suretax.RequestItem{
+			{LineNumber: "1", InvoiceNumber: "INV1", Revenue: "100", TransTypeCode: "010101", Address: suretax.Address{State: "IL"}},
+			{LineNumber: "2", InvoiceNumber: "INV1", Revenue: "50", TransTypeCode: "010101"},
+		},
+	}
+}
+
+func Test_Server(t *testing.T) {
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.Respond(Success("0.0625"))
+
+	client := srv.Client()
+	res, err := client.Send(testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalTax != "9.38" || len(res.GroupList) != 2 || res.GroupList[0].TaxList[0].TaxAmount != "6.25000" || res.TransId == 0 {
+		t.Fatalf("Expected both items taxed at 6.25%% but got %+v", res)
+	}
+	if err := res.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.RespondWhen(func(req *suretax.Request) bool { return req.ClientTracking == "bad" },
+		ItemErrors("0.0625", suretax.ItemMessage{LineNumber: "2", ResponseCode: "9151", Message: "Invalid Zip Code"}))
+	req := testRequest()
+	req.ClientTracking = "bad"
+	res, err = client.Send(req)
+	if err != nil || res.ResponseCode != "9001" || len(res.GroupList) != 1 || len(res.ItemMessages) != 1 {
+		t.Fatalf("Expected an item error for line 2 but got %+v, %v", res, err)
+	}
+
+	srv.RequireValidationKey("other")
+	if _, err := client.Send(testRequest()); !suretax.IsAuthError(err) {
+		t.Fatalf("Expected an auth error but got %v", err)
+	}
+	if _, err := client.Cancel(&suretax.CancelRequest{TransId: "7"}); !suretax.IsAuthError(err) {
+		t.Fatalf("Expected the cancel to be refused but got %v", err)
+	}
+	srv.RequireValidationKey("")
+
+	cres, err := client.Cancel(&suretax.CancelRequest{TransId: "7"})
+	if err != nil || cres.TransId != 7 {
+		t.Fatalf("Expected transaction 7 to be cancelled but got %+v, %v", cres, err)
+	}
+
+	if got := len(srv.Requests()); got != 3 {
+		t.Fatalf("Expected 3 recorded requests but got %v", got)
+	}
+	if got := srv.CancelRequests(); len(got) != 2 || got[1].TransId != "7" {
+		t.Fatalf("Expected 2 recorded cancel requests but got %v", got)
+	}
+}
+
+func Test_Server_Failures(t *testing.T) {
+
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.FailNext(1, 503)
+	client := srv.Client()
+	if _, err := client.Send(testRequest()); !suretax.IsTransient(err) {
+		t.Fatalf("Expected a transient error but got %v", err)
+	}
+	if _, err := client.Send(testRequest()); err != nil {
+		t.Fatalf("Expected only the first request to fail but got %v", err)
+	}
+
+	srv.SetLatency(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := client.Send(testRequest()); err != nil || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("Expected a delayed response but got %v after %v", err, time.Since(start))
+	}
+
+	srv.SetLatency(0)
+	srv.Respond(Canned(&suretax.Response{Successful: "Y", ResponseCode: "9999", TotalTax: "1.00"}))
+	if res, err := client.Send(testRequest()); err != nil || res.TotalTax != "1.00" {
+		t.Fatalf("Expected the canned response but got %+v, %v", res, err)
+	}
+}
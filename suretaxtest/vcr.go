@@ -0,0 +1,198 @@
+package suretaxtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+// Whether a Recorder forwards requests or serves recorded responses.
+type RecorderMode int
+
+const (
+	// Requests are sent to SureTax and the exchanges recorded, see Recorder.Save.
+	ModeRecord RecorderMode = iota
+
+	// Requests are answered from the cassette file without network access.
+	ModeReplay
+)
+
+// One recorded HTTP exchange. Credentials are redacted with suretax.ScrubPayload and suretax.ScrubHeaders.
+type Interaction struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+
+	Response struct {
+		Status int         `json:"status"`
+		Header http.Header `json:"header,omitempty"`
+		Body   string      `json:"body"`
+	} `json:"response"`
+}
+
+// Exchanges recorded to one fixture file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// suretax.HttpClient recording the exchanges of a SuretaxClient with SureTax to a cassette file
+// and replaying them, so tests can run the full Send and Cancel path in CI without credentials.
+// Exchanges are replayed in the order they were recorded. Safe for concurrent use,
+// though concurrent calls are replayed in the order they arrive.
+//
+//	mode := suretaxtest.ModeReplay
+//	if os.Getenv("SURETAX_RECORD") != "" {
+//		mode = suretaxtest.ModeRecord
+//	}
+//	rec, err := suretaxtest.NewRecorder("testdata/send.json", mode, nil)
+//	client := suretax.NewClient(url, cancelUrl, suretax.WithHttpClient(rec))
+//	...
+//	rec.Save()
+type Recorder struct {
+	path string
+	mode RecorderMode
+	next suretax.HttpClient
+
+	// When set, replayed requests must have the recorded body, credentials redacted.
+	// Leave it off for requests with generated values, e.g. a STAN.
+	MatchBody bool
+
+	mu       sync.Mutex
+	cassette Cassette
+	pos      int
+}
+
+// Returns a recorder for the cassette at path. In ModeReplay the cassette is loaded from path;
+// in ModeRecord requests are sent with next, http.DefaultClient if nil.
+func NewRecorder(path string, mode RecorderMode, next suretax.HttpClient) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultClient
+	}
+	r := &Recorder{path: path, mode: mode, next: next}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("Invalid cassette %s: %w", path, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &Interaction{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Header: suretax.ScrubHeaders(req.Header),
+		Body:   string(suretax.ScrubPayload(body)),
+	}
+	in.Response.Status = resp.StatusCode
+	in.Response.Header = suretax.ScrubHeaders(resp.Header)
+	in.Response.Body = string(suretax.ScrubPayload(respBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pos >= len(r.cassette.Interactions) {
+		return nil, fmt.Errorf("Cassette %s has no interaction left for %s %s", r.path, req.Method, req.URL.Path)
+	}
+	in := r.cassette.Interactions[r.pos]
+
+	if in.Method != req.Method || in.Path != req.URL.Path {
+		return nil, fmt.Errorf("Cassette %s interaction %d is %s %s, got %s %s", r.path, r.pos+1, in.Method, in.Path, req.Method, req.URL.Path)
+	}
+	if r.MatchBody && in.Body != string(suretax.ScrubPayload(body)) {
+		return nil, fmt.Errorf("Cassette %s interaction %d was recorded with another request body", r.path, r.pos+1)
+	}
+	r.pos++
+
+	header := in.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode:    in.Response.Status,
+		Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(in.Response.Body))),
+		ContentLength: int64(len(in.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Returns the number of recorded interactions not replayed yet.
+func (r *Recorder) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.cassette.Interactions) - r.pos
+}
+
+// Writes the recorded interactions to the cassette file. Does nothing in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(&r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,111 @@
+package suretaxtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebteterin/go-suretax"
+)
+
+func Test_Recorder(t *testing.T) {
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.Respond(Success("0.0625"))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := NewRecorder(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := srv.Client(suretax.WithHttpClient(rec))
+	recorded, err := client.Send(testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Cancel(&suretax.CancelRequest{ClientNumber: "000000001", ValidationKey: "key", TransId: "100000001"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "000000001") || !strings.Contains(string(data), suretax.Redacted) {
+		t.Fatalf("Expected the credentials redacted but got %s", data)
+	}
+
+	// Replay against a closed server, so nothing can reach the network.
+	srv.Close()
+	rep, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.MatchBody = true
+	client = suretax.NewClient(srv.Url(), srv.CancelUrl(), suretax.WithHttpClient(rep))
+	res, err := client.Send(testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TransId != recorded.TransId || res.TotalTax != recorded.TotalTax {
+		t.Fatalf("Expected the recorded response %+v but got %+v", recorded, res)
+	}
+
+	// The cancel is recorded next, so another send is out of order.
+	if _, err := client.Send(testRequest()); err == nil {
+		t.Fatalf("Expected an out of order request to fail")
+	}
+	if _, err := client.Cancel(&suretax.CancelRequest{ClientNumber: "000000001", ValidationKey: "key", TransId: "100000001"}); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Remaining() != 0 {
+		t.Fatalf("Expected every interaction replayed but got %d left", rep.Remaining())
+	}
+	if _, err := client.Cancel(&suretax.CancelRequest{TransId: "100000001"}); err == nil {
+		t.Fatalf("Expected an exhausted cassette to fail")
+	}
+}
+
+func Test_Recorder_MatchBody(t *testing.T) {
+
+	srv := NewServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, _ := NewRecorder(path, ModeRecord, nil)
+	if _, err := srv.Client(suretax.WithHttpClient(rec)).Send(testRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.MatchBody = true
+	req := testRequest()
+	req.ValidationKey = "another key"
+	client := suretax.NewClient(srv.Url(), srv.CancelUrl(), suretax.WithHttpClient(rep))
+	if _, err := client.Send(req); err != nil {
+		t.Fatalf("Expected other credentials to match the redacted body but got %v", err)
+	}
+
+	rep, _ = NewRecorder(path, ModeReplay, nil)
+	rep.MatchBody = true
+	req.ItemList[0].Revenue = "200"
+	client = suretax.NewClient(srv.Url(), srv.CancelUrl(), suretax.WithHttpClient(rep))
+	if _, err := client.Send(req); err == nil || !strings.Contains(err.Error(), "another request body") {
+		t.Fatalf("Expected a body mismatch but got %v", err)
+	}
+
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil); err == nil {
+		t.Fatalf("Expected a missing cassette to fail")
+	}
+}
@@ -0,0 +1,280 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tax type of a rate profile with its average rate on item revenue, or its average amount for unit based fees.
+type profileTax struct {
+	Tax
+
+	// number of items the tax was seen on
+	seen int
+
+	// sum of TaxAmount/Revenue, or of TaxAmount for fees
+	sum float64
+}
+
+type rateProfile struct {
+	items int
+	taxes map[string]*profileTax
+}
+
+// Learns rate profiles per state and trans type from archived requests and responses
+// and synthesizes realistic responses from them, e.g. for load tests or staging environments
+// that shouldn't consume billable transactions. Safe for concurrent use.
+type Synthesizer struct {
+	mu       sync.Mutex
+	profiles map[string]*rateProfile
+	transId  int
+}
+
+func NewSynthesizer() *Synthesizer {
+	return &Synthesizer{profiles: make(map[string]*rateProfile), transId: 100000000}
+}
+
+func profileKey(state, transType string) string {
+	return state + "|" + transType
+}
+
+// Learns the taxes of every item of req calculated without error in res.
+func (s *Synthesizer) Learn(req *Request, res *Response) error {
+	groups, err := res.Groups()
+	if err != nil {
+		return err
+	}
+
+	failed := map[string]bool{}
+	for _, m := range res.ItemMessages {
+		failed[lineKey(m.LineNumber)] = true
+	}
+	byLine := map[string][]Group{}
+	for _, g := range groups {
+		byLine[lineKey(g.LineNumber)] = append(byLine[lineKey(g.LineNumber)], g)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range req.ItemList {
+		item := &req.ItemList[i]
+		l := lineKey(item.LineNumber)
+		if failed[l] || len(byLine[l]) == 0 {
+			continue
+		}
+		state := itemState(item)
+		if state == "" {
+			state = byLine[l][0].StateCode
+		}
+		if err := s.learn(profileKey(state, item.TransTypeCode), item.Revenue, byLine[l]); err != nil {
+			return err
+		}
+		if err := s.learn(profileKey(state, ""), item.Revenue, byLine[l]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Learns from a response without its request. Trans types being unknown, the taxes only
+// serve as the profile of their state, used for items of trans types not learned.
+func (s *Synthesizer) LearnResponse(res *Response) error {
+	groups, err := res.Groups()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, g := range groups {
+		if len(g.TaxList) == 0 {
+			continue
+		}
+		if err := s.learn(profileKey(g.StateCode, ""), g.TaxList[0].Revenue, []Group{g}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Adds the taxes of one item to the profile for key. Called with mu held.
+func (s *Synthesizer) learn(key, revenue string, groups []Group) error {
+	rev, err := parseAmount(revenue)
+	if err != nil {
+		return err
+	}
+	r, _ := rev.Float64()
+
+	p, ok := s.profiles[key]
+	if !ok {
+		p = &rateProfile{taxes: make(map[string]*profileTax)}
+		s.profiles[key] = p
+	}
+	p.items++
+
+	for _, g := range groups {
+		for _, t := range g.TaxList {
+			amt, err := parseAmount(t.TaxAmount)
+			if err != nil {
+				return err
+			}
+			a, _ := amt.Float64()
+
+			pt, ok := p.taxes[t.TaxTypeCode+"|"+t.TaxAuthorityID]
+			if !ok {
+				pt = &profileTax{Tax: Tax{
+					TaxTypeCode:      t.TaxTypeCode,
+					TaxTypeDesc:      t.TaxTypeDesc,
+					TaxAuthorityID:   t.TaxAuthorityID,
+					TaxAuthorityName: t.TaxAuthorityName,
+					FeeRate:          t.FeeRate,
+					PercentTaxable:   t.PercentTaxable,
+				}}
+				p.taxes[t.TaxTypeCode+"|"+t.TaxAuthorityID] = pt
+			}
+			pt.seen++
+			if t.FeeRate != 0 {
+				pt.sum += a
+			} else if r != 0 {
+				pt.sum += a / r
+			}
+		}
+	}
+	return nil
+}
+
+// Returns a successful response with the learned taxes of every item, applied to its revenue.
+// Items of a state and trans type not learned use the profile of their state, and get no taxes without one.
+func (s *Synthesizer) Synthesize(req *Request) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transId++
+	res := &Response{
+		ResponseCode:   "9999",
+		HeaderMessage:  "Success",
+		Successful:     "Y",
+		TransId:        s.transId,
+		ClientTracking: req.ClientTracking,
+		STAN:           req.STAN,
+	}
+
+	total := new(big.Rat)
+	for i := range req.ItemList {
+		item := &req.ItemList[i]
+		rev, err := parseAmount(item.Revenue)
+		if err != nil {
+			return nil, err
+		}
+
+		state := itemState(item)
+		p, ok := s.profiles[profileKey(state, item.TransTypeCode)]
+		if !ok {
+			p = s.profiles[profileKey(state, "")]
+		}
+
+		g := Group{LineNumber: item.LineNumber, InvoiceNumber: item.InvoiceNumber, CustomerNumber: item.CustomerNumber, StateCode: state, TaxList: []Tax{}}
+		if p != nil {
+			for _, pt := range p.sortedTaxes() {
+				t := pt.Tax
+				t.Revenue, t.RevenueBase = item.Revenue, item.Revenue
+				amt := new(big.Rat)
+				if pt.FeeRate != 0 {
+					amt.SetFloat64(pt.sum / float64(pt.seen))
+				} else {
+					t.TaxRate = pt.sum / float64(pt.seen)
+					amt.Mul(rev, new(big.Rat).SetFloat64(t.TaxRate))
+				}
+				t.TaxAmount = formatAmount(amt, 2)
+				amt, _ = parseAmount(t.TaxAmount)
+				total.Add(total, amt)
+				g.TaxList = append(g.TaxList, t)
+			}
+		}
+		res.GroupList = append(res.GroupList, g)
+	}
+	res.TotalTax = formatAmount(total, 2)
+
+	return res, nil
+}
+
+// Taxes seen on at least half the items of the profile, ordered by tax type.
+func (p *rateProfile) sortedTaxes() []*profileTax {
+	var taxes []*profileTax
+	for _, t := range p.taxes {
+		if 2*t.seen >= p.items {
+			taxes = append(taxes, t)
+		}
+	}
+	sort.Slice(taxes, func(i, j int) bool {
+		if taxes[i].TaxTypeCode != taxes[j].TaxTypeCode {
+			return taxes[i].TaxTypeCode < taxes[j].TaxTypeCode
+		}
+		return taxes[i].TaxAuthorityID < taxes[j].TaxAuthorityID
+	})
+	return taxes
+}
+
+// Returns an HttpClient answering SureTax post requests with synthesized responses after latency,
+// for use with SetHttpClient in load tests.
+func (s *Synthesizer) HttpClient(latency time.Duration) HttpClient {
+	return &syntheticHttpClient{s, latency}
+}
+
+type syntheticHttpClient struct {
+	s       *Synthesizer
+	latency time.Duration
+}
+
+func (c *syntheticHttpClient) Do(r *http.Request) (*http.Response, error) {
+	if c.latency > 0 {
+		t := time.NewTimer(c.latency)
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			t.Stop()
+			return nil, r.Context().Err()
+		}
+	}
+
+	reply := func(status int, body []byte) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			Request:    r,
+		}
+	}
+
+	rw := requestWrapper{}
+	if err := json.NewDecoder(r.Body).Decode(&rw); err != nil {
+		return reply(http.StatusBadRequest, nil), nil
+	}
+	req := &Request{}
+	if err := json.Unmarshal([]byte(rw.Request), req); err != nil {
+		return reply(http.StatusBadRequest, nil), nil
+	}
+
+	res, err := c.s.Synthesize(req)
+	if err != nil {
+		return reply(http.StatusBadRequest, nil), nil
+	}
+	inner, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(ResponseWrapper{D: string(inner)})
+	if err != nil {
+		return nil, err
+	}
+	return reply(http.StatusOK, body), nil
+}
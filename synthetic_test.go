@@ -0,0 +1,69 @@
+package suretax
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Synthesizer(t *testing.T) {
+
+	s := NewSynthesizer()
+
+	req := getTestRequest()
+	req.ItemList[0].Address = Address{State: "FL", PostalCode: "32034"}
+	res := &Response{ResponseCode: "9999", GroupList: []Group{{LineNumber: "1", StateCode: "FL", TaxList: []Tax{
+		{TaxTypeCode: "106", TaxTypeDesc: "FL CST", TaxAmount: "10.00", Revenue: "100"},
+		{TaxTypeCode: "060", TaxAmount: "0.40", FeeRate: 0.4},
+	}}}}
+	if err := s.Learn(req, res); err != nil {
+		t.Fatal(err)
+	}
+	req.ItemList[0].Revenue = "200"
+	res.GroupList[0].TaxList[0].TaxAmount = "24.00"
+	if err := s.Learn(req, res); err != nil {
+		t.Fatal(err)
+	}
+
+	quote := getTestRequest()
+	quote.ItemList[0].Revenue = "50"
+	quote.ItemList[0].Address = Address{State: "FL"}
+	syn, err := s.Synthesize(quote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taxes := syn.GroupList[0].TaxList
+	if len(taxes) != 2 || taxes[0].TaxTypeCode != "060" || taxes[0].TaxAmount != "0.40" || taxes[1].TaxAmount != "5.50" {
+		t.Fatalf("Expected the learned fee and an 11%% tax but got %+v", taxes)
+	}
+	if syn.TotalTax != "5.90" || syn.TransId == 0 {
+		t.Fatalf("Expected TotalTax 5.90 but got %v", syn.TotalTax)
+	}
+
+	quote.ItemList[0].TransTypeCode = "990101"
+	if syn, _ := s.Synthesize(quote); len(syn.GroupList[0].TaxList) != 2 {
+		t.Fatal("Expected the state profile for an unknown trans type")
+	}
+	quote.ItemList[0].Address.State = "GA"
+	if syn, _ := s.Synthesize(quote); len(syn.GroupList[0].TaxList) != 0 || syn.TotalTax != "0.00" {
+		t.Fatal("Expected no taxes without a profile")
+	}
+}
+
+func Test_Synthesizer_HttpClient(t *testing.T) {
+
+	s := NewSynthesizer()
+	s.LearnResponse(&Response{GroupList: []Group{{StateCode: "FL", TaxList: []Tax{{TaxTypeCode: "106", TaxAmount: "10.00", Revenue: "100"}}}}})
+
+	SetHttpClient(s.HttpClient(time.Millisecond))
+	defer SetHttpClient(nil)
+
+	req := getTestRequest()
+	req.ItemList[0].Address = Address{State: "FL"}
+	res, err := (&SuretaxClient{}).Send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalTax != "10.00" || len(res.GroupList) != 1 {
+		t.Fatalf("Expected the synthesized response but got %+v", res)
+	}
+}
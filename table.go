@@ -0,0 +1,179 @@
+package suretax
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Layout of WriteTable.
+type TableStyle int
+
+const (
+	// Columns aligned with spaces, for terminals and logs.
+	TableText TableStyle = iota
+
+	// A GitHub flavored markdown table, for tickets and error reports.
+	TableMarkdown
+)
+
+var tableHeader = []string{"Line", "Jurisdiction", "Tax type", "Rate", "Base", "Amount", "Tax on tax"}
+
+// Columns aligned to the right.
+var tableNumeric = []bool{false, false, false, true, true, true, true}
+
+// Writes res as a table with one row per tax: line, jurisdiction, tax type, rate, base, amount and tax on tax,
+// followed by the total of every line, the grand total and the item errors.
+func WriteTable(w io.Writer, res *Response, style TableStyle) error {
+	groups, err := res.Groups()
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	var lineTotals []string
+	for _, g := range groups {
+		amounts := make([]string, 0, len(g.TaxList))
+		for _, t := range g.TaxList {
+			rows = append(rows, []string{
+				g.LineNumber,
+				tableJurisdiction(g.StateCode, t),
+				strings.TrimSpace(t.TaxTypeCode + " " + t.TaxTypeDesc),
+				tableRate(t),
+				t.RevenueBase,
+				t.TaxAmount,
+				t.TaxOnTax,
+			})
+			amounts = append(amounts, t.TaxAmount)
+		}
+		total, err := sumAmounts(amounts)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, []string{g.LineNumber, "", "Line total", "", "", total, ""})
+		lineTotals = append(lineTotals, total)
+	}
+
+	total, err := sumAmounts(lineTotals)
+	if err != nil {
+		return err
+	}
+	if res.TotalTax != "" {
+		total = res.TotalTax
+	}
+	rows = append(rows, []string{"", "", "Total", "", "", total, ""})
+
+	if style == TableMarkdown {
+		err = writeMarkdownTable(w, rows)
+	} else {
+		err = writeTextTable(w, rows)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, m := range res.ItemMessages {
+		if _, err := fmt.Fprintf(w, "\nLine %s: %s (%s)", m.LineNumber, m.Message, m.ResponseCode); err != nil {
+			return err
+		}
+	}
+	if len(res.ItemMessages) > 0 {
+		_, err = io.WriteString(w, "\n")
+	}
+	return err
+}
+
+func tableJurisdiction(state string, t Tax) string {
+	parts := []string{}
+	for _, p := range []string{state, t.CountyName, t.CityName} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// Returns the percentage rate, or the fee per unit for fixed fees.
+func tableRate(t Tax) string {
+	if t.FeeRate != 0 {
+		return strconv.FormatFloat(t.FeeRate, 'f', -1, 64) + " fee"
+	}
+	return strconv.FormatFloat(t.TaxRate*100, 'f', -1, 64) + "%"
+}
+
+func tableWidths(rows [][]string) []int {
+	widths := make([]int, len(tableHeader))
+	for _, row := range append([][]string{tableHeader}, rows...) {
+		for i, v := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(v))
+		}
+	}
+	return widths
+}
+
+func pad(v string, width int, right bool) string {
+	fill := strings.Repeat(" ", width-utf8.RuneCountInString(v))
+	if right {
+		return fill + v
+	}
+	return v + fill
+}
+
+func writeTextTable(w io.Writer, rows [][]string) error {
+	widths := tableWidths(rows)
+
+	var b strings.Builder
+	line := func(row []string) {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = pad(v, widths[i], tableNumeric[i])
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, "  "), " "))
+		b.WriteByte('\n')
+	}
+
+	line(tableHeader)
+	rule := make([]string, len(widths))
+	for i, n := range widths {
+		rule[i] = strings.Repeat("-", n)
+	}
+	line(rule)
+	for _, row := range rows {
+		line(row)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownTable(w io.Writer, rows [][]string) error {
+	widths := tableWidths(rows)
+
+	var b strings.Builder
+	line := func(row []string) {
+		b.WriteString("|")
+		for i, v := range row {
+			b.WriteString(" " + pad(strings.ReplaceAll(v, "|", `\|`), widths[i], tableNumeric[i]) + " |")
+		}
+		b.WriteByte('\n')
+	}
+
+	line(tableHeader)
+	b.WriteString("|")
+	for i, n := range widths {
+		if tableNumeric[i] {
+			b.WriteString(" " + strings.Repeat("-", n-1) + ": |")
+		} else {
+			b.WriteString(" " + strings.Repeat("-", n) + " |")
+		}
+	}
+	b.WriteByte('\n')
+	for _, row := range rows {
+		line(row)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
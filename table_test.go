@@ -0,0 +1,55 @@
+package suretax
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func getTableTestResponse() *Response {
+	return &Response{ResponseCode: "9001", TotalTax: "2.25",
+		GroupList: []Group{
+			{LineNumber: "1", StateCode: "IL", TaxList: []Tax{
+				{TaxTypeCode: "035", TaxTypeDesc: "STATE SALES TAX", CountyName: "COOK", CityName: "CHICAGO", TaxRate: 0.0625, RevenueBase: "20.00", TaxAmount: "1.25", TaxOnTax: "0"},
+				{TaxTypeCode: "106", TaxTypeDesc: "E911", FeeRate: 1, RevenueBase: "20.00", TaxAmount: "1.00", TaxOnTax: "0"},
+			}},
+		},
+		ItemMessages: []ItemMessage{{LineNumber: "2", ResponseCode: "9131", Message: "Invalid zip code"}},
+	}
+}
+
+func Test_WriteTable_Text(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	if err := WriteTable(buf, getTableTestResponse(), TableText); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "" +
+		"Line  Jurisdiction         Tax type              Rate   Base  Amount  Tax on tax\n" +
+		"----  -------------------  -------------------  -----  -----  ------  ----------\n" +
+		"1     IL / COOK / CHICAGO  035 STATE SALES TAX  6.25%  20.00    1.25           0\n" +
+		"1     IL                   106 E911             1 fee  20.00    1.00           0\n" +
+		"1                          Line total                           2.25\n" +
+		"                           Total                                2.25\n" +
+		"\nLine 2: Invalid zip code (9131)\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected\n%s\nbut got\n%s", expected, buf)
+	}
+}
+
+func Test_WriteTable_Markdown(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	if err := WriteTable(buf, getTableTestResponse(), TableMarkdown); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], "| Line | Jurisdiction") || !strings.Contains(lines[1], "-: |") {
+		t.Fatalf("Expected a markdown header with right aligned amounts but got\n%s", buf)
+	}
+	if !strings.Contains(buf.String(), "| Total ") {
+		t.Fatalf("Expected the grand total but got\n%s", buf)
+	}
+}
@@ -0,0 +1,146 @@
+package suretax
+
+//go:generate go run gen_taxtypes.go
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Broad category of a tax type, driving how it is presented on invoices.
+type TaxCategory int
+
+const (
+	TaxCategoryOther TaxCategory = iota
+
+	// Federal and state universal service funds
+	TaxCategoryUSF
+
+	// 911 and E911 fees
+	TaxCategoryE911
+
+	// State and local sales and use taxes
+	TaxCategorySales
+
+	// Gross receipts taxes
+	TaxCategoryGrossReceipts
+
+	// Communications services and excise taxes
+	TaxCategoryCommunications
+
+	// Regulatory fees and cost recovery charges
+	TaxCategoryRegulatory
+)
+
+var taxCategoryNames = [...]string{"Other", "USF", "E911", "Sales", "GrossReceipts", "Communications", "Regulatory"}
+
+func (c TaxCategory) String() string {
+	if int(c) < len(taxCategoryNames) {
+		return taxCategoryNames[c]
+	}
+	return "Other"
+}
+
+// Who bears a tax and whether it may be passed on to the customer.
+type TaxClass int
+
+const (
+	TaxClassUnknown TaxClass = iota
+
+	// Imposed on the customer and collected by the provider, shown as a tax.
+	TaxClassTax
+
+	// Imposed on the provider and recoverable from the customer as a surcharge.
+	TaxClassSurcharge
+)
+
+func (c TaxClass) String() string {
+	switch c {
+	case TaxClassTax:
+		return "Tax"
+	case TaxClassSurcharge:
+		return "Surcharge"
+	}
+	return "Unknown"
+}
+
+type taxTypeInfo struct {
+	Category TaxCategory
+	Class    TaxClass
+}
+
+// Keywords of TaxTypeDesc used for codes missing from taxTypeTable, checked in order.
+// taxTypeTable is generated from taxtypes.csv; add new codes there rather than keywords here.
+var taxTypeKeywords = []struct {
+	keyword string
+	info    taxTypeInfo
+}{
+	{"UNIVERSAL SERVICE", taxTypeInfo{TaxCategoryUSF, TaxClassSurcharge}},
+	{"USF", taxTypeInfo{TaxCategoryUSF, TaxClassSurcharge}},
+	{"911", taxTypeInfo{TaxCategoryE911, TaxClassTax}},
+	{"GROSS RECEIPTS", taxTypeInfo{TaxCategoryGrossReceipts, TaxClassSurcharge}},
+	{"COST RECOVERY", taxTypeInfo{TaxCategoryRegulatory, TaxClassSurcharge}},
+	{"REGULATORY", taxTypeInfo{TaxCategoryRegulatory, TaxClassSurcharge}},
+	{"SALES", taxTypeInfo{TaxCategorySales, TaxClassTax}},
+	{"USE TAX", taxTypeInfo{TaxCategorySales, TaxClassTax}},
+	{"COMMUNICATION", taxTypeInfo{TaxCategoryCommunications, TaxClassTax}},
+	{"EXCISE", taxTypeInfo{TaxCategoryCommunications, TaxClassTax}},
+}
+
+func (t *Tax) typeInfo() taxTypeInfo {
+	if info, ok := taxTypeTable[t.TaxTypeCode]; ok {
+		return info
+	}
+	desc := strings.ToUpper(t.TaxTypeDesc)
+	for _, k := range taxTypeKeywords {
+		if strings.Contains(desc, k.keyword) {
+			return k.info
+		}
+	}
+	return taxTypeInfo{}
+}
+
+// Returns the category of the tax, from its TaxTypeCode or, for codes not in the table, keywords
+// of its TaxTypeDesc such as "911" or "SALES". Unknown codes matching no keyword are TaxCategoryOther.
+func (t *Tax) Category() TaxCategory {
+	return t.typeInfo().Category
+}
+
+// Returns whether the tax is imposed on the customer or recoverable as a surcharge, told like Category.
+// Unknown codes matching no keyword are TaxClassUnknown.
+func (t *Tax) Class() TaxClass {
+	return t.typeInfo().Class
+}
+
+// Sums TaxAmount of all taxes of res per category. See also TaxSummary.ByCategory.
+func TaxByCategory(res *Response) (map[TaxCategory]string, error) {
+	groups, err := res.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	totals := map[TaxCategory]*big.Rat{}
+	decimals := map[TaxCategory]int{}
+	for _, g := range groups {
+		for i := range g.TaxList {
+			t := &g.TaxList[i]
+			amt, err := parseAmount(t.TaxAmount)
+			if err != nil {
+				return nil, err
+			}
+
+			c := t.Category()
+			if totals[c] == nil {
+				totals[c] = new(big.Rat)
+			}
+			totals[c].Add(totals[c], amt)
+			decimals[c] = max(decimals[c], amountDecimals(t.TaxAmount))
+		}
+	}
+
+	sums := make(map[TaxCategory]string, len(totals))
+	for c, total := range totals {
+		sums[c] = formatAmount(total, decimals[c])
+	}
+	return sums, nil
+}
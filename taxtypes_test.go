@@ -0,0 +1,60 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_TaxByCategory(t *testing.T) {
+
+	res, err := testCli.parseResponse(getTestResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sums, err := TaxByCategory(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[TaxCategory]string{
+		TaxCategoryUSF:            "12.20",
+		TaxCategoryRegulatory:     "1.49",
+		TaxCategoryCommunications: "14.96",
+	}
+	if len(sums) != len(expected) {
+		t.Fatalf("Expected %v but got %v", expected, sums)
+	}
+	for c, v := range expected {
+		if sums[c] != v {
+			t.Fatalf("Expected %v total %v but got %v", c, v, sums[c])
+		}
+	}
+}
+
+func Test_Tax_Category(t *testing.T) {
+
+	cases := []struct {
+		tax      Tax
+		category TaxCategory
+		class    TaxClass
+	}{
+		{Tax{TaxTypeCode: "035", TaxTypeDesc: "FEDERAL UNIVERSAL SERVICE FUND"}, TaxCategoryUSF, TaxClassSurcharge},
+		{Tax{TaxTypeCode: "060", TaxTypeDesc: "FEDERAL COST RECOVERY CHARGE"}, TaxCategoryRegulatory, TaxClassSurcharge},
+		{Tax{TaxTypeCode: "035"}, TaxCategoryUSF, TaxClassSurcharge},
+		{Tax{TaxTypeCode: "127", TaxTypeDesc: "Renamed description"}, TaxCategoryCommunications, TaxClassTax},
+		{Tax{TaxTypeCode: "999", TaxTypeDesc: "GA 911 Fee"}, TaxCategoryE911, TaxClassTax},
+		{Tax{TaxTypeCode: "999", TaxTypeDesc: "State Sales Tax"}, TaxCategorySales, TaxClassTax},
+		{Tax{TaxTypeCode: "999", TaxTypeDesc: "OH Gross Receipts Tax"}, TaxCategoryGrossReceipts, TaxClassSurcharge},
+		{Tax{TaxTypeCode: "999", TaxTypeDesc: "Something new"}, TaxCategoryOther, TaxClassUnknown},
+	}
+
+	for _, c := range cases {
+		if c.tax.Category() != c.category || c.tax.Class() != c.class {
+			t.Fatalf("Expected %v/%v for %+v but got %v/%v", c.category, c.class, c.tax, c.tax.Category(), c.tax.Class())
+		}
+	}
+
+	if TaxCategoryGrossReceipts.String() != "GrossReceipts" || TaxClassSurcharge.String() != "Surcharge" {
+		t.Fatal("Expected category and class names")
+	}
+}
@@ -0,0 +1,113 @@
+package suretax
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Returned by CancelByTracking when no transaction was recorded for the tracking value.
+var ErrUnknownTracking = errors.New("No transactions recorded for ClientTracking")
+
+// Transaction recorded in a TrackingIndex.
+type TrackingEntry struct {
+	ClientTracking string
+	TransId        int
+	ClientNumber   string
+
+	// Time the response was received.
+	Time time.Time
+}
+
+// Resolves ClientTracking values to the TransIds of the transactions sent with them.
+type TrackingIndex interface {
+	Put(entry TrackingEntry) error
+
+	// Returns every transaction recorded for clientTracking, oldest first.
+	Lookup(clientTracking string) ([]TrackingEntry, error)
+}
+
+// Optionally implemented by a TrackingIndex to resolve transactions by TransId.
+// Used to check the cancellation window before calling SureTax.
+type TransIdIndex interface {
+	LookupTransId(transId int) (TrackingEntry, bool, error)
+}
+
+// In-memory TrackingIndex. Safe for concurrent use.
+type MemoryTrackingIndex struct {
+	mu      sync.RWMutex
+	entries map[string][]TrackingEntry
+	byId    map[int]TrackingEntry
+}
+
+func NewMemoryTrackingIndex() *MemoryTrackingIndex {
+	return &MemoryTrackingIndex{entries: make(map[string][]TrackingEntry), byId: make(map[int]TrackingEntry)}
+}
+
+func (x *MemoryTrackingIndex) Put(entry TrackingEntry) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.entries[entry.ClientTracking] = append(x.entries[entry.ClientTracking], entry)
+	x.byId[entry.TransId] = entry
+	return nil
+}
+
+func (x *MemoryTrackingIndex) LookupTransId(transId int) (TrackingEntry, bool, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	e, ok := x.byId[transId]
+	return e, ok, nil
+}
+
+func (x *MemoryTrackingIndex) Lookup(clientTracking string) ([]TrackingEntry, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	entries := x.entries[clientTracking]
+	return append([]TrackingEntry(nil), entries...), nil
+}
+
+// Cancels every transaction recorded in the client's TrackingIndex for clientTracking, with the
+// client's credentials. Returns the responses of all attempted cancellations and the errors of those that failed.
+func (c *SuretaxClient) CancelByTracking(ctx context.Context, clientTracking string) ([]*CancelResponse, error) {
+
+	if c.TrackingIndex == nil {
+		return nil, errors.New("CancelByTracking requires a TrackingIndex")
+	}
+	if clientTracking == "" {
+		return nil, errors.New("CancelByTracking requires a ClientTracking")
+	}
+
+	entries, err := c.TrackingIndex.Lookup(clientTracking)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrUnknownTracking
+	}
+
+	var responses []*CancelResponse
+	var errs []error
+
+	for _, e := range entries {
+		req := &CancelRequest{
+			ClientNumber:   e.ClientNumber,
+			ClientTracking: clientTracking,
+			TransId:        strconv.Itoa(e.TransId),
+		}
+
+		res, err := c.CancelContext(ctx, req)
+		if res != nil {
+			responses = append(responses, res)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return responses, errors.Join(errs...)
+}
@@ -0,0 +1,57 @@
+package suretax
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func Test_CancelByTracking(t *testing.T) {
+
+	var cancelled *CancelRequest
+	SetHttpClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/cancel" {
+			var inner string
+			cancelled = &CancelRequest{}
+			decodeWrapped(t, r, &inner, cancelled)
+			return okResponse(envelope(`{"ResponseCode":"9999","HeaderMessage":"Success","Successful":"Y","TransId":616039832}`)), nil
+		}
+		resp := getTestResponse()
+		resp.StatusCode = http.StatusOK
+		return resp, nil
+	}))
+	defer SetHttpClient(nil)
+
+	cli := &SuretaxClient{Url: "http://suretax/send", CancelUrl: "http://suretax/cancel", TrackingIndex: NewMemoryTrackingIndex(),
+		Credentials: Credentials{ValidationKey: "key"}}
+
+	if _, err := cli.Send(getTestRequest()); err != nil {
+		t.Fatal(err)
+	}
+
+	responses, err := cli.CancelByTracking(context.Background(), "Certi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(responses) != 1 || cancelled.TransId != "616039832" || cancelled.ClientNumber != "000000001" || cancelled.ValidationKey != "key" {
+		t.Fatalf("Unexpected cancel request %+v", cancelled)
+	}
+
+	if _, err := cli.CancelByTracking(context.Background(), "unknown"); err != ErrUnknownTracking {
+		t.Fatalf("Expected ErrUnknownTracking but got %v", err)
+	}
+
+	cancelled = nil
+	req := getTestRequest()
+	req.ClientTracking = ""
+	if _, err := cli.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := cli.TrackingIndex.Lookup(""); len(entries) != 0 {
+		t.Fatalf("Expected the untracked transaction not to be indexed but got %+v", entries)
+	}
+	if _, err := cli.CancelByTracking(context.Background(), ""); err == nil || cancelled != nil {
+		t.Fatalf("Expected an empty ClientTracking to be refused without a cancel but got %v, %+v", err, cancelled)
+	}
+}
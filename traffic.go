@@ -0,0 +1,146 @@
+package suretax
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Shares of revenue by jurisdiction in percent, e.g. from a traffic study. They must add up to 100.
+type TrafficAllocation struct {
+	Interstate    string
+	Intrastate    string
+	International string
+}
+
+// TransTypeCodes to use for each jurisdiction of a service.
+type JurisdictionTransTypes struct {
+	Interstate    string
+	Intrastate    string
+	International string
+}
+
+// Allocates revenue items to jurisdictions before submission, as required from carriers
+// reporting by traffic study.
+type TrafficStudy struct {
+	// Allocation by the TransTypeCode of the original item
+	Allocations map[string]TrafficAllocation
+
+	// Codes for the sub-items, by the TransTypeCode of the original item
+	TransTypes map[string]JurisdictionTransTypes
+
+	// Recorded with each share for auditability, e.g. "TS-2024Q1"
+	Source string
+
+	// UDF field receiving the applied share: "UDF" or "UDF2" (default)
+	AuditField string
+}
+
+// Returns the allocation matching a safe harbor interstate percentage, the rest being intrastate.
+func (s *SafeHarbor) Allocation(transTypeCode string) (TrafficAllocation, bool) {
+	pct := s.Percent(transTypeCode)
+	if pct == "" {
+		return TrafficAllocation{}, false
+	}
+	p, err := parseAmount(pct)
+	if err != nil {
+		return TrafficAllocation{}, false
+	}
+	intra := new(big.Rat).Sub(big.NewRat(100, 1), p)
+	return TrafficAllocation{Interstate: pct, Intrastate: formatAmount(intra, amountDecimals(pct))}, true
+}
+
+// Splits item into one sub-item per jurisdiction with a non-zero share, each with the jurisdiction's
+// TransTypeCode and its part of Revenue, with 4 decimal places or as many as Revenue has. The last
+// sub-item absorbs rounding so the parts add up to Revenue. Line numbers get an -inter, -intra or
+// -intl suffix. Items whose trans type has no allocation are returned unchanged.
+func (s *TrafficStudy) Allocate(item RequestItem) ([]RequestItem, error) {
+	alloc, ok := s.Allocations[item.TransTypeCode]
+	if !ok {
+		return []RequestItem{item}, nil
+	}
+	codes := s.TransTypes[item.TransTypeCode]
+
+	fail := func(format string, a ...interface{}) error {
+		return &ValidationError{LineNumber: item.LineNumber, Field: "TransTypeCode", Message: fmt.Sprintf(format, a...)}
+	}
+
+	revenue, err := parseAmount(item.Revenue)
+	if err != nil {
+		return nil, &ValidationError{LineNumber: item.LineNumber, Field: "Revenue", Message: err.Error()}
+	}
+	decimals := splitPlaces(item.Revenue)
+
+	parts := []struct{ name, suffix, share, code string }{
+		{"interstate", "-inter", alloc.Interstate, codes.Interstate},
+		{"intrastate", "-intra", alloc.Intrastate, codes.Intrastate},
+		{"international", "-intl", alloc.International, codes.International},
+	}
+
+	total := new(big.Rat)
+	shares := make([]*big.Rat, len(parts))
+	last := -1
+	for i, p := range parts {
+		if shares[i], err = parseAmount(p.share); err != nil || shares[i].Sign() < 0 {
+			return nil, fail("invalid %s share %q for %s", p.name, p.share, item.TransTypeCode)
+		}
+		if shares[i].Sign() > 0 {
+			if p.code == "" {
+				return nil, fail("no %s trans type configured for %s", p.name, item.TransTypeCode)
+			}
+			last = i
+		}
+		total.Add(total, shares[i])
+	}
+	if total.Cmp(big.NewRat(100, 1)) != 0 {
+		return nil, fail("allocation for %s adds up to %s%%, not 100%%", item.TransTypeCode, total.FloatString(2))
+	}
+
+	var items []RequestItem
+	allocated := new(big.Rat)
+	for i, p := range parts {
+		if shares[i].Sign() == 0 {
+			continue
+		}
+
+		amount := formatAmount(new(big.Rat).Mul(revenue, new(big.Rat).Quo(shares[i], big.NewRat(100, 1))), decimals)
+		if i == last {
+			amount = formatAmount(new(big.Rat).Sub(revenue, allocated), decimals)
+		}
+		rounded, _ := parseAmount(amount)
+		allocated.Add(allocated, rounded)
+
+		sub := item
+		sub.TransTypeCode = p.code
+		sub.Revenue = amount
+		if item.LineNumber != "" {
+			sub.LineNumber = item.LineNumber + p.suffix
+		}
+
+		record := "TS:" + p.name + ":" + p.share
+		if s.Source != "" {
+			record = "TS:" + s.Source + ":" + p.name + ":" + p.share
+		}
+		if err := recordUDF(&sub, s.AuditField, record); err != nil {
+			return nil, err
+		}
+
+		items = append(items, sub)
+	}
+
+	return items, nil
+}
+
+// Replaces the items of req by their allocated sub-items. TotalRevenue is unchanged since
+// the sub-items add up to the original revenue.
+func (s *TrafficStudy) AllocateRequest(req *Request) error {
+	items := make([]RequestItem, 0, len(req.ItemList))
+	for _, item := range req.ItemList {
+		subs, err := s.Allocate(item)
+		if err != nil {
+			return err
+		}
+		items = append(items, subs...)
+	}
+	req.ItemList = items
+	return nil
+}
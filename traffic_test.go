@@ -0,0 +1,82 @@
+package suretax
+
+import (
+	"testing"
+)
+
+func Test_TrafficStudy_Allocate(t *testing.T) {
+
+	study := &TrafficStudy{
+		Allocations: map[string]TrafficAllocation{"050104": {Interstate: "60", Intrastate: "30", International: "10"}},
+		TransTypes:  map[string]JurisdictionTransTypes{"050104": {Interstate: "050204", Intrastate: "050104", International: "050304"}},
+		Source:      "TS-2024Q1",
+	}
+
+	item := getTestRequest().ItemList[0]
+	item.Revenue = "100.01"
+
+	items, err := study.Allocate(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct{ line, code, revenue, udf string }{
+		{"01-inter", "050204", "60.0060", "TS:TS-2024Q1:interstate:60"},
+		{"01-intra", "050104", "30.0030", "TS:TS-2024Q1:intrastate:30"},
+		{"01-intl", "050304", "10.0010", "TS:TS-2024Q1:international:10"},
+	}
+	if len(items) != len(expected) {
+		t.Fatalf("Expected %d sub-items but got %d", len(expected), len(items))
+	}
+	for i, e := range expected {
+		it := items[i]
+		if it.LineNumber != e.line || it.TransTypeCode != e.code || it.Revenue != e.revenue || it.UDF2 != e.udf {
+			t.Fatalf("Expected %+v but got %s %s %s %s", e, it.LineNumber, it.TransTypeCode, it.Revenue, it.UDF2)
+		}
+	}
+
+	other := RequestItem{TransTypeCode: "010101", Revenue: "5"}
+	if items, err := study.Allocate(other); err != nil || len(items) != 1 || items[0].TransTypeCode != "010101" {
+		t.Fatalf("Expected an unallocated item to be kept but got %v, %v", items, err)
+	}
+}
+
+func Test_TrafficStudy_Invalid(t *testing.T) {
+
+	item := RequestItem{LineNumber: "1", TransTypeCode: "050104", Revenue: "10"}
+
+	studies := []*TrafficStudy{
+		{Allocations: map[string]TrafficAllocation{"050104": {Interstate: "60", Intrastate: "30"}},
+			TransTypes: map[string]JurisdictionTransTypes{"050104": {Interstate: "050204", Intrastate: "050104"}}},
+		{Allocations: map[string]TrafficAllocation{"050104": {Interstate: "60", Intrastate: "40"}},
+			TransTypes: map[string]JurisdictionTransTypes{"050104": {Interstate: "050204"}}},
+	}
+
+	for i, s := range studies {
+		if _, err := s.Allocate(item); !IsValidationError(err) {
+			t.Fatalf("Expected a validation error for study %d but got %v", i, err)
+		}
+	}
+}
+
+func Test_SafeHarbor_Allocation(t *testing.T) {
+
+	sh := &SafeHarbor{Default: SafeHarborVoIP}
+	study := &TrafficStudy{
+		Allocations: map[string]TrafficAllocation{},
+		TransTypes:  map[string]JurisdictionTransTypes{"050104": {Interstate: "050204", Intrastate: "050104"}},
+	}
+	alloc, ok := sh.Allocation("050104")
+	if !ok || alloc.Interstate != "64.9" || alloc.Intrastate != "35.1" {
+		t.Fatalf("Expected a 64.9/35.1 allocation but got %+v", alloc)
+	}
+	study.Allocations["050104"] = alloc
+
+	req := getTestRequest()
+	if err := study.AllocateRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.ItemList) != 2 || req.ItemList[0].Revenue != "64.9000" || req.ItemList[1].Revenue != "35.1000" {
+		t.Fatalf("Expected revenue split 64.9/35.1 but got %+v", req.ItemList)
+	}
+}
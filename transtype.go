@@ -0,0 +1,176 @@
+package suretax
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A transaction type from the SureTax catalog.
+type TransType struct {
+	// TransTypeCode value, e.g. 050104
+	Code        string
+	Description string
+	Category    string
+}
+
+// A search result with its relevance between 0 and 1.
+type TransTypeMatch struct {
+	TransType
+	Score float64
+}
+
+// Searchable set of transaction types. The catalog isn't bundled with the package since it is
+// licensed per account; load the export provided by CCH with LoadTransTypeCatalog.
+type TransTypeCatalog struct {
+	types []TransType
+	words [][]string
+}
+
+// Reads a catalog from CSV with a header row naming Code, Description and Category columns
+// (in any order, case-insensitive). Other columns are ignored, Category may be absent.
+func LoadTransTypeCatalog(r io.Reader) (*TransTypeCatalog, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := map[string]int{"code": -1, "description": -1, "category": -1}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if _, ok := cols[h]; ok {
+			cols[h] = i
+		}
+	}
+	if cols["code"] < 0 || cols["description"] < 0 {
+		return nil, fmt.Errorf("Trans type catalog header must name Code and Description columns, got %v", header)
+	}
+
+	field := func(rec []string, col string) string {
+		if i := cols[col]; i >= 0 && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	c := &TransTypeCatalog{}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return c, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if t := (TransType{field(rec, "code"), field(rec, "description"), field(rec, "category")}); t.Code != "" {
+			c.Add(t)
+		}
+	}
+}
+
+func (c *TransTypeCatalog) Add(t TransType) {
+	c.types = append(c.types, t)
+	c.words = append(c.words, searchWords(t.Description+" "+t.Category))
+}
+
+// Returns the transaction type with the given code.
+func (c *TransTypeCatalog) Lookup(code string) (TransType, bool) {
+	for _, t := range c.types {
+		if t.Code == code {
+			return t, true
+		}
+	}
+	return TransType{}, false
+}
+
+// Returns the transaction types matching query, best first. Words of the query are matched
+// against descriptions and categories tolerating prefixes and small typos, so
+// Search("conference bridging") finds "Conference Bridging Services". A query starting
+// with a digit matches codes by prefix.
+func (c *TransTypeCatalog) Search(query string, limit int) []TransTypeMatch {
+	var matches []TransTypeMatch
+
+	query = strings.TrimSpace(query)
+	if query != "" && unicode.IsDigit(rune(query[0])) {
+		for _, t := range c.types {
+			if strings.HasPrefix(t.Code, query) {
+				matches = append(matches, TransTypeMatch{t, float64(len(query)) / float64(len(t.Code))})
+			}
+		}
+	} else if qwords := searchWords(query); len(qwords) > 0 {
+		for i, t := range c.types {
+			total := 0.0
+			for _, q := range qwords {
+				total += bestWordScore(q, c.words[i])
+			}
+			if score := total / float64(len(qwords)); score >= 0.5 {
+				matches = append(matches, TransTypeMatch{t, score})
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Code < matches[j].Code
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func bestWordScore(q string, words []string) float64 {
+	best := 0.0
+	for _, w := range words {
+		var score float64
+		switch {
+		case w == q:
+			score = 1
+		case len(q) >= 3 && strings.HasPrefix(w, q):
+			score = 0.8
+		case len(q) >= 4 && editDistance(q, w) <= len(q)/4:
+			score = 0.6
+		}
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// Levenshtein distance of a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
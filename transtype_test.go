@@ -0,0 +1,53 @@
+package suretax
+
+import (
+	"strings"
+	"testing"
+)
+
+const testTransTypes = "\ufeffCode,Description,Category,Notes\n" +
+	"050104,Conference Bridging Services,Conferencing,\n" +
+	"050101,Audio Conferencing,Conferencing,\n" +
+	"010101,Local Toll,Toll,\n" +
+	"\"030101\",\"Wireless Access, Monthly\",Wireless\n"
+
+func Test_TransTypeCatalog_Search(t *testing.T) {
+
+	c, err := LoadTransTypeCatalog(strings.NewReader(testTransTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tt, ok := c.Lookup("030101"); !ok || tt.Description != "Wireless Access, Monthly" || tt.Category != "Wireless" {
+		t.Fatalf("Expected the wireless trans type but got %+v", tt)
+	}
+
+	matches := c.Search("conference bridging", 0)
+	if len(matches) == 0 || matches[0].Code != "050104" || matches[0].Score != 1 {
+		t.Fatalf("Expected 050104 as the best match but got %+v", matches)
+	}
+
+	matches = c.Search("conferencing", 0)
+	if len(matches) != 2 || matches[0].Code != "050101" {
+		t.Fatalf("Expected both conferencing types, audio first, but got %+v", matches)
+	}
+
+	if matches := c.Search("wirelss acess", 0); len(matches) != 1 || matches[0].Code != "030101" {
+		t.Fatalf("Expected a typo tolerant match but got %+v", matches)
+	}
+
+	if matches := c.Search("0501", 1); len(matches) != 1 || matches[0].Code != "050101" {
+		t.Fatalf("Expected one code prefix match but got %+v", matches)
+	}
+
+	if matches := c.Search("satellite", 0); len(matches) != 0 {
+		t.Fatalf("Expected no matches but got %+v", matches)
+	}
+}
+
+func Test_LoadTransTypeCatalog_Header(t *testing.T) {
+
+	if _, err := LoadTransTypeCatalog(strings.NewReader("Id,Name\n1,x\n")); err == nil {
+		t.Fatal("Expected an error for a header without Code and Description")
+	}
+}
@@ -0,0 +1,159 @@
+package suretax
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Endpoints counted by a UsageMeter.
+const (
+	UsageEndpointSend   = "Send"
+	UsageEndpointCancel = "Cancel"
+)
+
+// Attribution of billable calls.
+type UsageKey struct {
+	// Host of the SureTax URL the call was sent to, e.g. testapi.taxrating.net.
+	Environment string
+
+	Endpoint string
+
+	// ReturnFileCode of the request (Q for quotes). Empty for cancellations.
+	ReturnFileCode string
+
+	// See WithTenant. Defaults to the BusinessUnit of the request.
+	Tenant string
+}
+
+// Billable calls of one UsageKey.
+type UsageEntry struct {
+	UsageKey
+
+	// Calls answered by SureTax, and the line items they carried.
+	Calls int64
+	Items int64
+}
+
+// Usage recorded by a UsageMeter between Since and Until, ordered by key.
+type UsageSnapshot struct {
+	Since   time.Time
+	Until   time.Time
+	Entries []UsageEntry
+}
+
+// Returns the number of calls of all entries.
+func (s *UsageSnapshot) Calls() int64 {
+	var n int64
+	for _, e := range s.Entries {
+		n += e.Calls
+	}
+	return n
+}
+
+// Writes the snapshot as CSV with one row per key.
+func (s *UsageSnapshot) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Environment", "Endpoint", "ReturnFileCode", "Tenant", "Calls", "Items"})
+
+	for _, e := range s.Entries {
+		cw.Write([]string{e.Environment, e.Endpoint, e.ReturnFileCode, e.Tenant,
+			strconv.FormatInt(e.Calls, 10), strconv.FormatInt(e.Items, 10)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes the snapshot as a JSON document.
+func (s *UsageSnapshot) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Counts the billable calls of a client, i.e. those SureTax answered, whatever the ResponseCode.
+// Calls failing before reaching SureTax or with an HTTP error are not counted. See SetBudget
+// for alerts on the monthly volume. Safe for concurrent use.
+type UsageMeter struct {
+	mu      sync.Mutex
+	entries map[UsageKey]*UsageEntry
+	since   time.Time
+	budget  *budgetState
+	now     func() time.Time
+}
+
+func NewUsageMeter() *UsageMeter {
+	m := &UsageMeter{entries: make(map[UsageKey]*UsageEntry), now: time.Now}
+	m.since = m.now()
+	return m
+}
+
+func (m *UsageMeter) record(key UsageKey, items int) {
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	if !ok {
+		e = &UsageEntry{UsageKey: key}
+		m.entries[key] = e
+	}
+	e.Calls++
+	e.Items += int64(items)
+
+	var events []BudgetEvent
+	var onThreshold func(BudgetEvent)
+	if m.budget != nil {
+		events = m.budget.count(m.now())
+		onThreshold = m.budget.budget.OnThreshold
+	}
+	m.mu.Unlock()
+
+	if onThreshold != nil {
+		for _, e := range events {
+			onThreshold(e)
+		}
+	}
+}
+
+// Returns the usage recorded since the meter was created or last reset.
+func (m *UsageMeter) Snapshot() *UsageSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.snapshot()
+}
+
+// Returns the usage recorded so far and starts a new period.
+func (m *UsageMeter) Reset() *UsageSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.snapshot()
+	m.entries = make(map[UsageKey]*UsageEntry)
+	m.since = s.Until
+	return s
+}
+
+func (m *UsageMeter) snapshot() *UsageSnapshot {
+	s := &UsageSnapshot{Since: m.since, Until: m.now()}
+	for _, e := range m.entries {
+		s.Entries = append(s.Entries, *e)
+	}
+	sort.Slice(s.Entries, func(i, j int) bool {
+		a, b := s.Entries[i].UsageKey, s.Entries[j].UsageKey
+		if a.Environment != b.Environment {
+			return a.Environment < b.Environment
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.ReturnFileCode != b.ReturnFileCode {
+			return a.ReturnFileCode < b.ReturnFileCode
+		}
+		return a.Tenant < b.Tenant
+	})
+	return s
+}
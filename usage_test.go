@@ -0,0 +1,80 @@
+package suretax
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func Test_UsageMeter_Send(t *testing.T) {
+
+	ok := envelope(`{"ResponseCode":"9999","Successful":"Y","TransId":1}`)
+	declined := envelope(`{"ResponseCode":"1100","Successful":"N"}`)
+	fake := &fakeHttpClient{bodies: []string{ok, declined, ok, envelope(`{"Successful":"Y","ResponseCode":"9999"}`)}}
+	SetHttpClient(fake)
+	defer SetHttpClient(nil)
+
+	meter := NewUsageMeter()
+	cli := &SuretaxClient{Url: "https://testapi.taxrating.net/post", CancelUrl: "https://testapi.taxrating.net/cancel", Usage: meter}
+
+	quote := getTestRequest()
+	quote.ReturnFileCode = "Q"
+	cli.Send(quote)
+	cli.Send(getTestRequest())
+
+	req := getTestRequest()
+	req.BusinessUnit = "east"
+	cli.SendContext(WithTenant(context.Background(), "acme"), req)
+	cli.Cancel(&CancelRequest{TransId: "1"})
+
+	fake.err = io.ErrUnexpectedEOF
+	cli.Send(getTestRequest())
+
+	s := meter.Snapshot()
+	if s.Calls() != 4 || len(s.Entries) != 4 {
+		t.Fatalf("Expected 4 billable calls but got %+v", s.Entries)
+	}
+	expected := []UsageKey{
+		{"testapi.taxrating.net", "Cancel", "", ""},
+		{"testapi.taxrating.net", "Send", "0", ""},
+		{"testapi.taxrating.net", "Send", "0", "acme"},
+		{"testapi.taxrating.net", "Send", "Q", ""},
+	}
+	for i, key := range expected {
+		if s.Entries[i].UsageKey != key {
+			t.Fatalf("Expected %+v but got %+v", key, s.Entries[i].UsageKey)
+		}
+	}
+	if s.Entries[1].Items != 1 {
+		t.Fatalf("Expected 1 item but got %v", s.Entries[1].Items)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := s.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("testapi.taxrating.net,Send,Q,,1,1\n")) {
+		t.Fatalf("Expected the quote row but got %v", buf.String())
+	}
+}
+
+func Test_UsageMeter_Reset(t *testing.T) {
+
+	m := NewUsageMeter()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.since = now
+
+	m.record(UsageKey{Endpoint: UsageEndpointSend}, 3)
+	now = now.Add(time.Hour)
+
+	s := m.Reset()
+	if s.Calls() != 1 || s.Until != now {
+		t.Fatalf("Expected the first period but got %+v", s)
+	}
+	if s := m.Snapshot(); s.Calls() != 0 || s.Since != now {
+		t.Fatalf("Expected an empty period but got %+v", s)
+	}
+}
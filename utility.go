@@ -0,0 +1,66 @@
+package suretax
+
+import "fmt"
+
+// Unit of measure of a metered energy or utility service.
+type UtilityUnit string
+
+const (
+	UtilityKilowattHours UtilityUnit = "kWh"
+	UtilityTherms        UtilityUnit = "therm"
+
+	// Hundred cubic feet (natural gas, water)
+	UtilityCcf UtilityUnit = "ccf"
+
+	UtilityGallons UtilityUnit = "gal"
+)
+
+// UnitType codes the SureTax utility engine of an account expects for each unit of measure.
+// The codes depend on the engine configuration of the account (see Appendix F of the SureTax
+// specification), so there are no defaults: configure them per client in SuretaxClient.UtilityUnits.
+type UtilityUnits map[UtilityUnit]UnitType
+
+// Sets Units of item to the metered quantity (e.g. "1250.5") and UnitType to the code configured
+// for unit. Returns a *ValidationError if the quantity is not a number or no code is configured for unit.
+func (u UtilityUnits) SetUsage(item *RequestItem, quantity string, unit UtilityUnit) error {
+	if _, err := parseAmount(quantity); err != nil || quantity == "" {
+		return &ValidationError{LineNumber: item.LineNumber, Field: "Units", Message: fmt.Sprintf("invalid quantity %q", quantity)}
+	}
+
+	code, ok := u[unit]
+	if !ok {
+		return &ValidationError{LineNumber: item.LineNumber, Field: "UnitType", Message: fmt.Sprintf("no UnitType code configured for %s", unit)}
+	}
+
+	item.Units = quantity
+	item.UnitType = code
+	return nil
+}
+
+// Reports whether t is one of the configured codes, so Units holds a metered quantity.
+func (u UtilityUnits) Has(t UnitType) bool {
+	for _, code := range u {
+		if code == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether t is a 2 digit code or one of the configured codes.
+func (u UtilityUnits) Valid(t UnitType) bool {
+	return t.Valid() || u.Has(t)
+}
+
+// Sets Units of item to the metered quantity and UnitType to the code configured for unit
+// in UtilityUnits, see UtilityUnits.SetUsage.
+func (c *SuretaxClient) SetUsage(item *RequestItem, quantity string, unit UtilityUnit) error {
+	return c.UtilityUnits.SetUsage(item, quantity, unit)
+}
+
+// Makes the client accept the UnitType codes of units, see SuretaxClient.UtilityUnits.
+func WithUtilityUnits(units UtilityUnits) Option {
+	return func(c *SuretaxClient) {
+		c.UtilityUnits = units
+	}
+}
@@ -0,0 +1,74 @@
+package suretax
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_SetUsage(t *testing.T) {
+
+	cli := NewClient("", "", WithUtilityUnits(UtilityUnits{UtilityKilowattHours: "K1"}))
+
+	item := RequestItem{LineNumber: "1"}
+	if err := cli.SetUsage(&item, "1250.5", UtilityKilowattHours); err != nil {
+		t.Fatal(err)
+	}
+	if item.Units != "1250.5" || item.UnitType != "K1" {
+		t.Fatalf("Expected Units 1250.5 and UnitType K1 but got %v and %v", item.Units, item.UnitType)
+	}
+
+	if findings := cli.Lint(&Request{ItemList: []RequestItem{item}}); hasFinding(findings, "UnitType") || hasFinding(findings, "Units") {
+		t.Fatalf("Expected the configured code and quantity to pass but got %v", findings)
+	}
+	if findings := LintRequest(&Request{ItemList: []RequestItem{item}}); !hasFinding(findings, "UnitType") {
+		t.Fatalf("Expected an unconfigured code to be reported but got %v", findings)
+	}
+
+	err := cli.SetUsage(&item, "12", UtilityTherms)
+	if verr, ok := err.(*ValidationError); !ok || verr.Field != "UnitType" {
+		t.Fatalf("Expected a UnitType validation error but got %v", err)
+	}
+
+	for _, q := range []string{"", "lots"} {
+		err := cli.SetUsage(&item, q, UtilityKilowattHours)
+		if verr, ok := err.(*ValidationError); !ok || verr.Field != "Units" {
+			t.Fatalf("Expected a Units validation error for %q but got %v", q, err)
+		}
+	}
+}
+
+func Test_buildRequest_ServiceAddress(t *testing.T) {
+
+	req := getTestRequest()
+	req.ItemList[0].ServiceAddress = &Address{PrimaryAddressLine: "1 Plant Rd", PostalCode: "32034"}
+	req.ItemList[0].MeterNumber = "M-778"
+
+	r, err := testCli.buildRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := requestBodyToString(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{`\"ServiceAddress\":{\"PrimaryAddressLine\":\"1 Plant Rd\"`, `\"MeterNumber\":\"M-778\"`} {
+		if !strings.Contains(body, s) {
+			t.Fatalf("Expected request body to contain %s but got %s", s, body)
+		}
+	}
+
+	min := MinimizeRequest(req, PrivacyStrip, nil)
+	if min.ItemList[0].ServiceAddress.PrimaryAddressLine != "" || req.ItemList[0].ServiceAddress.PrimaryAddressLine != "1 Plant Rd" {
+		t.Fatal("Expected the service address line to be stripped from the copy only")
+	}
+}
+
+func hasFinding(findings []LintFinding, field string) bool {
+	for _, f := range findings {
+		if strings.HasSuffix(f.Path, "."+field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Writes {"<key>":"<JSON of v>"} to buf, escaping the JSON of v as a string value while it is encoded.
+// Produces the same bytes as marshaling v, converting the result to a string and marshaling
+// a wrapper struct, without the intermediate copies.
+func writeWrapped(buf *bytes.Buffer, key string, v interface{}) error {
+	buf.WriteString(`{"`)
+	buf.WriteString(key)
+	buf.WriteString(`":"`)
+
+	if err := json.NewEncoder(stringEscaper{buf}).Encode(v); err != nil {
+		return err
+	}
+
+	buf.WriteString(`"}`)
+	return nil
+}
+
+// Escapes encoder output for embedding in a JSON string.
+// The encoder already escapes control characters, HTML characters and U+2028/U+2029 inside strings,
+// so only quotes and backslashes remain. Raw newlines can only be the encoder's trailing newline and are dropped.
+type stringEscaper struct {
+	buf *bytes.Buffer
+}
+
+func (e stringEscaper) Write(p []byte) (int, error) {
+	// The encoder hands over the whole document in one call, so growing once avoids repeated copies.
+	e.buf.Grow(len(p) + bytes.Count(p, []byte{'"'}) + bytes.Count(p, []byte{'\\'}) + len(`"}`))
+
+	start := 0
+	for i, b := range p {
+		switch b {
+		case '"', '\\':
+			e.buf.Write(p[start:i])
+			e.buf.WriteByte('\\')
+			e.buf.WriteByte(b)
+			start = i + 1
+		case '\n':
+			e.buf.Write(p[start:i])
+			start = i + 1
+		}
+	}
+	e.buf.Write(p[start:])
+	return len(p), nil
+}
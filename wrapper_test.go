@@ -0,0 +1,71 @@
+package suretax
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func Test_writeWrapped(t *testing.T) {
+
+	req := getTestRequest()
+	req.ClientTracking = "quote \" back\\slash <tag> &   \t ünï"
+	req.ItemList[0].UDF = "line\nbreak"
+
+	buf := new(bytes.Buffer)
+	if err := writeWrapped(buf, "request", req); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := marshalWrappedTwice(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != string(expected) {
+		t.Fatalf("Expected %s but got %s", expected, buf.String())
+	}
+}
+
+// The original Marshal → string → Marshal implementation, kept for comparison.
+func marshalWrappedTwice(req *Request) ([]byte, error) {
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(requestWrapper{string(reqBytes)})
+}
+
+func getLargeTestRequest(items int) *Request {
+	req := getTestRequest()
+	item := req.ItemList[0]
+	req.ItemList = make([]RequestItem, items)
+	for i := range req.ItemList {
+		req.ItemList[i] = item
+	}
+	return req
+}
+
+func Benchmark_buildRequest_10k(b *testing.B) {
+	req := getLargeTestRequest(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := testCli.buildRequest(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_marshalWrappedTwice_10k(b *testing.B) {
+	req := getLargeTestRequest(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := marshalWrappedTwice(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}